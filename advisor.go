@@ -0,0 +1,219 @@
+// Package snowflake - advisor.go recommends a BitLayout from deployment requirements.
+//
+// RecommendLayout tries every bit split BitLayout.Validate accepts at a range
+// of time units, measures each against the required worker count, per-worker
+// peak rate and lifespan with CalculateCapacity, and ranks them by the
+// headroom of their tightest dimension. When no layout meets every
+// requirement, the closest ones are returned with their shortfalls explained.
+
+package snowflake
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// recommendTimeUnits are the time units RecommendLayout considers. Powers of
+// two use the shift fast path; 10ms matches the Sonyflake-style presets.
+var recommendTimeUnits = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	4 * time.Millisecond,
+	8 * time.Millisecond,
+	10 * time.Millisecond,
+}
+
+// Requirements describes a deployment for RecommendLayout. Zero fields are
+// treated as "no requirement".
+type Requirements struct {
+	// Workers is the number of distinct worker IDs needed at once, including
+	// growth.
+	Workers int64
+
+	// PeakIDsPerSecPerWorker is the highest sustained rate a single worker
+	// must generate. Bursts above the layout's rate wait for the next time unit.
+	PeakIDsPerSecPerWorker int64
+
+	// Lifespan is how long from now IDs must keep being generated.
+	Lifespan time.Duration
+
+	// Epoch is the custom epoch in Unix milliseconds the layout will use.
+	// Default: Epoch (2024-01-01)
+	Epoch int64
+}
+
+// LayoutCandidate is one layout evaluated against Requirements.
+type LayoutCandidate struct {
+	Layout BitLayout
+
+	// Name is the registered name of the layout, if it has one.
+	Name string
+
+	Capacity LayoutCapacity
+
+	// OverflowDate is when the timestamp field overflows with the required epoch.
+	OverflowDate time.Time
+
+	// Headroom is capacity divided by requirement for each dimension: 2 means
+	// twice what is required, below 1 means the requirement is not met.
+	// Dimensions without a requirement are +Inf.
+	WorkerHeadroom     float64
+	ThroughputHeadroom float64
+	LifespanHeadroom   float64
+
+	// Satisfies reports whether every headroom is at least 1.
+	Satisfies bool
+
+	// TradeOffs explains each unmet requirement and any cost of the layout,
+	// such as a coarse time unit.
+	TradeOffs []string
+}
+
+// Headroom returns the smallest of the three headrooms: the dimension that
+// runs out first.
+func (c LayoutCandidate) Headroom() float64 {
+	return math.Min(c.WorkerHeadroom, math.Min(c.ThroughputHeadroom, c.LifespanHeadroom))
+}
+
+// LayoutRecommendation is the result of RecommendLayout.
+type LayoutRecommendation struct {
+	// Best is the top-ranked candidate.
+	Best LayoutCandidate
+
+	// Candidates holds every valid layout, best first: those that satisfy the
+	// requirements by decreasing Headroom, then the rest by how close they come.
+	Candidates []LayoutCandidate
+}
+
+// RecommendLayout searches every layout accepted by BitLayout.Validate, at
+// time units from 1ms to 10ms, and ranks them for req.
+//
+// Layouts are ranked by the headroom of their tightest dimension, so the best
+// layout leaves the most room for growth in whichever of workers, rate and
+// lifespan is closest to its limit. Ties prefer registered layouts, then finer
+// time units. If nothing satisfies req, Best is the layout with the smallest
+// shortfall and its TradeOffs say what was given up.
+//
+// Returns a *ConfigError if a requirement is negative.
+//
+// Example:
+//
+//	rec, err := snowflake.RecommendLayout(snowflake.Requirements{
+//	    Workers:                5000,
+//	    PeakIDsPerSecPerWorker: 50000,
+//	    Lifespan:               30 * 365 * 24 * time.Hour,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(rec.Best.Layout, rec.Best.Satisfies, rec.Best.TradeOffs)
+func RecommendLayout(req Requirements) (LayoutRecommendation, error) {
+	return recommendLayout(req, time.Now())
+}
+
+// recommendLayout implements RecommendLayout as of now.
+func recommendLayout(req Requirements, now time.Time) (LayoutRecommendation, error) {
+	if req.Workers < 0 {
+		return LayoutRecommendation{}, newConfigError("Workers", fmt.Sprint(req.Workers), "must not be negative", "count >= 0")
+	}
+	if req.PeakIDsPerSecPerWorker < 0 {
+		return LayoutRecommendation{}, newConfigError("PeakIDsPerSecPerWorker", fmt.Sprint(req.PeakIDsPerSecPerWorker),
+			"must not be negative", "rate >= 0")
+	}
+	if req.Lifespan < 0 {
+		return LayoutRecommendation{}, newConfigError("Lifespan", req.Lifespan.String(), "must not be negative", "duration >= 0")
+	}
+	if req.Epoch == 0 {
+		req.Epoch = Epoch
+	}
+
+	names := make(map[BitLayout]string)
+	for _, name := range LayoutNames() {
+		l, _ := LookupLayout(name)
+		if _, ok := names[l]; !ok {
+			names[l] = name
+		}
+	}
+
+	var candidates []LayoutCandidate
+	for _, unit := range recommendTimeUnits {
+		for ts := 38; ts <= 42; ts++ {
+			for wb := 8; wb <= 18; wb++ {
+				l := BitLayout{TimestampBits: ts, WorkerBits: wb, SequenceBits: 63 - ts - wb, TimeUnit: unit}
+				if l.Validate() != nil {
+					continue
+				}
+				candidates = append(candidates, evaluateLayout(l, names[l], req, now))
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Satisfies != b.Satisfies {
+			return a.Satisfies
+		}
+		if ha, hb := a.Headroom(), b.Headroom(); ha != hb {
+			return ha > hb
+		}
+		if (a.Name != "") != (b.Name != "") {
+			return a.Name != ""
+		}
+		if a.Layout.TimeUnit != b.Layout.TimeUnit {
+			return a.Layout.TimeUnit < b.Layout.TimeUnit
+		}
+		return a.Layout.TimestampBits > b.Layout.TimestampBits
+	})
+
+	return LayoutRecommendation{Best: candidates[0], Candidates: candidates}, nil
+}
+
+// evaluateLayout measures l against req.
+func evaluateLayout(l BitLayout, name string, req Requirements, now time.Time) LayoutCandidate {
+	s := Scheme{Layout: l, Epoch: req.Epoch}
+	c := LayoutCandidate{
+		Layout:       l,
+		Name:         name,
+		Capacity:     l.CalculateCapacity(),
+		OverflowDate: unitTime(s.epochUnits()+s.maxTimeUnits(), l.TimeUnit),
+	}
+	remaining := c.OverflowDate.Sub(now)
+
+	c.WorkerHeadroom = headroom(float64(c.Capacity.MaxWorkers), float64(req.Workers))
+	c.ThroughputHeadroom = headroom(float64(c.Capacity.ThroughputPerWorker), float64(req.PeakIDsPerSecPerWorker))
+	c.LifespanHeadroom = headroom(math.Max(remaining.Seconds(), 0), req.Lifespan.Seconds())
+	c.Satisfies = c.Headroom() >= 1
+
+	if c.WorkerHeadroom < 1 {
+		c.TradeOffs = append(c.TradeOffs, fmt.Sprintf("supports %d workers, %d required",
+			c.Capacity.MaxWorkers, req.Workers))
+	}
+	if c.ThroughputHeadroom < 1 {
+		c.TradeOffs = append(c.TradeOffs, fmt.Sprintf("generates %d IDs/sec per worker, %d required; peaks will wait for the clock",
+			c.Capacity.ThroughputPerWorker, req.PeakIDsPerSecPerWorker))
+	}
+	if c.LifespanHeadroom < 1 {
+		c.TradeOffs = append(c.TradeOffs, fmt.Sprintf("overflows on %s, %.1f years from now, %.1f required",
+			c.OverflowDate.UTC().Format(time.DateOnly), years(remaining), years(req.Lifespan)))
+	}
+	if l.TimeUnit > time.Millisecond {
+		c.TradeOffs = append(c.TradeOffs, fmt.Sprintf("%v time unit: IDs are ordered and timestamped only to %v",
+			l.TimeUnit, l.TimeUnit))
+	}
+	return c
+}
+
+// headroom returns capacity/required, or +Inf when nothing is required.
+func headroom(capacity, required float64) float64 {
+	if required <= 0 {
+		return math.Inf(1)
+	}
+	return capacity / required
+}
+
+// years converts d to years of 365.25 days.
+func years(d time.Duration) float64 {
+	return d.Hours() / 24 / 365.25
+}
@@ -0,0 +1,89 @@
+package snowflake
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const year = 365 * 24 * time.Hour
+
+func TestRecommendLayout_Satisfiable(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := Requirements{Workers: 5000, PeakIDsPerSecPerWorker: 50000, Lifespan: 30 * year}
+	rec, err := recommendLayout(req, now)
+	if err != nil {
+		t.Fatalf("RecommendLayout() error = %v", err)
+	}
+
+	best := rec.Best
+	if !best.Satisfies {
+		t.Fatalf("Best = %+v, want a layout satisfying %+v", best, req)
+	}
+	if err := best.Layout.Validate(); err != nil {
+		t.Errorf("Best.Layout.Validate() = %v", err)
+	}
+	if best.Capacity.MaxWorkers < req.Workers || best.Capacity.ThroughputPerWorker < req.PeakIDsPerSecPerWorker ||
+		best.OverflowDate.Sub(now) < req.Lifespan {
+		t.Errorf("Best %v does not meet %+v: %+v", best.Layout, req, best.Capacity)
+	}
+	if rec.Candidates[0].Layout != best.Layout {
+		t.Errorf("Candidates[0] = %v, want Best %v", rec.Candidates[0].Layout, best.Layout)
+	}
+
+	// Satisfying candidates come first, ordered by headroom
+	seenUnsatisfied := false
+	for i, c := range rec.Candidates {
+		if !c.Satisfies {
+			seenUnsatisfied = true
+		} else if seenUnsatisfied {
+			t.Fatalf("Candidates[%d] %v satisfies but follows an unsatisfying candidate", i, c.Layout)
+		}
+		if i > 0 && c.Satisfies && c.Headroom() > rec.Candidates[i-1].Headroom() {
+			t.Errorf("Candidates[%d] headroom %.2f > previous %.2f", i, c.Headroom(), rec.Candidates[i-1].Headroom())
+		}
+	}
+}
+
+func TestRecommendLayout_TradeOffs(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// No validated layout has more than 2^18 workers
+	rec, err := recommendLayout(Requirements{Workers: 1 << 20, PeakIDsPerSecPerWorker: 1000}, now)
+	if err != nil {
+		t.Fatalf("RecommendLayout() error = %v", err)
+	}
+	if rec.Best.Satisfies {
+		t.Fatalf("Best.Satisfies = true for 2^20 workers")
+	}
+	if rec.Best.Layout.WorkerBits != 18 {
+		t.Errorf("Best.Layout = %v, want the most worker bits", rec.Best.Layout)
+	}
+	if len(rec.Best.TradeOffs) == 0 || !strings.Contains(rec.Best.TradeOffs[0], "workers") {
+		t.Errorf("Best.TradeOffs = %q, want the worker shortfall explained", rec.Best.TradeOffs)
+	}
+
+	// A long lifespan at modest scale needs a coarser time unit
+	rec, err = recommendLayout(Requirements{Workers: 30000, PeakIDsPerSecPerWorker: 1000, Lifespan: 100 * year}, now)
+	if err != nil {
+		t.Fatalf("RecommendLayout() error = %v", err)
+	}
+	if !rec.Best.Satisfies || rec.Best.Layout.TimeUnit == time.Millisecond {
+		t.Errorf("Best = %v (satisfies %v), want a coarser unit", rec.Best.Layout, rec.Best.Satisfies)
+	}
+	found := false
+	for _, note := range rec.Best.TradeOffs {
+		found = found || strings.Contains(note, "time unit")
+	}
+	if !found {
+		t.Errorf("Best.TradeOffs = %q, want the time unit cost explained", rec.Best.TradeOffs)
+	}
+}
+
+func TestRecommendLayout_Invalid(t *testing.T) {
+	for _, req := range []Requirements{{Workers: -1}, {PeakIDsPerSecPerWorker: -1}, {Lifespan: -time.Hour}} {
+		if _, err := RecommendLayout(req); !IsConfigError(err) {
+			t.Errorf("RecommendLayout(%+v) error = %v, want ConfigError", req, err)
+		}
+	}
+}
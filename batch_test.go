@@ -2,6 +2,7 @@ package snowflake
 
 import (
 	"context"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -337,6 +338,38 @@ func TestGenerateBatch_SequenceOverflow(t *testing.T) {
 	}
 }
 
+func TestGenerateBatch_ClockBackwardReleasesLock(t *testing.T) {
+	gen, _ := New(1)
+
+	gen.mu.Lock()
+	gen.lastTimestamp = gen.currentTimestamp() + 3 // Within the 5ms tolerance
+	gen.lastClock = gen.lastTimestamp
+	gen.mu.Unlock()
+
+	done := make(chan error)
+	go func() {
+		_, err := gen.GenerateBatch(context.Background(), 10)
+		done <- err
+	}()
+
+	// The mutex is free while the batch sleeps out the drift, before it has
+	// generated anything
+	released := false
+	deadline := time.Now().Add(time.Second)
+	for !released && time.Now().Before(deadline) {
+		gen.mu.Lock()
+		released = gen.clockBackward.Load() > 0 && gen.generated.Load() == 0
+		gen.mu.Unlock()
+		runtime.Gosched()
+	}
+	if !released {
+		t.Error("GenerateBatch() held the mutex while waiting out clock drift")
+	}
+	if err := <-done; err != nil {
+		t.Errorf("GenerateBatch() error = %v", err)
+	}
+}
+
 // ============================================================================
 // Metrics Tests
 // ============================================================================
@@ -487,3 +520,37 @@ func BenchmarkGenerateBatch_Concurrent(b *testing.B) {
 		}
 	})
 }
+
+func TestGenerateBatch_CustomLayout(t *testing.T) {
+	cfg := DefaultConfig(300)
+	cfg.Layout = LayoutSuperior
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	ids, err := gen.GenerateBatch(context.Background(), 2000)
+	if err != nil {
+		t.Fatalf("GenerateBatch() error = %v", err)
+	}
+
+	scheme := gen.Scheme()
+	seen := make(map[ID]bool, len(ids))
+	for i, id := range ids {
+		if seen[id] {
+			t.Fatalf("duplicate ID %d at index %d", id, i)
+		}
+		seen[id] = true
+
+		_, worker, seq := scheme.Components(id)
+		if worker != 300 {
+			t.Fatalf("ID %d decodes to worker %d, want 300", id, worker)
+		}
+		if seq > 511 {
+			t.Fatalf("ID %d decodes to sequence %d, exceeds LayoutSuperior max 511", id, seq)
+		}
+		if d := time.Since(scheme.Time(id)); d < -time.Second || d > time.Minute {
+			t.Fatalf("ID %d decodes to time %v, too far from now", id, scheme.Time(id))
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package snowflake - buffered.go provides a prefetching generator.
+//
+// BufferedGenerator keeps a buffer of IDs generated ahead of time by a
+// background goroutine, so callers on latency-sensitive paths take an ID from
+// memory instead of waiting for the next time unit when a sequence overflows.
+
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBufferCapacity is the default number of IDs a BufferedGenerator holds.
+const DefaultBufferCapacity = 4096
+
+// ErrGeneratorClosed is returned by BufferedGenerator.Next after Close.
+var ErrGeneratorClosed = errors.New("generator closed")
+
+// BufferedConfig configures a BufferedGenerator.
+//
+// The zero value is usable: a 4096-ID buffer refilled when it falls below a
+// quarter full.
+type BufferedConfig struct {
+	// Capacity is the size of the ID buffer.
+	// Default: DefaultBufferCapacity (4096)
+	Capacity int
+
+	// LowWatermark triggers a refill when the number of buffered IDs drops below it.
+	// Default: Capacity / 4
+	LowWatermark int
+
+	// HighWatermark is the number of buffered IDs a refill stops at.
+	// Must be greater than LowWatermark and at most Capacity.
+	// Default: Capacity
+	HighWatermark int
+}
+
+// withDefaults fills in zero-valued fields.
+func (c BufferedConfig) withDefaults() BufferedConfig {
+	if c.Capacity == 0 {
+		c.Capacity = DefaultBufferCapacity
+	}
+	if c.HighWatermark == 0 {
+		c.HighWatermark = c.Capacity
+	}
+	if c.LowWatermark == 0 {
+		c.LowWatermark = c.Capacity / 4
+	}
+	return c
+}
+
+// Validate checks that the watermarks fit the capacity.
+func (c BufferedConfig) Validate() error {
+	c = c.withDefaults()
+	if c.Capacity < 1 {
+		return newConfigError("Capacity", fmt.Sprintf("%d", c.Capacity), "must be positive", "capacity >= 1")
+	}
+	if c.HighWatermark < 1 || c.HighWatermark > c.Capacity {
+		return newConfigError(
+			"HighWatermark",
+			fmt.Sprintf("%d", c.HighWatermark),
+			"out of range",
+			fmt.Sprintf("1 <= high watermark <= capacity (%d)", c.Capacity),
+		)
+	}
+	if c.LowWatermark < 0 || c.LowWatermark >= c.HighWatermark {
+		return newConfigError(
+			"LowWatermark",
+			fmt.Sprintf("%d", c.LowWatermark),
+			"out of range",
+			fmt.Sprintf("0 <= low watermark < high watermark (%d)", c.HighWatermark),
+		)
+	}
+	return nil
+}
+
+// BufferedMetrics is a snapshot of a BufferedGenerator's counters.
+type BufferedMetrics struct {
+	Hits         int64 // Next calls served from the buffer
+	Misses       int64 // Next calls that found the buffer empty and generated directly
+	Refills      int64 // Background refill runs
+	RefillErrors int64 // Refill runs that stopped early because of a generator error
+	Discarded    int64 // Buffered IDs dropped by Close
+	Buffered     int   // IDs currently in the buffer
+}
+
+// BufferedGenerator serves IDs from a buffer refilled in the background.
+//
+// IDs are prefetched, so their embedded timestamps are slightly older than the
+// time Next is called, and IDs returned by concurrent Next calls are unique
+// but not ordered by call time. Use it where latency matters more than
+// timestamp precision. IDs from the wrapped Generator (used directly or by
+// other wrappers) never collide with buffered ones.
+//
+// Example:
+//
+//	bg, err := snowflake.NewBuffered(gen, snowflake.BufferedConfig{Capacity: 8192})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer bg.Close()
+//
+//	id, err := bg.Next(ctx)
+type BufferedGenerator struct {
+	gen  *Generator
+	cfg  BufferedConfig
+	buf  chan ID       // Ring buffer of prefetched IDs
+	fill chan struct{} // Refill requests (capacity 1, coalescing)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	closeOnce sync.Once
+	closed    atomic.Bool
+
+	hits         atomic.Int64
+	misses       atomic.Int64
+	refills      atomic.Int64
+	refillErrors atomic.Int64
+	discarded    atomic.Int64
+}
+
+// NewBuffered wraps gen in a BufferedGenerator and starts its refill goroutine.
+//
+// The buffer starts empty and fills in the background; early calls to Next
+// may miss. Call Close to stop the goroutine.
+func NewBuffered(gen *Generator, cfg BufferedConfig) (*BufferedGenerator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg = cfg.withDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &BufferedGenerator{
+		gen:    gen,
+		cfg:    cfg,
+		buf:    make(chan ID, cfg.Capacity),
+		fill:   make(chan struct{}, 1),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go b.refillLoop(ctx)
+	b.requestRefill()
+
+	return b, nil
+}
+
+// Next returns an ID from the buffer, or generates one directly if the buffer
+// is empty.
+//
+// A buffer hit never waits for the clock. On a miss Next falls back to the
+// wrapped Generator, which may wait for the next time unit, and ctx bounds
+// that wait.
+//
+// Returns ErrGeneratorClosed after Close.
+func (b *BufferedGenerator) Next(ctx context.Context) (ID, error) {
+	if b.closed.Load() {
+		return 0, ErrGeneratorClosed
+	}
+
+	select {
+	case id, ok := <-b.buf:
+		if !ok {
+			return 0, ErrGeneratorClosed
+		}
+		b.hits.Add(1)
+		if len(b.buf) < b.cfg.LowWatermark {
+			b.requestRefill()
+		}
+		return id, nil
+	default:
+	}
+
+	b.misses.Add(1)
+	b.requestRefill()
+	return b.gen.GenerateIDWithContext(ctx)
+}
+
+// Generator returns the wrapped generator.
+func (b *BufferedGenerator) Generator() *Generator {
+	return b.gen
+}
+
+// Metrics returns a snapshot of the buffer counters.
+func (b *BufferedGenerator) Metrics() BufferedMetrics {
+	return BufferedMetrics{
+		Hits:         b.hits.Load(),
+		Misses:       b.misses.Load(),
+		Refills:      b.refills.Load(),
+		RefillErrors: b.refillErrors.Load(),
+		Discarded:    b.discarded.Load(),
+		Buffered:     len(b.buf),
+	}
+}
+
+// Close stops the refill goroutine and drains the buffer.
+//
+// Buffered IDs are discarded and counted in Metrics().Discarded; skipping them
+// is harmless since IDs need not be dense. Close is idempotent and safe to call
+// concurrently with Next.
+func (b *BufferedGenerator) Close() error {
+	b.closeOnce.Do(func() {
+		b.closed.Store(true)
+		b.cancel()
+		<-b.done
+
+		// No more sends after the refill goroutine exits
+		close(b.buf)
+		for range b.buf {
+			b.discarded.Add(1)
+		}
+	})
+	return nil
+}
+
+// requestRefill wakes the refill goroutine without blocking. Requests made
+// while a refill is already pending are coalesced.
+func (b *BufferedGenerator) requestRefill() {
+	select {
+	case b.fill <- struct{}{}:
+	default:
+	}
+}
+
+// refillLoop tops up the buffer to the high watermark whenever requested.
+func (b *BufferedGenerator) refillLoop(ctx context.Context) {
+	defer close(b.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.fill:
+		}
+
+		b.refills.Add(1)
+		for need := b.cfg.HighWatermark - len(b.buf); need > 0; need = b.cfg.HighWatermark - len(b.buf) {
+			// Generate at most one time unit's worth per batch to keep the lock short
+			ids, err := b.gen.GenerateBatch(ctx, min(need, int(b.gen.maxSequence+1)))
+			for _, id := range ids {
+				// Only this goroutine sends, so the buffer has room for every ID
+				select {
+				case b.buf <- id:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					b.refillErrors.Add(1)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBufferedConfig_Validate(t *testing.T) {
+	valid := []BufferedConfig{
+		{},
+		{Capacity: 100},
+		{Capacity: 100, LowWatermark: 10, HighWatermark: 50},
+		{Capacity: 1},
+	}
+	for _, cfg := range valid {
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", cfg, err)
+		}
+	}
+
+	invalid := []BufferedConfig{
+		{Capacity: -1},
+		{Capacity: 100, HighWatermark: 200},
+		{Capacity: 100, LowWatermark: 60, HighWatermark: 50},
+		{Capacity: 100, LowWatermark: -1},
+	}
+	for _, cfg := range invalid {
+		if err := cfg.Validate(); !IsConfigError(err) {
+			t.Errorf("Validate(%+v) error = %v, want ConfigError", cfg, err)
+		}
+	}
+}
+
+// waitBuffered waits until the buffer holds at least n IDs.
+func waitBuffered(t *testing.T, b *BufferedGenerator, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for b.Metrics().Buffered < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("buffer did not fill to %d (have %d)", n, b.Metrics().Buffered)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBufferedGenerator_HitsAndRefill(t *testing.T) {
+	gen, _ := New(5)
+	b, err := NewBuffered(gen, BufferedConfig{Capacity: 1000, LowWatermark: 200, HighWatermark: 800})
+	if err != nil {
+		t.Fatalf("NewBuffered() error = %v", err)
+	}
+	defer b.Close()
+
+	waitBuffered(t, b, 800)
+	if got := b.Metrics().Buffered; got > 800 {
+		t.Errorf("buffer filled to %d, want at most the high watermark 800", got)
+	}
+
+	ctx := context.Background()
+	seen := make(map[ID]bool)
+	for i := 0; i < 700; i++ {
+		id, err := b.Next(ctx)
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate ID %d", id)
+		}
+		seen[id] = true
+	}
+
+	m := b.Metrics()
+	if m.Hits != 700 || m.Misses != 0 {
+		t.Errorf("metrics = %+v, want 700 hits and no misses", m)
+	}
+
+	// Dropping below the low watermark triggers a refill
+	waitBuffered(t, b, 800)
+	if b.Metrics().Refills < 2 {
+		t.Errorf("Refills = %d, want >= 2", b.Metrics().Refills)
+	}
+}
+
+func TestBufferedGenerator_MissFallsBack(t *testing.T) {
+	gen, _ := New(5)
+	b, _ := NewBuffered(gen, BufferedConfig{Capacity: 10, LowWatermark: 1, HighWatermark: 10})
+	defer b.Close()
+
+	// Drain faster than the refill can keep up
+	ctx := context.Background()
+	seen := make(map[ID]bool)
+	for i := 0; i < 5000; i++ {
+		id, err := b.Next(ctx)
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate ID %d", id)
+		}
+		seen[id] = true
+	}
+
+	m := b.Metrics()
+	if m.Hits+m.Misses != 5000 {
+		t.Errorf("hits+misses = %d, want 5000", m.Hits+m.Misses)
+	}
+}
+
+func TestBufferedGenerator_Concurrent(t *testing.T) {
+	gen, _ := New(9)
+	b, _ := NewBuffered(gen, BufferedConfig{Capacity: 512})
+	defer b.Close()
+
+	var mu sync.Mutex
+	seen := make(map[ID]bool)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				id, err := b.Next(context.Background())
+				if err != nil {
+					t.Errorf("Next() error = %v", err)
+					return
+				}
+				// Interleave direct generation on the same generator
+				direct := gen.MustGenerateID()
+
+				mu.Lock()
+				if seen[id] || seen[direct] {
+					t.Errorf("duplicate ID")
+				}
+				seen[id], seen[direct] = true, true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBufferedGenerator_Close(t *testing.T) {
+	gen, _ := New(5)
+	b, _ := NewBuffered(gen, BufferedConfig{Capacity: 100})
+	waitBuffered(t, b, 100)
+
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("second Close() error = %v", err)
+	}
+
+	m := b.Metrics()
+	if m.Buffered != 0 || m.Discarded != 100 {
+		t.Errorf("after Close metrics = %+v, want empty buffer and 100 discarded", m)
+	}
+	if _, err := b.Next(context.Background()); !errors.Is(err, ErrGeneratorClosed) {
+		t.Errorf("Next() after Close error = %v, want ErrGeneratorClosed", err)
+	}
+}
+
+func TestBufferedGenerator_ContextOnMiss(t *testing.T) {
+	gen, _ := New(5)
+	b, _ := NewBuffered(gen, BufferedConfig{Capacity: 1})
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Hits ignore the context; keep calling until we miss
+	for i := 0; i < 100; i++ {
+		if _, err := b.Next(ctx); err != nil {
+			if !errors.Is(err, ErrContextCanceled) {
+				t.Fatalf("Next(canceled) error = %v, want ErrContextCanceled", err)
+			}
+			return
+		}
+	}
+	t.Fatal("expected a miss to surface the canceled context")
+}
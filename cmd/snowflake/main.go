@@ -5,16 +5,25 @@
 //   snowflake parse <id>             Parse and inspect an ID
 //   snowflake encode <id> <format>   Convert ID to different format
 //   snowflake validate <id>          Validate an ID
+//   snowflake range --from --to      Convert a time window to an ID range
+//   snowflake partitions [flags]     Print partition DDL for a time window
+//   snowflake layout list|show       List or describe bit layouts
+//   snowflake layout recommend       Recommend a bit layout from requirements
+//   snowflake epoch plan [flags]     Plan a switch to a new epoch or layout
+//   snowflake translate --from --to  Re-derive IDs from stdin for another layout
 //   snowflake bench                  Run performance benchmarks
 //
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -39,6 +48,16 @@ func main() {
 		cmdEncode(os.Args[2:])
 	case "validate", "val", "v":
 		cmdValidate(os.Args[2:])
+	case "range", "r":
+		cmdRange(os.Args[2:])
+	case "partitions", "part":
+		cmdPartitions(os.Args[2:])
+	case "layout", "layouts":
+		cmdLayout(os.Args[2:])
+	case "epoch":
+		cmdEpoch(os.Args[2:])
+	case "translate", "tr":
+		cmdTranslate(os.Args[2:])
 	case "bench", "benchmark", "b":
 		cmdBench(os.Args[2:])
 	case "version", "--version", "-v":
@@ -63,6 +82,11 @@ Commands:
   parse, p              Parse and inspect an ID
   encode, enc, e        Convert ID between formats
   validate, val, v      Validate an ID structure
+  range, r              Convert a time window to an ID range
+  partitions, part      Print time-bucketed partition DDL
+  layout                List, describe or recommend bit layouts
+  epoch                 Plan a switch to a new epoch or layout
+  translate, tr         Re-derive IDs from stdin for another layout or epoch
   bench, b              Run performance benchmarks
   version               Show version information
   help                  Show this help message
@@ -83,6 +107,27 @@ Examples:
   # Validate an ID
   snowflake validate 1234567890123456789
 
+  # Find the ID range for one hour
+  snowflake range --from 2024-06-01T12:00:00Z --to 2024-06-01T13:00:00Z
+
+  # Daily PostgreSQL partitions for June
+  snowflake partitions --interval day --from 2024-06-01T00:00:00Z --to 2024-06-30T00:00:00Z
+
+  # Generate IDs with a different bit layout
+  snowflake generate --layout superior --worker 10000
+
+  # Describe a layout spec
+  snowflake layout show 40/14/9@1ms
+
+  # Recommend a layout for 5000 workers at 50K IDs/sec each for 30 years
+  snowflake layout recommend --workers 5000 --peak 50000 --lifespan 30y
+
+  # Plan a new epoch for a layout running since 2000, switching in 2027
+  snowflake epoch plan --epoch 2000-01-01T00:00:00Z --cutover 2027-01-01T00:00:00Z
+
+  # Translate sorted IDs to another layout
+  psql -Atc 'SELECT id FROM events ORDER BY id' | snowflake translate --from default --to superior
+
   # Run benchmarks
   snowflake bench --duration 5s
 
@@ -99,10 +144,11 @@ For detailed help on a command:
 func cmdGenerate(args []string) {
 	fs := flag.NewFlagSet("generate", flag.ExitOnError)
 	count := fs.Int("count", 1, "Number of IDs to generate")
-	workerID := fs.Int64("worker", 0, "Worker ID (0-1023)")
-	format := fs.String("format", "decimal", "Output format: decimal, base32, base58, base62, hex")
+	workerID := fs.Int64("worker", 0, "Worker ID within the layout's worker bits (0-1023 for default)")
+	format := fs.String("format", "decimal", "Output format: decimal, base32, base58, base62, hex, grouped")
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	batch := fs.Bool("batch", false, "Use batch generation for better performance")
+	layout := layoutFlag(fs)
 
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, `Usage: snowflake generate [flags]
@@ -111,13 +157,17 @@ Generate one or more Snowflake IDs.
 
 Flags:
   --count N          Number of IDs to generate (default: 1)
-  --worker N         Worker ID 0-1023 (default: 0)
-  --format FORMAT    Output format: decimal, base32, base58, base62, hex (default: decimal)
+  --worker N         Worker ID within the layout's worker bits
+                     (0-1023 for default; default: 0)
+  --format FORMAT    Output format: decimal, base32, base58, base62, hex, grouped
+                     (default: decimal)
   --json             Output as JSON with full details
   --batch            Use batch generation (faster for large counts)
+  --layout LAYOUT    Bit layout name or spec (default: default, 41/10/12@1ms)
 
 Examples:
   snowflake generate --worker 42
+  snowflake generate --layout superior --worker 10000
   snowflake generate --count 1000 --format base62 --worker 42
   snowflake generate --json --worker 5
 `)
@@ -126,7 +176,9 @@ Examples:
 	fs.Parse(args)
 
 	// Create generator
-	gen, err := snowflake.New(*workerID)
+	cfg := snowflake.DefaultConfig(*workerID)
+	cfg.Layout = *layout
+	gen, err := snowflake.NewWithConfig(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating generator: %v\n", err)
 		os.Exit(1)
@@ -161,7 +213,7 @@ Examples:
 
 	// Output results
 	if *jsonOutput {
-		outputJSON(ids, duration, *workerID)
+		outputJSON(ids, duration, *workerID, gen.Scheme())
 	} else {
 		for _, id := range ids {
 			fmt.Println(formatID(id, *format))
@@ -177,23 +229,10 @@ Examples:
 }
 
 func formatID(id snowflake.ID, format string) string {
-	switch strings.ToLower(format) {
-	case "base32", "b32":
-		return id.Base32()
-	case "base58", "b58":
-		return id.Base58()
-	case "base62", "b62":
-		return id.Base62()
-	case "hex", "x":
-		return id.Hex()
-	case "binary", "bin":
-		return id.Base2()
-	default:
-		return id.String()
-	}
+	return id.Format(format)
 }
 
-func outputJSON(ids []snowflake.ID, duration time.Duration, workerID int64) {
+func outputJSON(ids []snowflake.ID, duration time.Duration, workerID int64, scheme snowflake.Scheme) {
 	type IDInfo struct {
 		ID        string    `json:"id"`
 		Base62    string    `json:"base62"`
@@ -213,12 +252,12 @@ func outputJSON(ids []snowflake.ID, duration time.Duration, workerID int64) {
 
 	infos := make([]IDInfo, len(ids))
 	for i, id := range ids {
-		ts, worker, seq := id.Components()
+		_, worker, seq := scheme.Components(id)
 		infos[i] = IDInfo{
 			ID:        id.String(),
 			Base62:    id.Base62(),
 			Hex:       id.Hex(),
-			Timestamp: time.UnixMilli(ts),
+			Timestamp: scheme.Time(id),
 			Worker:    worker,
 			Sequence:  seq,
 		}
@@ -243,51 +282,70 @@ func outputJSON(ids []snowflake.ID, duration time.Duration, workerID int64) {
 // ============================================================================
 
 func cmdParse(args []string) {
-	if len(args) < 1 {
-		fmt.Fprintf(os.Stderr, "Usage: snowflake parse <id>\n")
-		fmt.Fprintf(os.Stderr, "\nParse and inspect a Snowflake ID.\n")
-		fmt.Fprintf(os.Stderr, "\nExamples:\n")
-		fmt.Fprintf(os.Stderr, "  snowflake parse 1234567890123456789\n")
-		fmt.Fprintf(os.Stderr, "  snowflake parse 7n42dgm5tflk  # Base62 format\n")
+	fs := flag.NewFlagSet("parse", flag.ExitOnError)
+	formats := fs.String("format", "", "Comma-separated list of allowed input formats")
+	epoch := fs.Int64("epoch", snowflake.Epoch, "Custom epoch in Unix milliseconds")
+	layout := layoutFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: snowflake parse [flags] <id>
+
+Parse and inspect a Snowflake ID.
+
+Untagged input must be valid in exactly one format. Prefix the ID with a
+format tag (0x, hex:, b62:, b58:, b32:, dec:, grp:) to remove any ambiguity.
+Grouped IDs (BNEO-O6T6-6UYE-I3) are recognized without a tag.
+
+Flags:
+  --format LIST      Allowed input formats, e.g. "decimal,base62"
+                     (default: %s)
+  --epoch MS         Custom epoch in Unix milliseconds (default: %d)
+  --layout LAYOUT    Bit layout name or spec (default: default, 41/10/12@1ms)
+
+Examples:
+  snowflake parse 1234567890123456789
+  snowflake parse b62:1tckI1NfUnH
+  snowflake parse 0x112210f47de98115
+  snowflake parse BNEO-O6T6-6UYE-I3
+  snowflake parse --format base62 1tckI1NfUnH
+  snowflake parse --layout superior 1234567890123456789
+`, formatList(snowflake.DefaultParseEncodings), snowflake.Epoch)
+	}
+
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
 		os.Exit(1)
 	}
 
-	idStr := args[0]
+	idStr := fs.Arg(0)
 
-	// Try to parse in different formats
-	var id snowflake.ID
-	var err error
+	allowed, err := parseFormatList(*formats)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Try decimal first
-	id, err = snowflake.ParseString(idStr)
+	scheme, err := snowflake.NewScheme(*layout, *epoch)
 	if err != nil {
-		// Try Base62
-		id, err = snowflake.ParseBase62(idStr)
-		if err != nil {
-			// Try Base58
-			id, err = snowflake.ParseBase58(idStr)
-			if err != nil {
-				// Try Hex
-				id, err = snowflake.ParseHex(idStr)
-				if err != nil {
-					// Try Base32
-					id, err = snowflake.ParseBase32(idStr)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "Error: Unable to parse ID '%s'\n", idStr)
-						os.Exit(1)
-					}
-				}
-			}
-		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	id, encoding, err := snowflake.ParseAny(idStr, allowed...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Unable to parse ID '%s': %v\n", idStr, err)
+		os.Exit(1)
 	}
 
 	// Extract components
-	ts, worker, seq := id.Components()
-	timestamp := time.UnixMilli(ts)
-	age := id.Age()
+	ts, worker, seq := scheme.Components(id)
+	timestamp := scheme.Time(id)
+	age := time.Since(timestamp)
 
 	// Print detailed information
 	fmt.Printf("Snowflake ID: %s\n", id)
+	fmt.Printf("Input format: %s\n", encoding)
 	fmt.Printf("\n")
 	fmt.Printf("Components:\n")
 	fmt.Printf("  Timestamp:  %s (%d ms since epoch)\n", timestamp.Format(time.RFC3339), ts)
@@ -300,9 +358,10 @@ func cmdParse(args []string) {
 	fmt.Printf("  Base58:     %s\n", id.Base58())
 	fmt.Printf("  Base32:     %s\n", id.Base32())
 	fmt.Printf("  Hex:        %s\n", id.Hex())
+	fmt.Printf("  Grouped:    %s\n", id.Grouped())
 	fmt.Printf("\n")
 	fmt.Printf("Age:          %v\n", age.Round(time.Millisecond))
-	fmt.Printf("Valid:        %v\n", id.IsValid())
+	fmt.Printf("Valid:        %v\n", len(idProblems(scheme, id)) == 0)
 }
 
 // ============================================================================
@@ -320,9 +379,10 @@ func cmdEncode(args []string) {
 		fmt.Fprintf(os.Stderr, "  base32, b32        z-base-32\n")
 		fmt.Fprintf(os.Stderr, "  hex, x             Hexadecimal\n")
 		fmt.Fprintf(os.Stderr, "  binary, bin        Binary string\n")
+		fmt.Fprintf(os.Stderr, "  grouped, grp       Grouped with check character (BNEO-O6T6-6UYE-I3)\n")
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  snowflake encode 1234567890123456789 base62\n")
-		fmt.Fprintf(os.Stderr, "  snowflake encode 7n42dgm5tflk decimal\n")
+		fmt.Fprintf(os.Stderr, "  snowflake encode b62:1tckI1NfUnH decimal\n")
 		os.Exit(1)
 	}
 
@@ -340,33 +400,39 @@ func cmdEncode(args []string) {
 	fmt.Println(formatID(id, format))
 }
 
+// parseIDFlexible parses an ID in any unambiguous default format.
+//
+// Tagged input (0x..., b62:...) is always accepted; untagged input that is
+// valid in more than one format is rejected rather than guessed.
 func parseIDFlexible(idStr string) (snowflake.ID, error) {
-	// Try decimal first
-	id, err := snowflake.ParseString(idStr)
-	if err == nil {
-		return id, nil
-	}
+	id, _, err := snowflake.ParseAny(idStr)
+	return id, err
+}
 
-	// Try Base62
-	id, err = snowflake.ParseBase62(idStr)
-	if err == nil {
-		return id, nil
+// formatList renders encodings as a comma-separated list for help text.
+func formatList(encodings []snowflake.Encoding) string {
+	names := make([]string, len(encodings))
+	for i, enc := range encodings {
+		names[i] = string(enc)
 	}
+	return strings.Join(names, ", ")
+}
 
-	// Try Base58
-	id, err = snowflake.ParseBase58(idStr)
-	if err == nil {
-		return id, nil
+// parseFormatList converts a comma-separated list of format names into
+// encodings. An empty list selects the library defaults.
+func parseFormatList(list string) ([]snowflake.Encoding, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
 	}
-
-	// Try Hex
-	id, err = snowflake.ParseHex(idStr)
-	if err == nil {
-		return id, nil
+	var encodings []snowflake.Encoding
+	for _, name := range strings.Split(list, ",") {
+		enc, err := snowflake.ParseEncoding(name)
+		if err != nil {
+			return nil, err
+		}
+		encodings = append(encodings, enc)
 	}
-
-	// Try Base32
-	return snowflake.ParseBase32(idStr)
+	return encodings, nil
 }
 
 // ============================================================================
@@ -374,15 +440,38 @@ func parseIDFlexible(idStr string) (snowflake.ID, error) {
 // ============================================================================
 
 func cmdValidate(args []string) {
-	if len(args) < 1 {
-		fmt.Fprintf(os.Stderr, "Usage: snowflake validate <id>\n")
-		fmt.Fprintf(os.Stderr, "\nValidate the structure of a Snowflake ID.\n")
-		fmt.Fprintf(os.Stderr, "\nExamples:\n")
-		fmt.Fprintf(os.Stderr, "  snowflake validate 1234567890123456789\n")
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	epoch := fs.Int64("epoch", snowflake.Epoch, "Custom epoch in Unix milliseconds")
+	layout := layoutFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: snowflake validate [flags] <id>
+
+Validate the structure of a Snowflake ID.
+
+Flags:
+  --epoch MS         Custom epoch in Unix milliseconds (default: %d)
+  --layout LAYOUT    Bit layout name or spec (default: default, 41/10/12@1ms)
+
+Examples:
+  snowflake validate 1234567890123456789
+  snowflake validate --layout superior 1234567890123456789
+`, snowflake.Epoch)
+	}
+
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
 		os.Exit(1)
 	}
 
-	idStr := args[0]
+	idStr := fs.Arg(0)
+
+	scheme, err := snowflake.NewScheme(*layout, *epoch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Parse ID
 	id, err := parseIDFlexible(idStr)
@@ -392,25 +481,21 @@ func cmdValidate(args []string) {
 		os.Exit(1)
 	}
 
+	ts, worker, seq := scheme.Components(id)
+
 	// Validate structure
-	if !id.IsValid() {
+	if problems := idProblems(scheme, id); len(problems) > 0 {
 		fmt.Printf("INVALID: ID structure is invalid\n")
 
 		// Show why it's invalid
-		ts, worker, seq := id.Components()
+		_, _, maxWorker, maxSequence := scheme.Layout.CalculateShifts()
 		fmt.Printf("\nComponents:\n")
-		fmt.Printf("  Timestamp:  %d ms since epoch\n", ts)
-		fmt.Printf("  Worker ID:  %d (valid range: 0-1023)\n", worker)
-		fmt.Printf("  Sequence:   %d (valid range: 0-4095)\n", seq)
+		fmt.Printf("  Timestamp:  %d ms since Unix epoch\n", ts)
+		fmt.Printf("  Worker ID:  %d (valid range: 0-%d)\n", worker, maxWorker)
+		fmt.Printf("  Sequence:   %d (valid range: 0-%d)\n", seq, maxSequence)
 
-		if ts <= snowflake.Epoch {
-			fmt.Printf("\n  Error: Timestamp is before or equal to epoch\n")
-		}
-		if worker < 0 || worker > 1023 {
-			fmt.Printf("\n  Error: Worker ID out of range\n")
-		}
-		if seq < 0 || seq > 4095 {
-			fmt.Printf("\n  Error: Sequence out of range\n")
+		for _, problem := range problems {
+			fmt.Printf("\n  Error: %s\n", problem)
 		}
 
 		os.Exit(1)
@@ -419,13 +504,680 @@ func cmdValidate(args []string) {
 	fmt.Printf("VALID: ID structure is valid\n")
 
 	// Show components
-	ts, worker, seq := id.Components()
-	timestamp := time.UnixMilli(ts)
+	timestamp := scheme.Time(id)
 	fmt.Printf("\nComponents:\n")
 	fmt.Printf("  Timestamp:  %s\n", timestamp.Format(time.RFC3339))
 	fmt.Printf("  Worker ID:  %d\n", worker)
 	fmt.Printf("  Sequence:   %d\n", seq)
-	fmt.Printf("  Age:        %v\n", id.Age().Round(time.Millisecond))
+	fmt.Printf("  Age:        %v\n", time.Since(timestamp).Round(time.Millisecond))
+}
+
+// idProblems applies the checks of ID.IsValid under the scheme's layout and
+// epoch, describing each one the ID fails. Worker and sequence always fit
+// their fields, so only the sign and timestamp can be wrong.
+func idProblems(scheme snowflake.Scheme, id snowflake.ID) []string {
+	if id <= 0 {
+		return []string{"ID is not positive"}
+	}
+	var problems []string
+	ts := scheme.Time(id)
+	if !ts.After(time.UnixMilli(scheme.Epoch)) {
+		problems = append(problems, "Timestamp is before or equal to epoch")
+	}
+	if ts.After(time.Now().Add(snowflake.DayInMilliseconds * time.Millisecond)) {
+		problems = append(problems, "Timestamp is more than a day in the future")
+	}
+	return problems
+}
+
+// ============================================================================
+// Range Command
+// ============================================================================
+
+func cmdRange(args []string) {
+	fs := flag.NewFlagSet("range", flag.ExitOnError)
+	from := fs.String("from", "", "Window start (RFC3339, Unix milliseconds or \"now\")")
+	to := fs.String("to", "now", "Window end (RFC3339, Unix milliseconds or \"now\")")
+	epoch := fs.Int64("epoch", snowflake.Epoch, "Custom epoch in Unix milliseconds")
+	format := fs.String("format", "decimal", "Output format: decimal, base32, base58, base62, hex, grouped")
+	layout := layoutFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: snowflake range --from TIME [--to TIME] [flags]
+
+Convert a time window to the inclusive range of IDs generated within it,
+for use in "WHERE id BETWEEN first AND last" index scans.
+
+Times may be RFC3339 (2024-06-01T12:00:00Z), Unix milliseconds or "now".
+
+Flags:
+  --from TIME        Window start (required)
+  --to TIME          Window end (default: now)
+  --epoch MS         Custom epoch in Unix milliseconds (default: %d)
+  --format FORMAT    Output format: decimal, base32, base58, base62, hex, grouped
+                     (default: decimal)
+  --layout LAYOUT    Bit layout name or spec (default: default, 41/10/12@1ms)
+
+Examples:
+  snowflake range --from 2024-06-01T00:00:00Z --to 2024-06-02T00:00:00Z
+  snowflake range --from 1717200000000 --to now --format hex
+`, snowflake.Epoch)
+	}
+
+	fs.Parse(args)
+
+	if *from == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	start, err := parseTimeFlag(*from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --from: %v\n", err)
+		os.Exit(1)
+	}
+	end, err := parseTimeFlag(*to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --to: %v\n", err)
+		os.Exit(1)
+	}
+
+	scheme, err := snowflake.NewScheme(*layout, *epoch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	first, last, err := scheme.IDRangeForWindow(start, end)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("From:   %s\n", start.UTC().Format(time.RFC3339Nano))
+	fmt.Printf("To:     %s\n", end.UTC().Format(time.RFC3339Nano))
+	fmt.Printf("First:  %s\n", formatID(first, *format))
+	fmt.Printf("Last:   %s\n", formatID(last, *format))
+	fmt.Printf("\n")
+	fmt.Printf("WHERE id BETWEEN %d AND %d\n", first, last)
+}
+
+// ============================================================================
+// Partitions Command
+// ============================================================================
+
+func cmdPartitions(args []string) {
+	fs := flag.NewFlagSet("partitions", flag.ExitOnError)
+	intervalStr := fs.String("interval", "day", "Bucket size: hour, day, week or a duration")
+	from := fs.String("from", "", "Window start (RFC3339, Unix milliseconds or \"now\")")
+	to := fs.String("to", "now", "Window end (RFC3339, Unix milliseconds or \"now\")")
+	dialectStr := fs.String("dialect", "postgres", "SQL dialect: postgres, mysql, sqlite")
+	table := fs.String("table", "events", "Parent table name")
+	epoch := fs.Int64("epoch", snowflake.Epoch, "Custom epoch in Unix milliseconds")
+	list := fs.Bool("list", false, "List buckets instead of printing DDL")
+	layout := layoutFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: snowflake partitions --from TIME [--to TIME] [flags]
+
+Split a time window into fixed-size buckets and print the DDL that creates
+one ID-range partition per bucket. Buckets are aligned to UTC.
+
+Flags:
+  --interval VALUE   Bucket size: hour, day, week or a duration like 6h
+                     (default: day)
+  --from TIME        Window start (required)
+  --to TIME          Window end (default: now)
+  --dialect NAME     SQL dialect: postgres, mysql, sqlite (default: postgres)
+  --table NAME       Parent table name (default: events)
+  --epoch MS         Custom epoch in Unix milliseconds (default: %d)
+  --list             List bucket names, time bounds and ID bounds instead of DDL
+  --layout LAYOUT    Bit layout name or spec (default: default, 41/10/12@1ms)
+
+Examples:
+  snowflake partitions --interval day --from 2024-06-01T00:00:00Z --to 2024-06-07T00:00:00Z
+  snowflake partitions --interval hour --from now --to now --dialect mysql
+  snowflake partitions --interval week --from 2024-01-01T00:00:00Z --list
+`, snowflake.Epoch)
+	}
+
+	fs.Parse(args)
+
+	if *from == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	interval, err := snowflake.ParseInterval(*intervalStr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	dialect, err := snowflake.ParseDialect(*dialectStr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	start, err := parseTimeFlag(*from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --from: %v\n", err)
+		os.Exit(1)
+	}
+	end, err := parseTimeFlag(*to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --to: %v\n", err)
+		os.Exit(1)
+	}
+
+	scheme, err := snowflake.NewScheme(*layout, *epoch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	p, err := snowflake.NewPartitioner(*table, scheme, interval)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	buckets, err := p.Buckets(start, end)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *list {
+		for _, b := range buckets {
+			fmt.Printf("%-28s %s  %s  %d  %d\n", b.Name,
+				b.Start.Format(time.RFC3339), b.End.Format(time.RFC3339), b.MinID, b.MaxID)
+		}
+		return
+	}
+
+	ddl, err := p.DDL(dialect, buckets)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(ddl)
+}
+
+// layoutFlag defines a --layout flag accepting a layout name or spec.
+func layoutFlag(fs *flag.FlagSet) *snowflake.BitLayout {
+	layout := new(snowflake.BitLayout)
+	fs.TextVar(layout, "layout", snowflake.LayoutDefault,
+		"Bit layout: "+strings.Join(snowflake.LayoutNames(), ", ")+" or a spec like 40/14/9@1ms")
+	return layout
+}
+
+// parseTimeFlag parses an RFC3339 timestamp, Unix milliseconds or "now".
+func parseTimeFlag(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "now") {
+		return time.Now(), nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// ============================================================================
+// Layout Command
+// ============================================================================
+
+func cmdLayout(args []string) {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, `Usage: snowflake layout <list|show|recommend> [args]
+
+List the named bit layouts, show the capacity of layouts given by name
+or by spec (timestamp/worker/sequence@unit), or recommend one.
+
+Commands:
+  list               List named layouts with their specs and capacity
+  show LAYOUT...     Describe one or more layouts
+  recommend [flags]  Rank layouts against requirements (--help for flags)
+
+Examples:
+  snowflake layout list
+  snowflake layout show superior
+  snowflake layout show 40/14/9@1ms 39/15/9@10ms
+  snowflake layout recommend --workers 5000 --peak 50000 --lifespan 30y
+`)
+	}
+	if len(args) == 0 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		fmt.Printf("%-12s %-14s %10s %14s %10s\n", "NAME", "SPEC", "WORKERS", "IDS/SEC/WORKER", "LIFESPAN")
+		for _, name := range snowflake.LayoutNames() {
+			layout, _ := snowflake.LookupLayout(name)
+			c := layout.CalculateCapacity()
+			fmt.Printf("%-12s %-14s %10d %14d %9.0fy\n", name, layout, c.MaxWorkers,
+				c.ThroughputPerWorker, c.Lifespan.Hours()/24/365.25)
+		}
+	case "show":
+		if len(args) < 2 {
+			usage()
+			os.Exit(1)
+		}
+		for i, arg := range args[1:] {
+			layout, err := snowflake.ParseBitLayout(arg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			printLayout(arg, layout)
+		}
+	case "recommend", "rec":
+		cmdLayoutRecommend(args[1:])
+	case "help", "--help", "-h":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown layout command: %s\n\n", args[0])
+		usage()
+		os.Exit(1)
+	}
+}
+
+// printLayout prints a layout's bit allocation and capacity.
+func printLayout(name string, layout snowflake.BitLayout) {
+	c := layout.CalculateCapacity()
+	overflow := time.UnixMilli(snowflake.Epoch).Add(c.Lifespan)
+
+	fmt.Printf("Layout:        %s\n", name)
+	fmt.Printf("Spec:          %s\n", layout)
+	fmt.Printf("Bits:          %d timestamp, %d worker, %d sequence\n",
+		layout.TimestampBits, layout.WorkerBits, layout.SequenceBits)
+	fmt.Printf("Workers:       %d (IDs 0-%d)\n", c.MaxWorkers, c.MaxWorkers-1)
+	fmt.Printf("Per worker:    %d IDs per %v, %d IDs/sec\n", c.MaxSequence, c.TimeUnit, c.ThroughputPerWorker)
+	fmt.Printf("Total:         %d IDs/sec\n", c.TotalThroughput)
+	fmt.Printf("Lifespan:      %.1f years (until %s with the default epoch)\n",
+		c.Lifespan.Hours()/24/365.25, overflow.UTC().Format(time.DateOnly))
+	for _, warning := range layout.Lint() {
+		fmt.Printf("Warning:       %s\n", warning)
+	}
+}
+
+func cmdLayoutRecommend(args []string) {
+	fs := flag.NewFlagSet("layout recommend", flag.ExitOnError)
+	workers := fs.Int64("workers", 0, "Worker IDs needed, including growth")
+	peak := fs.Int64("peak", 0, "Peak IDs/sec per worker")
+	lifespanStr := fs.String("lifespan", "", "Required lifespan from now, e.g. 30y or 8760h")
+	epochStr := fs.String("epoch", "", "Custom epoch (RFC3339 or Unix milliseconds, default: 2024-01-01)")
+	top := fs.Int("top", 5, "Number of candidates to show")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: snowflake layout recommend [flags]
+
+Rank every valid bit layout against your requirements and explain the
+trade-offs of the best ones. Layouts are ranked by the headroom of their
+tightest dimension: capacity divided by requirement.
+
+Flags:
+  --workers N        Worker IDs needed, including growth
+  --peak N           Peak IDs/sec per worker
+  --lifespan D       Required lifespan from now: years (30y) or a duration
+  --epoch TIME       Custom epoch, RFC3339 or Unix milliseconds (default: 2024-01-01)
+  --top N            Number of candidates to show (default: 5)
+
+Examples:
+  snowflake layout recommend --workers 5000 --peak 50000 --lifespan 30y
+  snowflake layout recommend --workers 200000 --peak 100 --lifespan 50y
+`)
+	}
+
+	fs.Parse(args)
+
+	req := snowflake.Requirements{Workers: *workers, PeakIDsPerSecPerWorker: *peak}
+	if *lifespanStr != "" {
+		lifespan, err := parseLifespan(*lifespanStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --lifespan: %v\n", err)
+			os.Exit(1)
+		}
+		req.Lifespan = lifespan
+	}
+	if *epochStr != "" {
+		epoch, err := parseTimeFlag(*epochStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --epoch: %v\n", err)
+			os.Exit(1)
+		}
+		req.Epoch = epoch.UnixMilli()
+	}
+
+	rec, err := snowflake.RecommendLayout(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if rec.Best.Satisfies {
+		fmt.Printf("Recommended: %s\n", candidateName(rec.Best))
+	} else {
+		fmt.Printf("No layout meets every requirement; closest: %s\n", candidateName(rec.Best))
+	}
+	fmt.Println()
+
+	for i, c := range rec.Candidates[:min(*top, len(rec.Candidates))] {
+		status := "meets requirements"
+		if !c.Satisfies {
+			status = "falls short"
+		}
+		fmt.Printf("%d. %s (%s, headroom %s)\n", i+1, candidateName(c), status, formatHeadroom(c.Headroom()))
+		fmt.Printf("   Workers:   %d (%s)\n", c.Capacity.MaxWorkers, formatHeadroom(c.WorkerHeadroom))
+		fmt.Printf("   Rate:      %d IDs/sec per worker (%s)\n", c.Capacity.ThroughputPerWorker, formatHeadroom(c.ThroughputHeadroom))
+		fmt.Printf("   Overflow:  %s (%s)\n", c.OverflowDate.UTC().Format(time.DateOnly), formatHeadroom(c.LifespanHeadroom))
+		for _, note := range c.TradeOffs {
+			fmt.Printf("   - %s\n", note)
+		}
+	}
+}
+
+// candidateName returns a candidate's spec, with its registered name if any.
+func candidateName(c snowflake.LayoutCandidate) string {
+	if c.Name != "" {
+		return fmt.Sprintf("%s (%s)", c.Layout, c.Name)
+	}
+	return c.Layout.String()
+}
+
+// formatHeadroom formats a headroom ratio like "3.2x".
+func formatHeadroom(h float64) string {
+	if math.IsInf(h, 1) {
+		return "no requirement"
+	}
+	if h >= 10 {
+		return fmt.Sprintf("%.0fx", h)
+	}
+	return fmt.Sprintf("%.2fx", h)
+}
+
+// parseLifespan parses a number of years ("30y") or a Go duration.
+func parseLifespan(s string) (time.Duration, error) {
+	if n, ok := strings.CutSuffix(strings.TrimSpace(s), "y"); ok {
+		years, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(years * 365.25 * 24 * float64(time.Hour)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// ============================================================================
+// Epoch Command
+// ============================================================================
+
+func cmdEpoch(args []string) {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, `Usage: snowflake epoch plan [flags]
+
+Plan a switch to a new epoch or layout before the current one runs out.
+
+Commands:
+  plan [flags]       List new epochs and layouts whose IDs all exceed the
+                     current ones (--help for flags)
+
+Examples:
+  snowflake epoch plan --epoch 2000-01-01T00:00:00Z
+  snowflake epoch plan --layout sonyflake --cutover 2027-01-01T00:00:00Z
+`)
+	}
+	if len(args) == 0 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "plan":
+		cmdEpochPlan(args[1:])
+	case "help", "--help", "-h":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown epoch command: %s\n\n", args[0])
+		usage()
+		os.Exit(1)
+	}
+}
+
+func cmdEpochPlan(args []string) {
+	fs := flag.NewFlagSet("epoch plan", flag.ExitOnError)
+	epochStr := fs.String("epoch", "", "Current epoch (RFC3339 or Unix milliseconds, default: 2024-01-01)")
+	cutoverStr := fs.String("cutover", "now", "When new generators start (RFC3339, Unix milliseconds or \"now\")")
+	margin := fs.Duration("margin", snowflake.DefaultEpochPlanMargin, "Clock skew and borrow-ahead allowance around the cutover")
+	minWorkerBits := fs.Int("min-worker-bits", 0, "Minimum worker bits (default: the current layout's)")
+	minRate := fs.Int64("min-rate", 0, "Minimum IDs/sec per worker")
+	top := fs.Int("top", 5, "Number of candidates to show")
+	layout := layoutFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: snowflake epoch plan [flags]
+
+List new epochs and layouts to switch to at the cutover such that every new
+ID is greater than every existing one, ranked by remaining lifespan. Only the
+ID space above the current scheme's last ID is available, so layouts with more
+timestamp bits or coarser time units last longer at the cost of workers or
+throughput.
+
+Flags:
+  --layout LAYOUT        Current bit layout name or spec (default: default)
+  --epoch TIME           Current epoch, RFC3339 or Unix milliseconds
+                         (default: 2024-01-01)
+  --cutover TIME         When old generators stop and new ones start
+                         (default: now)
+  --margin D             Clock skew and borrow-ahead allowance on both sides
+                         of the cutover (default: 1m)
+  --min-worker-bits N    Minimum worker bits (default: the current layout's)
+  --min-rate N           Minimum IDs/sec per worker
+  --top N                Number of candidates to show (default: 5)
+
+Examples:
+  snowflake epoch plan --epoch 2000-01-01T00:00:00Z
+  snowflake epoch plan --layout sonyflake --cutover 2027-01-01T00:00:00Z --min-rate 10000
+`)
+	}
+
+	fs.Parse(args)
+
+	epoch := snowflake.Epoch
+	if *epochStr != "" {
+		t, err := parseTimeFlag(*epochStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --epoch: %v\n", err)
+			os.Exit(1)
+		}
+		epoch = t.UnixMilli()
+	}
+	cutover, err := parseTimeFlag(*cutoverStr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --cutover: %v\n", err)
+		os.Exit(1)
+	}
+
+	plan, err := snowflake.PlanEpoch(snowflake.Scheme{Layout: *layout, Epoch: epoch}, snowflake.EpochPlanOptions{
+		Cutover:               cutover,
+		Margin:                *margin,
+		MinWorkerBits:         *minWorkerBits,
+		MinIDsPerSecPerWorker: *minRate,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Current:    %s, epoch %s\n", plan.Current.Layout,
+		time.UnixMilli(plan.Current.Epoch).UTC().Format(time.RFC3339))
+	fmt.Printf("Cutover:    %s (margin %v)\n", plan.Cutover.UTC().Format(time.RFC3339), plan.Margin)
+	fmt.Printf("Last ID:    %d\n", plan.LastID)
+	fmt.Printf("Remaining:  %.1f years (overflows %s)\n", yearsBetween(plan.Cutover, plan.CurrentOverflowDate),
+		plan.CurrentOverflowDate.UTC().Format(time.DateOnly))
+	fmt.Println()
+
+	if _, ok := plan.Best(); !ok {
+		fmt.Println("No layout keeps new IDs above the existing ones.")
+	}
+
+	for i, c := range plan.Candidates[:min(*top, len(plan.Candidates))] {
+		name := c.Scheme.Layout.String()
+		if c.Name != "" {
+			name = fmt.Sprintf("%s (%s)", name, c.Name)
+		}
+		if !c.Feasible {
+			fmt.Printf("%d. %s: %s\n", i+1, name, c.Problem)
+			continue
+		}
+		fmt.Printf("%d. %s\n", i+1, name)
+		fmt.Printf("   Epoch:     %s (%d)\n", time.UnixMilli(c.Scheme.Epoch).UTC().Format(time.RFC3339Nano), c.Scheme.Epoch)
+		fmt.Printf("   First ID:  %d\n", c.FirstID)
+		fmt.Printf("   Remaining: %.1f years (overflows %s)\n", yearsBetween(plan.Cutover, c.OverflowDate),
+			c.OverflowDate.UTC().Format(time.DateOnly))
+		fmt.Printf("   Capacity:  %d workers, %d IDs/sec per worker\n", c.Capacity.MaxWorkers, c.Capacity.ThroughputPerWorker)
+	}
+}
+
+// yearsBetween returns the years of 365.25 days from one time to another.
+// Unlike time.Duration it doesn't saturate at about 292 years.
+func yearsBetween(from, to time.Time) float64 {
+	return float64(to.Unix()-from.Unix()) / (365.25 * 24 * 60 * 60)
+}
+
+// ============================================================================
+// Translate Command
+// ============================================================================
+
+func cmdTranslate(args []string) {
+	fs := flag.NewFlagSet("translate", flag.ExitOnError)
+	from := new(snowflake.BitLayout)
+	to := new(snowflake.BitLayout)
+	fs.TextVar(from, "from", snowflake.LayoutDefault, "Source bit layout name or spec")
+	fs.TextVar(to, "to", snowflake.LayoutDefault, "Target bit layout name or spec")
+	fromEpochStr := fs.String("from-epoch", "", "Source epoch (RFC3339 or Unix milliseconds, default: 2024-01-01)")
+	toEpochStr := fs.String("to-epoch", "", "Target epoch (RFC3339 or Unix milliseconds, default: the source epoch)")
+	resequence := fs.Bool("resequence", false, "Renumber sequences from 0 per time unit and worker")
+	unordered := fs.Bool("unordered", false, "Translate each ID independently; input need not be sorted")
+	pairs := fs.Bool("pairs", false, "Print \"old new\" pairs instead of new IDs only")
+	inputs := fs.String("input", "decimal", "Comma-separated list of allowed input formats")
+	format := fs.String("format", "decimal", "Output format: decimal, base32, base58, base62, hex, grouped")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: snowflake translate --from LAYOUT --to LAYOUT [flags] < ids
+
+Read IDs from stdin, one per line, and print each re-derived for the target
+layout and epoch: the same time, worker ID and sequence, in the new bit
+positions. Input must be in ascending order (ORDER BY id), and output keeps
+that order. Stops at the first ID that does not fit the target.
+
+Flags:
+  --from LAYOUT      Source layout name or spec (default: default)
+  --to LAYOUT        Target layout name or spec (default: default)
+  --from-epoch TIME  Source epoch, RFC3339 or Unix milliseconds (default: 2024-01-01)
+  --to-epoch TIME    Target epoch (default: the source epoch)
+  --resequence       Renumber sequences from 0 within each time unit and
+                     worker, so IDs fit a narrower sequence field
+  --unordered        Translate each ID independently; input need not be
+                     sorted (incompatible with --resequence)
+  --pairs            Print "old new" pairs, e.g. for a mapping table
+  --input LIST       Allowed input formats, e.g. "decimal,base62"
+                     (default: decimal)
+  --format FORMAT    Output format: decimal, base32, base58, base62, hex, grouped
+                     (default: decimal)
+
+Examples:
+  psql -Atc 'SELECT id FROM events ORDER BY id' | snowflake translate --from default --to superior
+  snowflake translate --from default --to superior --resequence --pairs < ids.txt > mapping.txt
+`)
+	}
+
+	fs.Parse(args)
+
+	if *resequence && *unordered {
+		fmt.Fprintf(os.Stderr, "Error: --resequence requires ordered input\n")
+		os.Exit(1)
+	}
+
+	fromScheme := snowflake.Scheme{Layout: *from, Epoch: snowflake.Epoch}
+	if *fromEpochStr != "" {
+		t, err := parseTimeFlag(*fromEpochStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --from-epoch: %v\n", err)
+			os.Exit(1)
+		}
+		fromScheme.Epoch = t.UnixMilli()
+	}
+	toScheme := snowflake.Scheme{Layout: *to, Epoch: fromScheme.Epoch}
+	if *toEpochStr != "" {
+		t, err := parseTimeFlag(*toEpochStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --to-epoch: %v\n", err)
+			os.Exit(1)
+		}
+		toScheme.Epoch = t.UnixMilli()
+	}
+
+	allowed, err := parseFormatList(*inputs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --input: %v\n", err)
+		os.Exit(1)
+	}
+
+	tr, err := snowflake.NewTranslator(fromScheme, toScheme, snowflake.TranslatorOptions{Resequence: *resequence})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	translate := tr.Translate
+	if *unordered {
+		translate = func(id snowflake.ID) (snowflake.ID, error) {
+			return snowflake.Translate(id, fromScheme, toScheme)
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	scanner := bufio.NewScanner(os.Stdin)
+	line := 0
+	for scanner.Scan() {
+		line++
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" {
+			continue
+		}
+		id, _, err := snowflake.ParseAny(input, allowed...)
+		if err != nil {
+			out.Flush()
+			fmt.Fprintf(os.Stderr, "Error: line %d: %v\n", line, err)
+			os.Exit(1)
+		}
+		translated, err := translate(id)
+		if err != nil {
+			out.Flush()
+			fmt.Fprintf(os.Stderr, "Error: line %d: %v\n", line, err)
+			os.Exit(1)
+		}
+		if *pairs {
+			fmt.Fprintf(out, "%s %s\n", formatID(id, *format), formatID(translated, *format))
+		} else {
+			fmt.Fprintln(out, formatID(translated, *format))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		out.Flush()
+		fmt.Fprintf(os.Stderr, "Error: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	out.Flush()
+	if *resequence {
+		fmt.Fprintf(os.Stderr, "Translated %d IDs, %d resequenced\n", tr.Count(), tr.Resequenced())
+	}
 }
 
 // ============================================================================
@@ -435,8 +1187,9 @@ func cmdValidate(args []string) {
 func cmdBench(args []string) {
 	fs := flag.NewFlagSet("bench", flag.ExitOnError)
 	duration := fs.Duration("duration", 3*time.Second, "Benchmark duration")
-	workerID := fs.Int64("worker", 0, "Worker ID (0-1023)")
+	workerID := fs.Int64("worker", 0, "Worker ID within the layout's worker bits (0-1023 for default)")
 	batchSize := fs.Int("batch", 100, "Batch size for batch generation test")
+	layout := layoutFlag(fs)
 
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, `Usage: snowflake bench [flags]
@@ -445,19 +1198,24 @@ Run performance benchmarks for ID generation.
 
 Flags:
   --duration D      Benchmark duration (default: 3s)
-  --worker N        Worker ID 0-1023 (default: 0)
+  --worker N        Worker ID within the layout's worker bits
+                    (0-1023 for default; default: 0)
   --batch N         Batch size for batch test (default: 100)
+  --layout LAYOUT   Bit layout name or spec (default: default, 41/10/12@1ms)
 
 Examples:
   snowflake bench --duration 5s
   snowflake bench --worker 42 --duration 10s
+  snowflake bench --layout 39/15/9@1ms
 `)
 	}
 
 	fs.Parse(args)
 
 	// Create generator
-	gen, err := snowflake.New(*workerID)
+	cfg := snowflake.DefaultConfig(*workerID)
+	cfg.Layout = *layout
+	gen, err := snowflake.NewWithConfig(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating generator: %v\n", err)
 		os.Exit(1)
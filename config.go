@@ -0,0 +1,448 @@
+// Package snowflake - config.go loads Config from the environment, flags and files.
+//
+// Every source uses the same settings (worker_id, epoch, layout, ...), so a
+// value reads the same whether it comes from SNOWFLAKE_WORKER_ID, a
+// -snowflake-worker-id flag or a "worker_id" field in a file. Keys are matched
+// ignoring case, '_' and '-', so Go field names ("WorkerID") work too. Loaded
+// configurations are checked with Config.Validate, and malformed values are
+// reported as *ConfigError naming the Config field.
+//
+// Observer, Logger and Health cannot be loaded and keep their values;
+// Config.MarshalJSON omits them.
+
+package snowflake
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configSetting is one loadable Config field.
+type configSetting struct {
+	key   string // Canonical snake_case key
+	field string // Config field name, for ConfigError
+	usage string
+	set   func(c *Config, value string) error
+	get   func(c *Config) string
+
+	// literal marks values get returns as JSON numbers or booleans rather
+	// than strings.
+	literal bool
+}
+
+// configSettings lists the fields that can be loaded, in documentation order.
+var configSettings = []configSetting{
+	{
+		key: "worker_id", field: "WorkerID", usage: "unique worker ID",
+		set: func(c *Config, v string) error {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return newConfigError("WorkerID", v, "not an integer", "decimal worker ID")
+			}
+			c.WorkerID = n
+			return nil
+		},
+		get:     func(c *Config) string { return strconv.FormatInt(c.WorkerID, 10) },
+		literal: true,
+	},
+	{
+		key: "epoch", field: "Epoch", usage: "custom epoch: RFC 3339 time, date or Unix milliseconds",
+		set: func(c *Config, v string) error {
+			ms, err := parseEpoch(v)
+			if err != nil {
+				return newConfigError("Epoch", v, "not a time",
+					"RFC 3339 time (2024-01-01T00:00:00Z), date (2024-01-01) or Unix milliseconds")
+			}
+			c.Epoch = ms
+			return nil
+		},
+		get: func(c *Config) string { return time.UnixMilli(c.Epoch).UTC().Format(time.RFC3339Nano) },
+	},
+	{
+		key: "layout", field: "Layout", usage: "bit layout: preset name (superior) or spec (41/10/12@1ms)",
+		set: func(c *Config, v string) error {
+			l, err := ParseBitLayout(v)
+			if err != nil {
+				return newConfigError("Layout", v, err.Error(), "preset name or timestamp/worker/sequence spec such as 41/10/12@1ms")
+			}
+			c.Layout = l
+			return nil
+		},
+		get: func(c *Config) string {
+			if c.Layout == (BitLayout{}) {
+				return LayoutDefault.String() // As Validate defaults it
+			}
+			return c.Layout.String()
+		},
+	},
+	durationSetting("max_clock_backward", "MaxClockBackward", "largest clock drift to wait out",
+		func(c *Config) *time.Duration { return &c.MaxClockBackward }),
+	{
+		key: "enable_metrics", field: "EnableMetrics", usage: "record latency and wait-time histograms",
+		set: func(c *Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return newConfigError("EnableMetrics", v, "not a boolean", "true or false")
+			}
+			c.EnableMetrics = b
+			return nil
+		},
+		get:     func(c *Config) string { return strconv.FormatBool(c.EnableMetrics) },
+		literal: true,
+	},
+	{
+		key: "name", field: "Name", usage: "generator name for metrics and logs",
+		set: func(c *Config, v string) error {
+			c.Name = v
+			return nil
+		},
+		get: func(c *Config) string { return c.Name },
+	},
+	durationSetting("max_borrow_ahead", "MaxBorrowAhead", "how far to borrow ahead of the clock on overflow (0 = never)",
+		func(c *Config) *time.Duration { return &c.MaxBorrowAhead }),
+	durationSetting("max_wait", "MaxWait", "longest wait for the clock per ID (0 = no limit)",
+		func(c *Config) *time.Duration { return &c.MaxWait }),
+}
+
+// durationSetting returns a configSetting for a time.Duration field.
+func durationSetting(key, field, usage string, ptr func(c *Config) *time.Duration) configSetting {
+	return configSetting{
+		key: key, field: field, usage: usage,
+		set: func(c *Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return newConfigError(field, v, "not a duration", "Go duration such as 5ms or 1s")
+			}
+			*ptr(c) = d
+			return nil
+		},
+		get: func(c *Config) string { return ptr(c).String() },
+	}
+}
+
+// parseEpoch parses Unix milliseconds, an RFC 3339 time or a date.
+func parseEpoch(s string) (int64, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ms, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UnixMilli(), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid epoch %q", s)
+}
+
+// normalizeConfigKey folds case and drops '_' and '-', so "worker_id",
+// "WORKER-ID" and "WorkerID" match.
+func normalizeConfigKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.NewReplacer("_", "", "-", "").Replace(key)
+}
+
+// lookupConfigSetting finds the setting for key.
+func lookupConfigSetting(key string) (configSetting, bool) {
+	norm := normalizeConfigKey(key)
+	for _, s := range configSettings {
+		if normalizeConfigKey(s.key) == norm {
+			return s, true
+		}
+	}
+	return configSetting{}, false
+}
+
+// configKeys returns the canonical keys, for error messages.
+func configKeys() string {
+	keys := make([]string, len(configSettings))
+	for i, s := range configSettings {
+		keys[i] = s.key
+	}
+	return strings.Join(keys, ", ")
+}
+
+// keyValue is one key and raw value read from a source.
+type keyValue struct {
+	key, value string
+}
+
+// apply sets each value on c, stopping at the first error. It reports
+// whether the worker ID was among them.
+func (c *Config) apply(values []keyValue) (workerSet bool, err error) {
+	for _, kv := range values {
+		s, ok := lookupConfigSetting(kv.key)
+		if !ok {
+			return false, newConfigError(kv.key, kv.value, "unknown setting", "one of "+configKeys())
+		}
+		if err := s.set(c, strings.TrimSpace(kv.value)); err != nil {
+			return false, err
+		}
+		workerSet = workerSet || s.key == "worker_id"
+	}
+	return workerSet, nil
+}
+
+// ============================================================================
+// Environment
+// ============================================================================
+
+// ConfigFromEnv builds a Config from environment variables named prefix, an
+// underscore and the upper-case setting:
+//
+//	SNOWFLAKE_WORKER_ID           required
+//	SNOWFLAKE_EPOCH               2024-01-01T00:00:00Z, 2024-01-01 or 1704067200000
+//	SNOWFLAKE_LAYOUT              superior, 41/10/12 or 39/15/9@10ms
+//	SNOWFLAKE_MAX_CLOCK_BACKWARD  5ms
+//	SNOWFLAKE_ENABLE_METRICS      true
+//	SNOWFLAKE_NAME                orders
+//	SNOWFLAKE_MAX_BORROW_AHEAD    10ms
+//	SNOWFLAKE_MAX_WAIT            50ms
+//
+// Unset variables keep their DefaultConfig values. The worker ID has no safe
+// default, so a missing worker ID is an error. The result is validated.
+//
+// Example:
+//
+//	cfg, err := snowflake.ConfigFromEnv("SNOWFLAKE")
+//	if err != nil {
+//	    log.Fatal(err) // e.g. invalid configuration: Layout=41/10/11 (...)
+//	}
+//	gen, err := snowflake.NewWithConfig(cfg)
+func ConfigFromEnv(prefix string) (Config, error) {
+	var values []keyValue
+	for _, s := range configSettings {
+		if v, ok := os.LookupEnv(envName(prefix, s.key)); ok {
+			values = append(values, keyValue{s.key, v})
+		}
+	}
+
+	cfg := DefaultConfig(0)
+	workerSet, err := cfg.apply(values)
+	if err != nil {
+		return Config{}, err
+	}
+	if !workerSet {
+		return Config{}, newConfigError("WorkerID", "", "not set",
+			fmt.Sprintf("set %s to this instance's worker ID", envName(prefix, "worker_id")))
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// envName returns the environment variable for key under prefix.
+func envName(prefix, key string) string {
+	name := strings.ToUpper(key)
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimSuffix(prefix, "_") + "_" + name
+}
+
+// ============================================================================
+// Flags
+// ============================================================================
+
+// RegisterFlags defines a flag for each setting on fs, named prefix followed
+// by the setting with dashes ("-snowflake-worker-id" for prefix "snowflake-").
+// Flags write into c, and their usage shows c's current values as defaults.
+//
+// Call Validate after fs.Parse.
+//
+// Example:
+//
+//	cfg := snowflake.DefaultConfig(0)
+//	cfg.RegisterFlags(flag.CommandLine, "snowflake-")
+//	flag.Parse()
+//	if err := cfg.Validate(); err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Config) RegisterFlags(fs *flag.FlagSet, prefix string) {
+	for _, s := range configSettings {
+		set := s.set
+		name := prefix + strings.ReplaceAll(s.key, "_", "-")
+		usage := fmt.Sprintf("%s (default %q)", s.usage, s.get(c))
+		fs.Func(name, usage, func(v string) error { return set(c, v) })
+	}
+}
+
+// ============================================================================
+// Files and encodings
+// ============================================================================
+
+// MarshalJSON implements json.Marshaler, writing the settings UnmarshalJSON
+// reads: snake_case keys, the epoch as an RFC 3339 time, the layout as its
+// spec and durations as strings such as "5ms". Observer, Logger and Health
+// are omitted.
+//
+// Example:
+//
+//	data, err := json.Marshal(snowflake.DefaultConfig(42))
+//	// {"worker_id":42,"epoch":"2024-01-01T00:00:00Z","layout":"41/10/12@1ms",...}
+func (c Config) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, s := range configSettings {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(s.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		if s.literal {
+			buf.WriteString(s.get(&c))
+			continue
+		}
+		value, err := json.Marshal(s.get(&c))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The object holds settings by key
+// ("worker_id", "epoch", "layout", ...) with string, number or boolean
+// values; durations are strings such as "5ms". Settings not present keep their
+// current values, so decode into DefaultConfig for defaults. Unknown keys are
+// rejected and the result is validated.
+//
+// Example:
+//
+//	cfg := snowflake.DefaultConfig(0)
+//	err := json.Unmarshal([]byte(`{"worker_id": 42, "layout": "superior", "epoch": "2025-01-01"}`), &cfg)
+func (c *Config) UnmarshalJSON(data []byte) error {
+	values, err := jsonConfigValues(data)
+	if err != nil {
+		return err
+	}
+	if _, err := c.apply(values); err != nil {
+		return err
+	}
+	return c.Validate()
+}
+
+// jsonConfigValues reads the settings of a JSON object.
+func jsonConfigValues(data []byte) ([]keyValue, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // Report errors deterministically
+
+	values := make([]keyValue, 0, len(raw))
+	for _, key := range keys {
+		msg := raw[key]
+		var v string
+		switch {
+		case string(msg) == "null":
+			continue
+		case len(msg) > 0 && msg[0] == '"':
+			if err := json.Unmarshal(msg, &v); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, key, err)
+			}
+		case len(msg) > 0 && msg[0] == '{' && normalizeConfigKey(key) == "layout":
+			// A BitLayout stored as a struct
+			var l BitLayout
+			if err := l.UnmarshalJSON(msg); err != nil {
+				return nil, newConfigError("Layout", string(msg), err.Error(), "BitLayout object or layout string")
+			}
+			v = l.String()
+		default:
+			v = string(msg)
+		}
+		values = append(values, keyValue{key, v})
+	}
+	return values, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for key=value settings
+// separated by newlines, commas or spaces, with '#' starting a comment, as in
+// an environment file:
+//
+//	worker_id=42
+//	layout=superior   # 40/14/9@1ms
+//	epoch=2025-01-01
+//
+// Settings not present keep their current values; the result is validated.
+func (c *Config) UnmarshalText(text []byte) error {
+	values, err := textConfigValues(string(text))
+	if err != nil {
+		return err
+	}
+	if _, err := c.apply(values); err != nil {
+		return err
+	}
+	return c.Validate()
+}
+
+// textConfigValues reads key=value settings.
+func textConfigValues(text string) ([]keyValue, error) {
+	var values []keyValue
+	for _, line := range strings.Split(text, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		}) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, newConfigError(field, "", "expected key=value", "one of "+configKeys())
+			}
+			values = append(values, keyValue{key, strings.Trim(value, `"'`)})
+		}
+	}
+	return values, nil
+}
+
+// LoadConfigFile reads a Config from a file: JSON if the name ends in
+// ".json", key=value settings (see Config.UnmarshalText) otherwise.
+//
+// Settings not in the file use DefaultConfig values, except the worker ID,
+// which must be present. The result is validated.
+//
+// Example:
+//
+//	cfg, err := snowflake.LoadConfigFile("/etc/ids/snowflake.json")
+func LoadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var values []keyValue
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		values, err = jsonConfigValues(data)
+	} else {
+		values, err = textConfigValues(string(data))
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg := DefaultConfig(0)
+	workerSet, err := cfg.apply(values)
+	if err != nil {
+		return Config{}, err
+	}
+	if !workerSet {
+		return Config{}, newConfigError("WorkerID", "", "not set", "add worker_id to "+path)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,211 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("SNOWFLAKE_WORKER_ID", "42")
+	t.Setenv("SNOWFLAKE_EPOCH", "2025-01-01T00:00:00Z")
+	t.Setenv("SNOWFLAKE_LAYOUT", "superior")
+	t.Setenv("SNOWFLAKE_MAX_CLOCK_BACKWARD", "20ms")
+	t.Setenv("SNOWFLAKE_ENABLE_METRICS", "true")
+	t.Setenv("SNOWFLAKE_NAME", "orders")
+
+	cfg, err := ConfigFromEnv("SNOWFLAKE")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv() error = %v", err)
+	}
+	want := DefaultConfig(42)
+	want.Epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	want.Layout = LayoutSuperior
+	want.MaxClockBackward = 20 * time.Millisecond
+	want.EnableMetrics = true
+	want.Name = "orders"
+	if cfg != want {
+		t.Errorf("ConfigFromEnv() = %+v, want %+v", cfg, want)
+	}
+
+	if _, err := NewWithConfig(cfg); err != nil {
+		t.Errorf("NewWithConfig(loaded) error = %v", err)
+	}
+}
+
+func TestConfigFromEnv_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		field string
+	}{
+		{"missing worker", map[string]string{}, "WorkerID"},
+		{"bad worker", map[string]string{"APP_WORKER_ID": "x"}, "WorkerID"},
+		{"worker out of range", map[string]string{"APP_WORKER_ID": "5000"}, "WorkerID"},
+		{"bad epoch", map[string]string{"APP_WORKER_ID": "1", "APP_EPOCH": "yesterday"}, "Epoch"},
+		{"bad layout", map[string]string{"APP_WORKER_ID": "1", "APP_LAYOUT": "41/10/11"}, "Layout"},
+		{"bad duration", map[string]string{"APP_WORKER_ID": "1", "APP_MAX_WAIT": "5"}, "MaxWait"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := ConfigFromEnv("APP_")
+			var ce *ConfigError
+			if !errors.As(err, &ce) {
+				t.Fatalf("ConfigFromEnv() error = %v, want ConfigError", err)
+			}
+			if ce.Field != tt.field {
+				t.Errorf("ConfigError.Field = %q, want %q", ce.Field, tt.field)
+			}
+		})
+	}
+}
+
+func TestConfig_UnmarshalJSON(t *testing.T) {
+	cfg := DefaultConfig(0)
+	data := `{"worker_id": 7, "epoch": 1735689600000, "layout": "40/14/9@1ms",
+		"MaxBorrowAhead": "10ms", "enable_metrics": true, "name": null}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if cfg.WorkerID != 7 || cfg.Epoch != 1735689600000 || cfg.Layout != LayoutSuperior ||
+		cfg.MaxBorrowAhead != 10*time.Millisecond || !cfg.EnableMetrics {
+		t.Errorf("Unmarshal() = %+v", cfg)
+	}
+	if cfg.MaxClockBackward != DefaultConfig(0).MaxClockBackward {
+		t.Errorf("MaxClockBackward = %v, want default kept", cfg.MaxClockBackward)
+	}
+
+	// Struct-form layout
+	cfg = DefaultConfig(0)
+	data = `{"worker_id": 1, "layout": {"TimestampBits": 40, "WorkerBits": 14, "SequenceBits": 9, "TimeUnit": 1000000}}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil || cfg.Layout != LayoutSuperior {
+		t.Errorf("Unmarshal(struct layout) = %+v, %v", cfg.Layout, err)
+	}
+
+	for _, bad := range []string{`{"worker": 1}`, `{"worker_id": "one"}`, `{"worker_id": 99999}`, `[1]`} {
+		cfg := DefaultConfig(0)
+		if err := json.Unmarshal([]byte(bad), &cfg); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestConfig_MarshalJSON_RoundTrip(t *testing.T) {
+	want := DefaultConfig(7)
+	want.Epoch = 1735689600123
+	want.Layout = LayoutSuperior
+	want.MaxClockBackward = 20 * time.Millisecond
+	want.EnableMetrics = false
+	want.Name = "orders"
+	want.MaxBorrowAhead = 10 * time.Millisecond
+	want.MaxWait = 50 * time.Millisecond
+	want.Observer = NopObserver{}
+
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	got := DefaultConfig(0)
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal(%s) error = %v", data, err)
+	}
+	want.Observer = nil
+	if got.WorkerID != want.WorkerID || got.Epoch != want.Epoch || got.Layout != want.Layout ||
+		got.MaxClockBackward != want.MaxClockBackward || got.EnableMetrics != want.EnableMetrics ||
+		got.Name != want.Name || got.MaxBorrowAhead != want.MaxBorrowAhead || got.MaxWait != want.MaxWait {
+		t.Errorf("round trip of %s = %+v, want %+v", data, got, want)
+	}
+
+	// Defaults round-trip too, including a zero layout and through a pointer
+	for _, cfg := range []Config{DefaultConfig(3), {WorkerID: 3, Epoch: Epoch}} {
+		data, err := json.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		var got Config
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Errorf("Unmarshal(%s) error = %v", data, err)
+		}
+	}
+}
+
+func TestConfig_UnmarshalText(t *testing.T) {
+	cfg := DefaultConfig(0)
+	text := "# ids\nworker_id=12\nLAYOUT=ultra, epoch=2024-06-01 # date\nmax-wait=50ms\n"
+	if err := cfg.UnmarshalText([]byte(text)); err != nil {
+		t.Fatalf("UnmarshalText() error = %v", err)
+	}
+	if cfg.WorkerID != 12 || cfg.Layout != LayoutUltra || cfg.MaxWait != 50*time.Millisecond ||
+		cfg.Epoch != time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC).UnixMilli() {
+		t.Errorf("UnmarshalText() = %+v", cfg)
+	}
+
+	if err := cfg.UnmarshalText([]byte("worker_id")); !IsConfigError(err) {
+		t.Errorf("UnmarshalText(no '=') error = %v, want ConfigError", err)
+	}
+}
+
+func TestConfig_RegisterFlags(t *testing.T) {
+	cfg := DefaultConfig(0)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs, "snowflake-")
+
+	err := fs.Parse([]string{"-snowflake-worker-id", "3", "-snowflake-layout", "39/15/9@10ms", "-snowflake-enable-metrics=true"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	want := BitLayout{TimestampBits: 39, WorkerBits: 15, SequenceBits: 9, TimeUnit: 10 * time.Millisecond}
+	if cfg.WorkerID != 3 || cfg.Layout != want || !cfg.EnableMetrics {
+		t.Errorf("after Parse: %+v", cfg)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(discard{})
+	cfg.RegisterFlags(fs, "")
+	if err := fs.Parse([]string{"-epoch", "tomorrow"}); err == nil {
+		t.Error("Parse(-epoch tomorrow) succeeded, want error")
+	}
+}
+
+// discard is an io.Writer that drops flag usage output.
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadConfigFile(write("ids.json", `{"worker_id": 5, "layout": "megascale"}`))
+	if err != nil || cfg.WorkerID != 5 || cfg.Layout != LayoutMegaScale {
+		t.Errorf("LoadConfigFile(json) = %+v, %v", cfg, err)
+	}
+
+	cfg, err = LoadConfigFile(write("ids.env", "worker_id=6\nname=users\n"))
+	if err != nil || cfg.WorkerID != 6 || cfg.Name != "users" || cfg.Layout != LayoutDefault {
+		t.Errorf("LoadConfigFile(env) = %+v, %v", cfg, err)
+	}
+
+	if _, err := LoadConfigFile(write("empty.json", `{}`)); !IsConfigError(err) {
+		t.Errorf("LoadConfigFile(no worker) error = %v, want ConfigError", err)
+	}
+	if _, err := LoadConfigFile(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadConfigFile(missing) error = %v, want ErrNotExist", err)
+	}
+}
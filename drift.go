@@ -0,0 +1,292 @@
+// Package snowflake - drift.go detects wall clock steps the generator does not follow.
+//
+// A generator's clock is the wall clock at creation plus monotonic time since,
+// so NTP steps and manual changes never make it go backward. The price is that
+// after a step the generator keeps running on the old time: ID.Time() drifts
+// away from the real time by the size of the step for the life of the process.
+// A DriftMonitor measures that offset and can re-anchor the generator's clock
+// to the wall clock when that is safe.
+
+package snowflake
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDriftInterval is the default period between DriftMonitor checks.
+	DefaultDriftInterval = 10 * time.Second
+
+	// DefaultDriftThreshold is the default offset a DriftMonitor reports or
+	// re-anchors. NTP slews both clocks alike, so offsets only appear after
+	// steps, which are usually far larger.
+	DefaultDriftThreshold = 10 * time.Millisecond
+
+	// DefaultMaxBackwardWait is the default largest backward step DriftReanchor
+	// re-anchors.
+	DefaultMaxBackwardWait = time.Second
+)
+
+// DriftPolicy selects what a DriftMonitor does about an offset.
+type DriftPolicy int
+
+const (
+	// DriftReportOnly records the offset in metrics and events but never
+	// changes the generator's clock.
+	DriftReportOnly DriftPolicy = iota
+
+	// DriftReanchorForward also re-anchors when the wall clock is ahead of the
+	// generator. Timestamps jump forward, which never risks duplicates.
+	DriftReanchorForward
+
+	// DriftReanchor also re-anchors when the wall clock is behind the
+	// generator by at most DriftConfig.MaxBackwardWait. Generation is paused
+	// until the re-anchored clock passes the last timestamp used, so a
+	// backward step of d blocks callers for about d.
+	DriftReanchor
+)
+
+// String returns a human-readable name for the policy.
+func (p DriftPolicy) String() string {
+	switch p {
+	case DriftReportOnly:
+		return "report-only"
+	case DriftReanchorForward:
+		return "reanchor-forward"
+	case DriftReanchor:
+		return "reanchor"
+	default:
+		return fmt.Sprintf("DriftPolicy(%d)", int(p))
+	}
+}
+
+// DriftConfig configures a DriftMonitor. Zero fields use the defaults.
+type DriftConfig struct {
+	// Interval is the period between checks.
+	// Default: DefaultDriftInterval (10s)
+	Interval time.Duration
+
+	// Threshold is the smallest offset that is reported as an event or
+	// re-anchored. Smaller offsets are still recorded in Metrics.ClockOffsetUs.
+	// Default: DefaultDriftThreshold (10ms)
+	Threshold time.Duration
+
+	// Policy selects whether offsets are re-anchored.
+	// Default: DriftReportOnly
+	Policy DriftPolicy
+
+	// MaxBackwardWait is the largest backward offset DriftReanchor re-anchors,
+	// and so the longest it pauses generation. Larger backward offsets are
+	// only reported.
+	// Default: DefaultMaxBackwardWait (1s)
+	MaxBackwardWait time.Duration
+}
+
+// withDefaults fills in zero-valued fields.
+func (c DriftConfig) withDefaults() DriftConfig {
+	if c.Interval == 0 {
+		c.Interval = DefaultDriftInterval
+	}
+	if c.Threshold == 0 {
+		c.Threshold = DefaultDriftThreshold
+	}
+	if c.MaxBackwardWait == 0 {
+		c.MaxBackwardWait = DefaultMaxBackwardWait
+	}
+	return c
+}
+
+// Validate checks that durations are non-negative and the policy is known.
+func (c DriftConfig) Validate() error {
+	c = c.withDefaults()
+	if c.Interval < 0 {
+		return newConfigError("Interval", c.Interval.String(), "must be positive", "duration > 0")
+	}
+	if c.Threshold < 0 {
+		return newConfigError("Threshold", c.Threshold.String(), "must be positive", "duration > 0")
+	}
+	if c.MaxBackwardWait < 0 {
+		return newConfigError("MaxBackwardWait", c.MaxBackwardWait.String(), "must be positive", "duration > 0")
+	}
+	if c.Policy < DriftReportOnly || c.Policy > DriftReanchor {
+		return newConfigError("Policy", c.Policy.String(), "unknown policy",
+			"DriftReportOnly, DriftReanchorForward or DriftReanchor")
+	}
+	return nil
+}
+
+// DriftReading is the result of one DriftMonitor check.
+type DriftReading struct {
+	// Offset is the wall clock minus the generator's clock, measured before
+	// any re-anchoring. Positive means the generator is behind real time.
+	Offset time.Duration
+
+	// Reanchored reports whether the generator's clock was moved by Offset.
+	Reanchored bool
+
+	// CheckedAt is the wall clock time of the check.
+	CheckedAt time.Time
+}
+
+// DriftMonitor periodically compares a generator's clock with the wall clock.
+//
+// Each check stores the offset in Metrics.ClockOffsetUs, reports offsets of
+// at least DriftConfig.Threshold to the generator's Observer if it implements
+// DriftObserver, and re-anchors according to DriftConfig.Policy; re-anchors
+// are counted in Metrics.Reanchors.
+//
+// Example:
+//
+//	mon, err := snowflake.NewDriftMonitor(gen, snowflake.DriftConfig{
+//	    Policy: snowflake.DriftReanchorForward,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer mon.Close()
+type DriftMonitor struct {
+	gen *Generator
+	cfg DriftConfig
+
+	mu       sync.Mutex    // Serialises checks
+	reported time.Duration // Offset last reported to the observer
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewDriftMonitor starts a DriftMonitor for gen. Call Close to stop it.
+func NewDriftMonitor(gen *Generator, cfg DriftConfig) (*DriftMonitor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg = cfg.withDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &DriftMonitor{
+		gen:    gen,
+		cfg:    cfg,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go m.loop(ctx)
+	return m, nil
+}
+
+// loop runs a check every Interval until ctx is canceled.
+func (m *DriftMonitor) loop(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	m.Check()
+	for {
+		select {
+		case <-ticker.C:
+			m.Check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check measures the offset now and applies the policy, without waiting for
+// the next interval.
+//
+// With DriftReanchor, a backward re-anchor blocks until generation can resume.
+func (m *DriftMonitor) Check() DriftReading {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g := m.gen
+	offset := g.clockOffset()
+	reading := DriftReading{Offset: offset, CheckedAt: time.Now()}
+
+	if abs(offset) >= m.cfg.Threshold {
+		switch {
+		case offset > 0 && m.cfg.Policy >= DriftReanchorForward,
+			offset < 0 && m.cfg.Policy == DriftReanchor && -offset <= m.cfg.MaxBackwardWait:
+			g.reanchor(offset)
+			reading.Reanchored = true
+		}
+	}
+
+	if reading.Reanchored {
+		g.clockOffsetUs.Store(g.clockOffset().Microseconds())
+		m.reported = 0
+		g.notifyClockDrift(offset, true)
+	} else {
+		g.clockOffsetUs.Store(offset.Microseconds())
+		if abs(offset-m.reported) >= m.cfg.Threshold {
+			m.reported = offset
+			g.notifyClockDrift(offset, false)
+		}
+	}
+	return reading
+}
+
+// Close stops the monitor and waits for a running check to finish. It is
+// safe to call more than once.
+func (m *DriftMonitor) Close() {
+	m.closeOnce.Do(func() {
+		m.cancel()
+		<-m.done
+	})
+}
+
+// abs returns the absolute value of d.
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// ============================================================================
+// Generator plumbing
+// ============================================================================
+
+// clockTime returns the generator's notion of the current time: the wall
+// clock at creation, plus monotonic time since, plus any re-anchoring.
+func (g *Generator) clockTime() time.Time {
+	return g.epoch.Add(time.Since(g.epoch) + time.Duration(g.anchorShift.Load()))
+}
+
+// clockOffset returns the wall clock minus the generator's clock.
+func (g *Generator) clockOffset() time.Duration {
+	now := time.Now()
+	clock := g.epoch.Add(now.Sub(g.epoch) + time.Duration(g.anchorShift.Load()))
+	return now.Round(0).Sub(clock.Round(0))
+}
+
+// reanchor moves the generator's clock by offset.
+//
+// Moving it backward could repeat timestamps, so for a negative offset the
+// lock is held until the moved clock reaches the highest reading seen.
+func (g *Generator) reanchor(offset time.Duration) {
+	g.mu.Lock()
+	defer g.unlockAndNotify()
+
+	g.anchorShift.Add(int64(offset))
+	if offset < 0 {
+		time.Sleep(g.untilTimeUnit(g.lastClock))
+		for g.currentTimestamp() < g.lastClock {
+			runtime.Gosched()
+		}
+	}
+	g.reanchors.Add(1)
+}
+
+// notifyClockDrift delivers a drift event if the observer implements
+// DriftObserver. The caller must not hold g.mu.
+func (g *Generator) notifyClockDrift(offset time.Duration, reanchored bool) {
+	if o, ok := g.observer.(DriftObserver); ok {
+		o.OnClockDrift(g.workerID, offset, reanchored)
+	}
+}
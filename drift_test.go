@@ -0,0 +1,147 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+// newCheckedDriftMonitor returns a monitor without its background loop, so
+// tests control when checks run.
+func newCheckedDriftMonitor(gen *Generator, cfg DriftConfig) *DriftMonitor {
+	return &DriftMonitor{gen: gen, cfg: cfg.withDefaults()}
+}
+
+// within reports whether d is within tolerance of want.
+func within(d, want, tolerance time.Duration) bool {
+	return abs(d-want) <= tolerance
+}
+
+func TestDriftMonitor_ReportOnly(t *testing.T) {
+	events := NewChannelObserver(8)
+	cfg := DefaultConfig(1)
+	cfg.Observer = events
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	// Simulate the wall clock stepping forward by a second
+	gen.anchorShift.Store(int64(-time.Second))
+
+	mon := newCheckedDriftMonitor(gen, DriftConfig{})
+	r := mon.Check()
+	if !within(r.Offset, time.Second, 10*time.Millisecond) || r.Reanchored {
+		t.Fatalf("Check() = %+v, want ~1s offset without re-anchoring", r)
+	}
+	if us := gen.GetMetrics().ClockOffsetUs; !within(time.Duration(us)*time.Microsecond, time.Second, 10*time.Millisecond) {
+		t.Errorf("ClockOffsetUs = %d, want ~1e6", us)
+	}
+
+	// An unchanged offset is reported once
+	mon.Check()
+	if n := len(events.Events()); n != 1 {
+		t.Fatalf("%d events, want 1", n)
+	}
+	ev := <-events.Events()
+	if ev.Kind != EventClockDrift || ev.Reanchored || !within(ev.Drift, time.Second, 10*time.Millisecond) {
+		t.Errorf("event = %+v, want clock drift of ~1s", ev)
+	}
+}
+
+func TestDriftMonitor_ReanchorForward(t *testing.T) {
+	gen, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	gen.anchorShift.Store(int64(-time.Second))
+	before := gen.MustGenerateID()
+
+	mon := newCheckedDriftMonitor(gen, DriftConfig{Policy: DriftReanchorForward})
+	if r := mon.Check(); !r.Reanchored {
+		t.Fatalf("Check() = %+v, want re-anchored", r)
+	}
+	if off := gen.clockOffset(); !within(off, 0, 5*time.Millisecond) {
+		t.Errorf("offset after re-anchor = %v, want ~0", off)
+	}
+	m := gen.GetMetrics()
+	if m.Reanchors != 1 || !within(time.Duration(m.ClockOffsetUs)*time.Microsecond, 0, 5*time.Millisecond) {
+		t.Errorf("Reanchors = %d, ClockOffsetUs = %d; want 1, ~0", m.Reanchors, m.ClockOffsetUs)
+	}
+
+	after := gen.MustGenerateID()
+	if after <= before {
+		t.Errorf("ID after re-anchor %d <= before %d", after, before)
+	}
+	if d := time.Since(after.Time()); !within(d, 0, 10*time.Millisecond) {
+		t.Errorf("ID time is %v from now after re-anchor, want ~0", d)
+	}
+
+	// Backward offsets are not re-anchored by this policy
+	gen.anchorShift.Add(int64(100 * time.Millisecond))
+	if r := mon.Check(); r.Reanchored {
+		t.Errorf("Check() = %+v, backward offset re-anchored under DriftReanchorForward", r)
+	}
+}
+
+func TestDriftMonitor_ReanchorBackward(t *testing.T) {
+	gen, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Generator 200ms ahead of the wall clock, with an ID already issued there
+	gen.anchorShift.Store(int64(200 * time.Millisecond))
+	before := gen.MustGenerateID()
+
+	mon := newCheckedDriftMonitor(gen, DriftConfig{Policy: DriftReanchor, MaxBackwardWait: 500 * time.Millisecond})
+	start := time.Now()
+	r := mon.Check()
+	if !r.Reanchored || !within(r.Offset, -200*time.Millisecond, 10*time.Millisecond) {
+		t.Fatalf("Check() = %+v, want ~-200ms re-anchored", r)
+	}
+	if waited := time.Since(start); waited < 150*time.Millisecond {
+		t.Errorf("Check() returned after %v, want it to wait out the step", waited)
+	}
+
+	after := gen.MustGenerateID()
+	if after <= before {
+		t.Errorf("ID after backward re-anchor %d <= before %d", after, before)
+	}
+	if m := gen.GetMetrics(); m.ClockBackwardErr != 0 {
+		t.Errorf("ClockBackwardErr = %d after re-anchor, want 0", m.ClockBackwardErr)
+	}
+
+	// Steps beyond MaxBackwardWait are only reported
+	gen.anchorShift.Add(int64(time.Second))
+	if r := mon.Check(); r.Reanchored {
+		t.Errorf("Check() = %+v, want a 1s backward step left alone", r)
+	}
+}
+
+func TestNewDriftMonitor(t *testing.T) {
+	gen, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := NewDriftMonitor(gen, DriftConfig{Policy: DriftPolicy(9)}); !IsConfigError(err) {
+		t.Errorf("NewDriftMonitor(bad policy) error = %v, want ConfigError", err)
+	}
+	if _, err := NewDriftMonitor(gen, DriftConfig{Interval: -time.Second}); !IsConfigError(err) {
+		t.Errorf("NewDriftMonitor(negative interval) error = %v, want ConfigError", err)
+	}
+
+	gen.anchorShift.Store(int64(-time.Second))
+	mon, err := NewDriftMonitor(gen, DriftConfig{Interval: 5 * time.Millisecond, Policy: DriftReanchorForward})
+	if err != nil {
+		t.Fatalf("NewDriftMonitor() error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for gen.GetMetrics().Reanchors == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	mon.Close()
+	mon.Close()
+	if gen.GetMetrics().Reanchors != 1 {
+		t.Errorf("Reanchors = %d, want 1 from the background check", gen.GetMetrics().Reanchors)
+	}
+}
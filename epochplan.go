@@ -0,0 +1,285 @@
+// Package snowflake - epochplan.go plans a switch to a new epoch or layout.
+//
+// When a scheme's timestamp field nears its end, generators must move to a
+// new epoch, a new layout or both. IDs are compared as integers, so to keep
+// ordering the first ID of the new scheme must exceed every ID the old one
+// produced. PlanEpoch finds, for each candidate layout, the latest epoch
+// that achieves this at a planned cutover time and reports how long the
+// result lasts.
+//
+// The new scheme can only use the ID space above the old scheme's last ID.
+// Moving to a layout with more timestamp bits or a coarser time unit spends
+// that space more slowly, at the cost of workers or per-worker throughput.
+
+package snowflake
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultEpochPlanMargin is the clock allowance PlanEpoch uses when
+// EpochPlanOptions.Margin is zero.
+const DefaultEpochPlanMargin = time.Minute
+
+// EpochPlanOptions configures PlanEpoch. Zero fields use the defaults noted.
+type EpochPlanOptions struct {
+	// Cutover is when the old generators stop and the new ones start.
+	// Default: now
+	Cutover time.Time
+
+	// Margin is the clock allowance on both sides of Cutover: old generators
+	// may produce IDs for up to Margin after it, through clock skew or
+	// Config.MaxBorrowAhead, and new generators may start up to Margin before
+	// it. Set it to at least the larger of your fleet's clock skew and
+	// MaxBorrowAhead.
+	// Default: DefaultEpochPlanMargin (1 minute)
+	Margin time.Duration
+
+	// MinWorkerBits excludes layouts with fewer worker bits, so the worker
+	// IDs already deployed remain valid.
+	// Default: the current layout's WorkerBits
+	MinWorkerBits int
+
+	// MinIDsPerSecPerWorker excludes layouts whose per-worker throughput is
+	// lower. Zero means no requirement.
+	MinIDsPerSecPerWorker int64
+
+	// Layouts are the candidate layouts. Invalid layouts are skipped.
+	// Default: every layout accepted by BitLayout.Validate at time units from
+	// 1ms to 10ms, the registered layouts and the current layout
+	Layouts []BitLayout
+}
+
+// EpochCandidate is one layout evaluated by PlanEpoch.
+type EpochCandidate struct {
+	// Scheme is the candidate layout with the latest epoch that keeps its IDs
+	// above EpochPlan.LastID. Its Epoch is zero when Feasible is false.
+	Scheme Scheme
+
+	// Name is the registered name of the layout, if it has one.
+	Name string
+
+	Capacity LayoutCapacity
+
+	// FirstID is the smallest ID the new scheme can generate from
+	// Cutover - Margin onwards. It is always greater than EpochPlan.LastID.
+	FirstID ID
+
+	// Remaining is the lifespan left at Cutover, saturating at the largest
+	// Duration (about 292 years), and OverflowDate is when the timestamp
+	// field runs out.
+	Remaining    time.Duration
+	OverflowDate time.Time
+
+	// Feasible reports whether the layout can preserve ordering; if not,
+	// Problem explains why.
+	Feasible bool
+	Problem  string
+}
+
+// EpochPlan is the result of PlanEpoch.
+type EpochPlan struct {
+	// Current is the scheme being replaced.
+	Current Scheme
+
+	// Cutover and Margin are the options the plan was computed with.
+	Cutover time.Time
+	Margin  time.Duration
+
+	// LastID is the largest ID the current scheme can have generated by
+	// Cutover + Margin.
+	LastID ID
+
+	// CurrentRemaining and CurrentOverflowDate describe the current scheme's
+	// lifespan at Cutover, for comparison with the candidates.
+	CurrentRemaining    time.Duration
+	CurrentOverflowDate time.Time
+
+	// Candidates holds every layout considered: feasible ones first by
+	// decreasing lifespan (latest OverflowDate first), then the infeasible ones.
+	Candidates []EpochCandidate
+}
+
+// Best returns the feasible candidate with the longest remaining lifespan,
+// or false if no candidate can preserve ordering.
+func (p EpochPlan) Best() (EpochCandidate, bool) {
+	if len(p.Candidates) == 0 || !p.Candidates[0].Feasible {
+		return EpochCandidate{}, false
+	}
+	return p.Candidates[0], true
+}
+
+// PlanEpoch computes candidate epochs and layouts to replace current at
+// opts.Cutover such that every new ID is strictly greater than every existing
+// one, and ranks them by remaining lifespan.
+//
+// For each layout, the new epoch is the latest one whose first timestamp at
+// Cutover - Margin lies above the current scheme's last ID at Cutover + Margin.
+// A layout is infeasible when that would need an epoch before 1970, or when
+// no timestamp values are left above the last ID.
+//
+// All old generators must stop before Cutover + Margin and new ones must not
+// start before Cutover - Margin.
+//
+// Returns an error if current is invalid, or a *ConfigError if an option is
+// negative.
+//
+// Example:
+//
+//	if info := gen.LifespanInfo(); info.IsApproaching {
+//	    plan, err := snowflake.PlanEpoch(gen.Scheme(), snowflake.EpochPlanOptions{
+//	        Cutover: maintenanceWindow,
+//	    })
+//	    if err != nil {
+//	        return err
+//	    }
+//	    if best, ok := plan.Best(); ok {
+//	        log.Info("Next scheme", "layout", best.Scheme.Layout,
+//	            "epoch", best.Scheme.Epoch, "remaining", best.Remaining)
+//	    }
+//	}
+func PlanEpoch(current Scheme, opts EpochPlanOptions) (EpochPlan, error) {
+	if err := current.Validate(); err != nil {
+		return EpochPlan{}, err
+	}
+	current = current.normalized()
+
+	if opts.Margin < 0 {
+		return EpochPlan{}, newConfigError("Margin", opts.Margin.String(), "must not be negative", "duration >= 0")
+	}
+	if opts.MinWorkerBits < 0 {
+		return EpochPlan{}, newConfigError("MinWorkerBits", fmt.Sprint(opts.MinWorkerBits), "must not be negative", "bits >= 0")
+	}
+	if opts.MinIDsPerSecPerWorker < 0 {
+		return EpochPlan{}, newConfigError("MinIDsPerSecPerWorker", fmt.Sprint(opts.MinIDsPerSecPerWorker),
+			"must not be negative", "rate >= 0")
+	}
+	if opts.Cutover.IsZero() {
+		opts.Cutover = time.Now()
+	}
+	if opts.Margin == 0 {
+		opts.Margin = DefaultEpochPlanMargin
+	}
+	if opts.MinWorkerBits == 0 {
+		opts.MinWorkerBits = current.Layout.WorkerBits
+	}
+	if opts.Layouts == nil {
+		opts.Layouts = epochPlanLayouts(current.Layout)
+	}
+
+	plan := EpochPlan{
+		Current: current,
+		Cutover: opts.Cutover,
+		Margin:  opts.Margin,
+		LastID:  current.MaxIDForTime(opts.Cutover.Add(opts.Margin)),
+	}
+	elapsed := current.clampTimeUnits(current.timeUnits(opts.Cutover))
+	plan.CurrentRemaining = unitsDuration(current.maxTimeUnits()-elapsed, current.Layout.TimeUnit)
+	plan.CurrentOverflowDate = unitTime(current.epochUnits()+current.maxTimeUnits(), current.Layout.TimeUnit)
+
+	names := make(map[BitLayout]string)
+	for _, name := range LayoutNames() {
+		l, _ := LookupLayout(name)
+		if _, ok := names[l]; !ok {
+			names[l] = name
+		}
+	}
+
+	seen := make(map[BitLayout]bool)
+	for _, l := range opts.Layouts {
+		if seen[l] || l.Validate() != nil {
+			continue
+		}
+		seen[l] = true
+		capacity := l.CalculateCapacity()
+		if l.WorkerBits < opts.MinWorkerBits || capacity.ThroughputPerWorker < opts.MinIDsPerSecPerWorker {
+			continue
+		}
+		c := planEpochCandidate(l, plan)
+		c.Name = names[l]
+		c.Capacity = capacity
+		plan.Candidates = append(plan.Candidates, c)
+	}
+
+	sort.SliceStable(plan.Candidates, func(i, j int) bool {
+		a, b := plan.Candidates[i], plan.Candidates[j]
+		if a.Feasible != b.Feasible {
+			return a.Feasible
+		}
+		// Remaining saturates for long-lived layouts; the overflow date doesn't
+		if !a.OverflowDate.Equal(b.OverflowDate) {
+			return a.OverflowDate.After(b.OverflowDate)
+		}
+		if (a.Name != "") != (b.Name != "") {
+			return a.Name != ""
+		}
+		if a.Capacity.ThroughputPerWorker != b.Capacity.ThroughputPerWorker {
+			return a.Capacity.ThroughputPerWorker > b.Capacity.ThroughputPerWorker
+		}
+		return a.Scheme.Layout.TimeUnit < b.Scheme.Layout.TimeUnit
+	})
+
+	return plan, nil
+}
+
+// PlanEpoch plans a switch away from the generator's scheme.
+// See the package-level PlanEpoch.
+func (g *Generator) PlanEpoch(opts EpochPlanOptions) (EpochPlan, error) {
+	return PlanEpoch(g.Scheme(), opts)
+}
+
+// planEpochCandidate finds the latest epoch for l that keeps its IDs above
+// plan.LastID from Cutover - Margin onwards.
+func planEpochCandidate(l BitLayout, plan EpochPlan) EpochCandidate {
+	c := EpochCandidate{Scheme: Scheme{Layout: l}}
+	timestampShift, _, _, _ := l.CalculateShifts()
+	maxUnits := (int64(1) << l.TimestampBits) - 1
+
+	// The smallest timestamp field value whose IDs all exceed LastID
+	first := int64(plan.LastID)>>timestampShift + 1
+	if first > maxUnits {
+		c.Problem = fmt.Sprintf("no %d-bit timestamps are left above the last ID", l.TimestampBits)
+		return c
+	}
+
+	// Place the epoch so that the earliest new generator starts at first.
+	// Rounding the epoch down to a millisecond only moves timestamps up.
+	start := plan.Cutover.Add(-plan.Margin)
+	epoch := unitTime(unitsAt(start, l.TimeUnit)-first, l.TimeUnit)
+	if epoch.UnixMilli() <= 0 {
+		c.Problem = fmt.Sprintf("needs an epoch before 1970 (%s)", epoch.UTC().Format(time.DateOnly))
+		return c
+	}
+	c.Scheme.Epoch = epoch.UnixMilli()
+
+	elapsed := c.Scheme.timeUnits(plan.Cutover)
+	if elapsed > maxUnits {
+		c.Problem = "overflows before the cutover"
+		return c
+	}
+	c.FirstID = c.Scheme.MinIDForTime(start)
+	c.Remaining = unitsDuration(maxUnits-elapsed, l.TimeUnit)
+	c.OverflowDate = unitTime(c.Scheme.epochUnits()+maxUnits, l.TimeUnit)
+	c.Feasible = true
+	return c
+}
+
+// epochPlanLayouts returns the default PlanEpoch candidates: the layouts
+// RecommendLayout searches, the registered layouts and current.
+func epochPlanLayouts(current BitLayout) []BitLayout {
+	layouts := []BitLayout{current}
+	for _, name := range LayoutNames() {
+		l, _ := LookupLayout(name)
+		layouts = append(layouts, l)
+	}
+	for _, unit := range recommendTimeUnits {
+		for ts := 38; ts <= 42; ts++ {
+			for wb := 8; wb <= 18; wb++ {
+				layouts = append(layouts, BitLayout{TimestampBits: ts, WorkerBits: wb, SequenceBits: 63 - ts - wb, TimeUnit: unit})
+			}
+		}
+	}
+	return layouts
+}
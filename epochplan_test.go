@@ -0,0 +1,196 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPlanEpoch_PreservesOrdering(t *testing.T) {
+	current := Scheme{Layout: LayoutDefault, Epoch: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()}
+	cutover := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	plan, err := PlanEpoch(current, EpochPlanOptions{Cutover: cutover})
+	if err != nil {
+		t.Fatalf("PlanEpoch() error = %v", err)
+	}
+
+	if plan.Margin != DefaultEpochPlanMargin {
+		t.Errorf("Margin = %v, want %v", plan.Margin, DefaultEpochPlanMargin)
+	}
+	if want := current.MaxIDForTime(cutover.Add(DefaultEpochPlanMargin)); plan.LastID != want {
+		t.Errorf("LastID = %d, want %d", plan.LastID, want)
+	}
+
+	best, ok := plan.Best()
+	if !ok {
+		t.Fatal("Best() found no feasible candidate")
+	}
+	if best.Remaining <= plan.CurrentRemaining {
+		t.Errorf("Best().Remaining = %v, want more than the current %v", best.Remaining, plan.CurrentRemaining)
+	}
+
+	seenInfeasible := false
+	for i, c := range plan.Candidates {
+		if c.Scheme.Layout.WorkerBits < current.Layout.WorkerBits {
+			t.Errorf("Candidates[%d] %v has fewer worker bits than the current layout", i, c.Scheme.Layout)
+		}
+		if !c.Feasible {
+			seenInfeasible = true
+			if c.Problem == "" {
+				t.Errorf("Candidates[%d] %v is infeasible without a Problem", i, c.Scheme.Layout)
+			}
+			continue
+		}
+		if seenInfeasible {
+			t.Fatalf("Candidates[%d] %v is feasible but follows an infeasible candidate", i, c.Scheme.Layout)
+		}
+		if i > 0 && c.OverflowDate.After(plan.Candidates[i-1].OverflowDate) {
+			t.Errorf("Candidates[%d] OverflowDate %v is after the previous candidate's", i, c.OverflowDate)
+		}
+		if err := c.Scheme.Validate(); err != nil {
+			t.Errorf("Candidates[%d].Scheme.Validate() = %v", i, err)
+		}
+
+		// Every new ID, even from a generator starting Margin early, exceeds
+		// every old ID, even from a generator running Margin late.
+		if first := c.Scheme.MinIDForTime(cutover.Add(-plan.Margin)); first != c.FirstID || first <= plan.LastID {
+			t.Errorf("Candidates[%d] %v: first ID %d, FirstID %d, LastID %d", i, c.Scheme.Layout, first, c.FirstID, plan.LastID)
+		}
+		if got := c.OverflowDate.Sub(cutover); got != c.Remaining && c.Remaining < time.Duration(1<<63-1) {
+			t.Errorf("Candidates[%d] %v: OverflowDate - Cutover = %v, Remaining = %v", i, c.Scheme.Layout, got, c.Remaining)
+		}
+	}
+}
+
+func TestPlanEpoch_RanksBeyondDurationRange(t *testing.T) {
+	// Both outlive time.Duration, so Remaining saturates for both; the
+	// 42-bit layout still lasts four times as long
+	short := BitLayout{TimestampBits: 40, WorkerBits: 16, SequenceBits: 7, TimeUnit: 10 * time.Millisecond}
+	long := BitLayout{TimestampBits: 42, WorkerBits: 10, SequenceBits: 11, TimeUnit: 10 * time.Millisecond}
+	plan, err := PlanEpoch(DefaultScheme, EpochPlanOptions{
+		Cutover: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Layouts: []BitLayout{short, long},
+	})
+	if err != nil {
+		t.Fatalf("PlanEpoch() error = %v", err)
+	}
+
+	best, ok := plan.Best()
+	if !ok || best.Scheme.Layout != long {
+		t.Errorf("Best() = %v, want %v", best.Scheme.Layout, long)
+	}
+	if len(plan.Candidates) != 2 || !plan.Candidates[0].OverflowDate.After(plan.Candidates[1].OverflowDate) {
+		t.Errorf("Candidates = %+v, want the later overflow first", plan.Candidates)
+	}
+}
+
+func TestPlanEpoch_GeneratedIDsExceedLastID(t *testing.T) {
+	current := Scheme{Layout: LayoutDefault, Epoch: time.Now().AddDate(-40, 0, 0).UnixMilli()}
+	plan, err := PlanEpoch(current, EpochPlanOptions{})
+	if err != nil {
+		t.Fatalf("PlanEpoch() error = %v", err)
+	}
+
+	for _, c := range plan.Candidates[:min(len(plan.Candidates), 5)] {
+		if !c.Feasible {
+			continue
+		}
+		cfg := DefaultConfig(1)
+		cfg.Layout = c.Scheme.Layout
+		cfg.Epoch = c.Scheme.Epoch
+		gen, err := NewWithConfig(cfg)
+		if err != nil {
+			t.Fatalf("NewWithConfig(%v) error = %v", c.Scheme, err)
+		}
+		id, err := gen.GenerateID()
+		if err != nil {
+			t.Fatalf("GenerateID() with %v error = %v", c.Scheme, err)
+		}
+		if id <= plan.LastID {
+			t.Errorf("%v generated %d, want > LastID %d", c.Scheme, id, plan.LastID)
+		}
+	}
+}
+
+func TestPlanEpoch_Exhausted(t *testing.T) {
+	// The current scheme has already overflowed, so no ID space is left
+	current := Scheme{Layout: LayoutDefault, Epoch: 1}
+	cutover := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	plan, err := PlanEpoch(current, EpochPlanOptions{Cutover: cutover})
+	if err != nil {
+		t.Fatalf("PlanEpoch() error = %v", err)
+	}
+	if plan.LastID != ID(1<<63-1) {
+		t.Errorf("LastID = %d, want the largest ID", plan.LastID)
+	}
+	if _, ok := plan.Best(); ok {
+		t.Error("Best() found a feasible candidate with no ID space left")
+	}
+	if plan.CurrentRemaining != 0 {
+		t.Errorf("CurrentRemaining = %v, want 0", plan.CurrentRemaining)
+	}
+}
+
+func TestPlanEpoch_EpochBefore1970(t *testing.T) {
+	// Half the default range used: a 42-bit layout needs an epoch about 70
+	// years before the cutover, which is before 1970.
+	cutover := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := Scheme{Layout: LayoutDefault, Epoch: cutover.Add(-35 * year).UnixMilli()}
+	long := BitLayout{TimestampBits: 42, WorkerBits: 10, SequenceBits: 11, TimeUnit: time.Millisecond}
+	plan, err := PlanEpoch(current, EpochPlanOptions{Cutover: cutover, Layouts: []BitLayout{long, LayoutDefault}})
+	if err != nil {
+		t.Fatalf("PlanEpoch() error = %v", err)
+	}
+	if len(plan.Candidates) != 2 {
+		t.Fatalf("len(Candidates) = %d, want 2", len(plan.Candidates))
+	}
+	if c := plan.Candidates[0]; !c.Feasible || c.Scheme.Layout != LayoutDefault {
+		t.Errorf("Candidates[0] = %+v, want feasible LayoutDefault", c)
+	}
+	if c := plan.Candidates[1]; c.Feasible || c.Problem == "" {
+		t.Errorf("Candidates[1] = %+v, want infeasible with a Problem", c)
+	}
+}
+
+func TestPlanEpoch_Filters(t *testing.T) {
+	cutover := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	plan, err := PlanEpoch(DefaultScheme, EpochPlanOptions{
+		Cutover:               cutover,
+		MinWorkerBits:         14,
+		MinIDsPerSecPerWorker: 100_000,
+	})
+	if err != nil {
+		t.Fatalf("PlanEpoch() error = %v", err)
+	}
+	if len(plan.Candidates) == 0 {
+		t.Fatal("no candidates")
+	}
+	for _, c := range plan.Candidates {
+		if c.Scheme.Layout.WorkerBits < 14 || c.Capacity.ThroughputPerWorker < 100_000 {
+			t.Errorf("candidate %v (%d IDs/sec) violates the filters", c.Scheme.Layout, c.Capacity.ThroughputPerWorker)
+		}
+	}
+}
+
+func TestPlanEpoch_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		current Scheme
+		opts    EpochPlanOptions
+		field   string
+	}{
+		{"negative margin", DefaultScheme, EpochPlanOptions{Margin: -time.Second}, "Margin"},
+		{"negative worker bits", DefaultScheme, EpochPlanOptions{MinWorkerBits: -1}, "MinWorkerBits"},
+		{"negative rate", DefaultScheme, EpochPlanOptions{MinIDsPerSecPerWorker: -1}, "MinIDsPerSecPerWorker"},
+		{"invalid epoch", Scheme{Layout: LayoutDefault}, EpochPlanOptions{}, "Epoch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PlanEpoch(tt.current, tt.opts)
+			var cfgErr *ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Field != tt.field {
+				t.Errorf("PlanEpoch() error = %v, want ConfigError for %s", err, tt.field)
+			}
+		})
+	}
+}
@@ -15,7 +15,8 @@ import (
 // These can be used with errors.Is() and errors.As() for error checking.
 var (
 	// ErrSequenceOverflow is returned when sequence exhaustion occurs.
-	// This is typically handled internally but can be exposed for monitoring.
+	// GenerateID normally waits for the next time unit instead; it is returned
+	// (wrapped in *OverflowError) by TryGenerateID and when Config.MaxWait is exceeded.
 	ErrSequenceOverflow = errors.New("sequence overflow")
 )
 
@@ -39,7 +39,8 @@ type MetricsExporter struct {
 func NewMetricsExporter(workerID int64) (*MetricsExporter, error) {
 	// Create generator with metrics enabled
 	cfg := snowflake.DefaultConfig(workerID)
-	cfg.EnableMetrics = true
+	cfg.EnableMetrics = true // Latency and wait-time histograms
+	cfg.Name = "ids"         // Value of the "name" label
 
 	gen, err := snowflake.NewWithConfig(cfg)
 	if err != nil {
@@ -57,71 +58,23 @@ func (m *MetricsExporter) GenerateID(ctx context.Context) (snowflake.ID, error)
 	return m.gen.GenerateIDWithContext(ctx)
 }
 
-// PrometheusHandler returns an HTTP handler that exposes metrics in Prometheus format
-func (m *MetricsExporter) PrometheusHandler() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Get current metrics
-		metrics := m.gen.GetMetrics()
-
-		// Write metrics in Prometheus format
-		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
-
-		// Snowflake ID generation metrics
-		fmt.Fprintf(w, "# HELP snowflake_ids_generated_total Total number of IDs generated\n")
-		fmt.Fprintf(w, "# TYPE snowflake_ids_generated_total counter\n")
-		fmt.Fprintf(w, "snowflake_ids_generated_total{worker=\"%d\"} %d\n", m.workerID, metrics.Generated)
-
-		// Clock backward events
-		fmt.Fprintf(w, "# HELP snowflake_clock_backward_total Number of times clock moved backward (recovered)\n")
-		fmt.Fprintf(w, "# TYPE snowflake_clock_backward_total counter\n")
-		fmt.Fprintf(w, "snowflake_clock_backward_total{worker=\"%d\"} %d\n", m.workerID, metrics.ClockBackward)
-
-		// Clock backward errors (unrecoverable)
-		fmt.Fprintf(w, "# HELP snowflake_clock_backward_errors_total Number of unrecoverable clock backward errors\n")
-		fmt.Fprintf(w, "# TYPE snowflake_clock_backward_errors_total counter\n")
-		fmt.Fprintf(w, "snowflake_clock_backward_errors_total{worker=\"%d\"} %d\n", m.workerID, metrics.ClockBackwardErr)
-
-		// Sequence overflows
-		fmt.Fprintf(w, "# HELP snowflake_sequence_overflow_total Number of sequence overflows (had to wait for next ms)\n")
-		fmt.Fprintf(w, "# TYPE snowflake_sequence_overflow_total counter\n")
-		fmt.Fprintf(w, "snowflake_sequence_overflow_total{worker=\"%d\"} %d\n", m.workerID, metrics.SequenceOverflow)
-
-		// Wait time microseconds
-		fmt.Fprintf(w, "# HELP snowflake_wait_time_microseconds_total Total time spent waiting in microseconds\n")
-		fmt.Fprintf(w, "# TYPE snowflake_wait_time_microseconds_total counter\n")
-		fmt.Fprintf(w, "snowflake_wait_time_microseconds_total{worker=\"%d\"} %d\n", m.workerID, metrics.WaitTimeUs)
-
-		// Generator info (static labels)
-		fmt.Fprintf(w, "# HELP snowflake_generator_info Generator information\n")
-		fmt.Fprintf(w, "# TYPE snowflake_generator_info gauge\n")
-		fmt.Fprintf(w, "snowflake_generator_info{worker=\"%d\"} 1\n", m.workerID)
-
-		// Derived metrics (rates calculated by Prometheus)
-		// These are just for documentation, Prometheus will calculate them
-		if metrics.Generated > 0 {
-			avgWaitUs := float64(metrics.WaitTimeUs) / float64(metrics.Generated)
-			fmt.Fprintf(w, "# HELP snowflake_avg_wait_microseconds Average wait time per ID in microseconds\n")
-			fmt.Fprintf(w, "# TYPE snowflake_avg_wait_microseconds gauge\n")
-			fmt.Fprintf(w, "snowflake_avg_wait_microseconds{worker=\"%d\"} %.2f\n", m.workerID, avgWaitUs)
-		}
-	}
+// PrometheusHandler returns an HTTP handler that exposes metrics in Prometheus format.
+//
+// The library writes the exposition format itself, so no Prometheus client
+// dependency is needed. Pass more generators to serve them from one endpoint.
+func (m *MetricsExporter) PrometheusHandler() http.Handler {
+	return snowflake.MetricsHandler(m.gen)
 }
 
-// HealthHandler returns a health check handler
-func (m *MetricsExporter) HealthHandler() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		metrics := m.gen.GetMetrics()
-
-		// Check for critical issues
-		if metrics.ClockBackwardErr > 10 {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			fmt.Fprintf(w, "UNHEALTHY: Too many clock backward errors: %d\n", metrics.ClockBackwardErr)
-			return
-		}
+// HealthHandler returns a readiness handler: 503 while the generator is
+// unhealthy (clock errors or timestamp overflow), with its status as JSON.
+func (m *MetricsExporter) HealthHandler() http.Handler {
+	return snowflake.ReadinessHandler(m.gen)
+}
 
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, "OK: Generated %d IDs\n", metrics.Generated)
-	}
+// LivenessHandler returns a liveness handler that reports status without failing.
+func (m *MetricsExporter) LivenessHandler() http.Handler {
+	return snowflake.LivenessHandler(m.gen)
 }
 
 func main() {
@@ -141,8 +94,9 @@ func main() {
 
 	// Setup HTTP server
 	mux := http.NewServeMux()
-	mux.HandleFunc("/metrics", exporter.PrometheusHandler())
-	mux.HandleFunc("/health", exporter.HealthHandler())
+	mux.Handle("/metrics", exporter.PrometheusHandler())
+	mux.Handle("/health", exporter.HealthHandler())
+	mux.Handle("/livez", exporter.LivenessHandler())
 	mux.HandleFunc("/generate", func(w http.ResponseWriter, r *http.Request) {
 		// Example endpoint that generates IDs
 		ctx := r.Context()
@@ -164,7 +118,8 @@ func main() {
 
 Endpoints:
   /metrics    - Prometheus metrics endpoint
-  /health     - Health check endpoint
+  /health     - Readiness check (503 when unhealthy)
+  /livez      - Liveness check
   /generate   - Generate a new ID (example usage)
 
 Example queries:
@@ -196,6 +151,9 @@ PromQL Queries:
   # Alert on clock issues
   snowflake_clock_backward_errors_total > 10
 
+  # p99 generation latency
+  histogram_quantile(0.99, rate(snowflake_generate_latency_seconds_bucket[5m]))
+
 Worker ID: %d
 `, workerID)
 	})
@@ -128,6 +128,12 @@ func (pm *PartitionManager) InsertEvent(ctx context.Context, id snowflake.ID, da
 
 // QueryEventsByTimeRange queries events in a time range (uses partition pruning)
 func (pm *PartitionManager) QueryEventsByTimeRange(ctx context.Context, start, end time.Time) ([]Event, error) {
+	// Convert the window to an ID range so the primary key index does the work
+	firstID, lastID, err := snowflake.IDRangeForWindow(start, end)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate list of partitions to query
 	partitions := pm.getPartitionsBetween(start, end)
 
@@ -137,10 +143,10 @@ func (pm *PartitionManager) QueryEventsByTimeRange(ctx context.Context, start, e
 	for _, partitionName := range partitions {
 		query := fmt.Sprintf(`
 			SELECT id, data, created_at FROM %s
-			WHERE created_at BETWEEN ? AND ?
+			WHERE id BETWEEN ? AND ?
 		`, partitionName)
 
-		rows, err := pm.db.QueryContext(ctx, query, start, end)
+		rows, err := pm.db.QueryContext(ctx, query, int64(firstID), int64(lastID))
 		if err != nil {
 			// Partition might not exist, skip it
 			continue
@@ -0,0 +1,68 @@
+// Package snowflake - expvar.go publishes generator metrics through the
+// standard library's expvar package.
+//
+// Published variables are computed on each read, so /debug/vars always shows
+// current counters and lifespan figures without a background updater.
+
+package snowflake
+
+import (
+	"expvar"
+	"time"
+)
+
+// PublishExpvar publishes gen's metrics and lifespan under name in expvar.
+//
+// The variable is a JSON object with the Metrics counters, the worker ID,
+// Config.Name and a nested "lifespan" object, evaluated on every read. Like
+// expvar.Publish, it panics if name is already registered, so call it once
+// per generator, typically at startup.
+//
+// Example:
+//
+//	import _ "expvar" // Serves /debug/vars on http.DefaultServeMux
+//
+//	snowflake.PublishExpvar("snowflake_orders", gen)
+//
+//	// curl localhost:8080/debug/vars
+//	// "snowflake_orders": {"generated": 1523478, "sequence_overflow": 152, ...,
+//	//     "lifespan": {"utilization": 0.0138, "remaining_seconds": 2.16e9, ...}}
+func PublishExpvar(name string, gen *Generator) {
+	expvar.Publish(name, ExpvarFunc(gen))
+}
+
+// ExpvarFunc returns an expvar.Func reporting gen's metrics, as published by
+// PublishExpvar. Use it to add a generator to an existing expvar.Map.
+//
+// Example:
+//
+//	vars := expvar.NewMap("ids")
+//	vars.Set("orders", snowflake.ExpvarFunc(orders))
+//	vars.Set("users", snowflake.ExpvarFunc(users))
+func ExpvarFunc(gen *Generator) expvar.Func {
+	return func() any {
+		m := gen.GetMetrics()
+		info := gen.LifespanInfo()
+		return map[string]any{
+			"name":                  gen.Name(),
+			"worker_id":             gen.WorkerID(),
+			"generated":             m.Generated,
+			"clock_backward":        m.ClockBackward,
+			"clock_backward_errors": m.ClockBackwardErr,
+			"sequence_overflow":     m.SequenceOverflow,
+			"wait_time_us":          m.WaitTimeUs,
+			"borrowed":              m.Borrowed,
+			"lead_us":               m.LeadUs,
+			"clock_offset_us":       m.ClockOffsetUs,
+			"reanchors":             m.Reanchors,
+			"lifespan": map[string]any{
+				"utilization":       info.Utilization,
+				"remaining_seconds": info.Remaining.Seconds(),
+				"total_seconds":     info.TotalLifespan.Seconds(),
+				"age_seconds":       info.CurrentAge.Seconds(),
+				"overflow_date":     info.OverflowDate.UTC().Format(time.RFC3339),
+				"is_approaching":    info.IsApproaching,
+			},
+		}
+	}
+}
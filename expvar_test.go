@@ -0,0 +1,49 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	cfg := DefaultConfig(5)
+	cfg.Name = "orders"
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	PublishExpvar("snowflake_test_orders", gen)
+
+	read := func() map[string]any {
+		t.Helper()
+		var v map[string]any
+		if err := json.Unmarshal([]byte(expvar.Get("snowflake_test_orders").String()), &v); err != nil {
+			t.Fatalf("expvar output is not JSON: %v", err)
+		}
+		return v
+	}
+
+	if v := read(); v["generated"] != 0.0 || v["name"] != "orders" || v["worker_id"] != 5.0 {
+		t.Errorf("initial vars = %v", v)
+	}
+
+	// Values are live, not captured at publish time
+	for i := 0; i < 3; i++ {
+		gen.MustGenerateID()
+	}
+	v := read()
+	if v["generated"] != 3.0 {
+		t.Errorf("generated = %v, want 3", v["generated"])
+	}
+	lifespan, ok := v["lifespan"].(map[string]any)
+	if !ok {
+		t.Fatalf("lifespan = %v, want an object", v["lifespan"])
+	}
+	if u, _ := lifespan["utilization"].(float64); u <= 0 || u >= 1 {
+		t.Errorf("lifespan.utilization = %v, want within (0, 1)", lifespan["utilization"])
+	}
+	if lifespan["is_approaching"] != false {
+		t.Errorf("lifespan.is_approaching = %v, want false", lifespan["is_approaching"])
+	}
+}
@@ -0,0 +1,193 @@
+// Package snowflake - grouped.go provides a human-readable grouped ID format.
+//
+// Grouped IDs are meant for places where people read, type or dictate IDs:
+// support tickets, printed receipts, phone calls. The format is upper-case
+// z-base-32 split into short groups, with a check character at the end:
+//
+//	BNEO-O6T6-6UYE-I3
+//
+// Parsing is case-insensitive, ignores separators and whitespace, and rejects
+// input whose check character doesn't match, catching single-character typos
+// and most adjacent transpositions before a wrong ID is looked up.
+
+package snowflake
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// DefaultGroupSize is the number of characters per group in grouped IDs.
+const DefaultGroupSize = 4
+
+// Grouped format errors.
+var (
+	// ErrInvalidGrouped is returned when a grouped ID contains invalid characters.
+	ErrInvalidGrouped = errors.New("invalid grouped ID")
+
+	// ErrInvalidCheckCharacter is returned when a grouped ID's check character doesn't match.
+	// This usually means the ID was mistyped.
+	ErrInvalidCheckCharacter = errors.New("grouped ID check character mismatch")
+)
+
+// GroupedFormat configures how grouped IDs are rendered.
+//
+// The zero value is usable and equivalent to DefaultGroupedFormat.
+//
+// Example:
+//
+//	f := snowflake.GroupedFormat{GroupSize: 3, Separator: " "}
+//	f.Encode(id) // "BNE OO6 T66 UYE I3"
+type GroupedFormat struct {
+	// GroupSize is the number of characters per group.
+	// Default: 4
+	GroupSize int
+
+	// Separator is placed between groups. The check character joins the last
+	// group when that group is short, and gets its own group otherwise.
+	// Default: "-"
+	Separator string
+}
+
+// DefaultGroupedFormat renders IDs as four-character groups separated by dashes.
+var DefaultGroupedFormat = GroupedFormat{
+	GroupSize: DefaultGroupSize,
+	Separator: "-",
+}
+
+// Encode renders the ID as upper-case z-base-32 groups followed by a check character.
+//
+// The check character fills the last group when it is shorter than GroupSize,
+// so no group is ever longer than GroupSize and there's never a lone trailing
+// group next to a short one.
+//
+// Built on the Base32 encoder, so the body decodes with ParseBase32 once separators
+// and the check character are removed and the text is lower-cased.
+//
+// Performance: ~600ns (Base32 encoding + check character)
+func (f GroupedFormat) Encode(id ID) string {
+	groupSize := f.GroupSize
+	if groupSize <= 0 {
+		groupSize = DefaultGroupSize
+	}
+	sep := f.Separator
+	if sep == "" {
+		sep = DefaultGroupedFormat.Separator
+	}
+
+	body := encodeBase32(int64(id))
+	check := groupedCheckCharacter(body)
+
+	var b strings.Builder
+	b.Grow(len(body) + 1 + (len(body)/groupSize)*len(sep))
+	for i := 0; i < len(body); i++ {
+		if i > 0 && i%groupSize == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteByte(toUpperASCII(body[i]))
+	}
+	if len(body)%groupSize == 0 {
+		b.WriteString(sep) // Last group is full; check character starts a new one
+	}
+	b.WriteByte(toUpperASCII(check))
+
+	return b.String()
+}
+
+// Parse decodes a grouped ID, verifying its check character.
+//
+// Parsing is case-insensitive and ignores whitespace, dashes and the configured
+// separator anywhere in the input, so "bneo o6t6 6uye i3" and "BNEO-O6T6-6UYE-I3"
+// decode to the same ID.
+//
+// Returns ErrInvalidGrouped for characters outside the z-base-32 alphabet and
+// ErrInvalidCheckCharacter when the check character doesn't match.
+func (f GroupedFormat) Parse(s string) (ID, error) {
+	sep := f.Separator
+	if sep == "" {
+		sep = DefaultGroupedFormat.Separator
+	}
+
+	// Normalize: drop separators and whitespace, lower-case the rest
+	b := make([]byte, 0, len(s))
+	for _, r := range strings.ReplaceAll(s, sep, "") {
+		if r == '-' || unicode.IsSpace(r) {
+			continue
+		}
+		if r >= 0x80 {
+			return 0, ErrInvalidGrouped
+		}
+		c := byte(unicode.ToLower(r))
+		if decodeBase32Map[c] == 0xFF {
+			return 0, ErrInvalidGrouped
+		}
+		b = append(b, c)
+	}
+
+	// Need at least one body character plus the check character
+	if len(b) < 2 {
+		return 0, ErrInvalidGrouped
+	}
+	if len(b)-1 > MaxBase32Len {
+		return 0, ErrStringTooLong
+	}
+
+	body, check := string(b[:len(b)-1]), b[len(b)-1]
+	if groupedCheckCharacter(body) != check {
+		return 0, ErrInvalidCheckCharacter
+	}
+
+	return ParseBase32(body)
+}
+
+// Grouped returns the ID in DefaultGroupedFormat.
+//
+// Use this for IDs that humans read or type, such as support ticket references.
+//
+// Example:
+//
+//	id.Grouped() // "BNEO-O6T6-6UYE-I3"
+func (id ID) Grouped() string {
+	return DefaultGroupedFormat.Encode(id)
+}
+
+// ParseGrouped parses a grouped ID produced by ID.Grouped or any GroupedFormat
+// that uses dashes or whitespace as separators.
+//
+// Example:
+//
+//	id, err := snowflake.ParseGrouped("bneo-o6t6-6uye-i3")
+func ParseGrouped(s string) (ID, error) {
+	return DefaultGroupedFormat.Parse(s)
+}
+
+// groupedCheckCharacter computes the Luhn mod 32 check character for a z-base-32 string.
+//
+// Luhn mod N detects every single-character substitution and every transposition
+// of adjacent characters except one pair per alphabet, which covers the typos
+// people make when copying IDs by hand.
+//
+// The input must contain only lower-case z-base-32 characters.
+func groupedCheckCharacter(body string) byte {
+	const n = len(encodeBase32Map)
+
+	factor := 2
+	sum := 0
+	for i := len(body) - 1; i >= 0; i-- {
+		addend := factor * int(decodeBase32Map[body[i]])
+		addend = addend/n + addend%n
+		sum += addend
+		factor = 3 - factor // Alternate 2, 1, 2, 1, ...
+	}
+
+	return encodeBase32Map[(n-sum%n)%n]
+}
+
+// toUpperASCII upper-cases a single ASCII letter, leaving other bytes unchanged.
+func toUpperASCII(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
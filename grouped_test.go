@@ -0,0 +1,207 @@
+package snowflake
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGrouped_RoundTrip(t *testing.T) {
+	gen, _ := New(42)
+	for i := 0; i < 1000; i++ {
+		id := gen.MustGenerateID()
+		encoded := id.Grouped()
+
+		decoded, err := ParseGrouped(encoded)
+		if err != nil {
+			t.Fatalf("ParseGrouped(%q) error = %v", encoded, err)
+		}
+		if decoded != id {
+			t.Fatalf("ParseGrouped(%q) = %d, want %d", encoded, decoded, id)
+		}
+	}
+}
+
+func TestGrouped_Shape(t *testing.T) {
+	id := ID(1234567890123456789)
+	got := id.Grouped()
+
+	if got != "BNEO-O6T6-6UYE-I3" {
+		t.Errorf("Grouped() = %q, want %q", got, "BNEO-O6T6-6UYE-I3")
+	}
+	if got != strings.ToUpper(got) {
+		t.Errorf("Grouped() should be upper-case, got %q", got)
+	}
+
+	// Body is the Base32 encoding
+	compact := strings.ReplaceAll(got, "-", "")
+	body := strings.ToLower(compact[:len(compact)-1])
+	if body != id.Base32() {
+		t.Errorf("body = %q, want Base32 %q", body, id.Base32())
+	}
+}
+
+func TestGrouped_CheckCharacterPlacement(t *testing.T) {
+	id := ID(1234567890123456789)
+
+	// Short last group: check character joins it
+	// Full last group: check character starts its own group
+	tests := []struct {
+		format GroupedFormat
+		want   string
+	}{
+		{GroupedFormat{GroupSize: 4}, "BNEO-O6T6-6UYE-I3"},
+		{GroupedFormat{GroupSize: 5}, "BNEOO-6T66U-YEI3"},
+		{GroupedFormat{GroupSize: 13}, "BNEOO6T66UYEI-3"},
+		{GroupedFormat{GroupSize: 20}, "BNEOO6T66UYEI3"},
+	}
+	for _, tt := range tests {
+		if got := tt.format.Encode(id); got != tt.want {
+			t.Errorf("GroupSize %d: Encode() = %q, want %q", tt.format.GroupSize, got, tt.want)
+		}
+	}
+
+	// No group is ever longer than GroupSize
+	gen, _ := New(1)
+	for i := 0; i < 100; i++ {
+		encoded := gen.MustGenerateID().Grouped()
+		for _, g := range strings.Split(encoded, "-") {
+			if len(g) == 0 || len(g) > DefaultGroupSize {
+				t.Fatalf("Grouped() = %q has group %q outside 1..%d chars", encoded, g, DefaultGroupSize)
+			}
+		}
+	}
+}
+
+func TestGrouped_LenientParsing(t *testing.T) {
+	id := ID(1234567890123456789)
+
+	inputs := []string{
+		"BNEO-O6T6-6UYE-I3",
+		"bneo-o6t6-6uye-i3",
+		"bneo o6t6 6uye i3",
+		"BNEO-O6T6-6UYE-I-3",
+		"  BNEOO6T66UYEI3\n",
+		"Bneo-O6t6 - 6uye-i3",
+	}
+	for _, input := range inputs {
+		got, err := ParseGrouped(input)
+		if err != nil {
+			t.Errorf("ParseGrouped(%q) error = %v", input, err)
+			continue
+		}
+		if got != id {
+			t.Errorf("ParseGrouped(%q) = %d, want %d", input, got, id)
+		}
+	}
+}
+
+func TestGrouped_CustomFormat(t *testing.T) {
+	id := ID(1234567890123456789)
+	f := GroupedFormat{GroupSize: 3, Separator: "."}
+
+	encoded := f.Encode(id)
+	if encoded != "BNE.OO6.T66.UYE.I3" {
+		t.Errorf("Encode() = %q, want %q", encoded, "BNE.OO6.T66.UYE.I3")
+	}
+
+	decoded, err := f.Parse(encoded)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if decoded != id {
+		t.Errorf("Parse() = %d, want %d", decoded, id)
+	}
+
+	// Zero value behaves like the default
+	if got := (GroupedFormat{}).Encode(id); got != id.Grouped() {
+		t.Errorf("zero GroupedFormat.Encode() = %q, want %q", got, id.Grouped())
+	}
+}
+
+func TestGrouped_DetectsSubstitution(t *testing.T) {
+	id := ID(1234567890123456789)
+	compact := strings.ReplaceAll(id.Grouped(), "-", "")
+
+	for pos := 0; pos < len(compact); pos++ {
+		for i := 0; i < len(encodeBase32Map); i++ {
+			c := toUpperASCII(encodeBase32Map[i])
+			if c == compact[pos] {
+				continue
+			}
+			typo := compact[:pos] + string(c) + compact[pos+1:]
+			if _, err := ParseGrouped(typo); !errors.Is(err, ErrInvalidCheckCharacter) {
+				t.Fatalf("ParseGrouped(%q) error = %v, want ErrInvalidCheckCharacter", typo, err)
+			}
+		}
+	}
+}
+
+func TestGrouped_DetectsAdjacentTransposition(t *testing.T) {
+	gen, _ := New(7)
+	detected, total := 0, 0
+
+	for i := 0; i < 200; i++ {
+		compact := strings.ReplaceAll(gen.MustGenerateID().Grouped(), "-", "")
+		for pos := 0; pos+1 < len(compact); pos++ {
+			if compact[pos] == compact[pos+1] {
+				continue
+			}
+			b := []byte(compact)
+			b[pos], b[pos+1] = b[pos+1], b[pos]
+			total++
+			if _, err := ParseGrouped(string(b)); err != nil {
+				detected++
+			}
+		}
+	}
+
+	// Luhn mod N misses only one transposition pair per alphabet
+	if rate := float64(detected) / float64(total); rate < 0.95 {
+		t.Errorf("transposition detection rate = %.3f, want >= 0.95", rate)
+	}
+}
+
+func TestGrouped_Invalid(t *testing.T) {
+	tests := []struct {
+		input string
+		want  error
+	}{
+		{"", ErrInvalidGrouped},
+		{"B", ErrInvalidGrouped},
+		{"BNEO-O6T6-6UYE-I0", ErrInvalidGrouped}, // '0' not in z-base-32
+		{"BNEO-O6T6-6UYE-Ié", ErrInvalidGrouped},
+		{"BNEO-O6T6-6UYE-IY", ErrInvalidCheckCharacter},
+		{strings.Repeat("Y", 20), ErrStringTooLong},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseGrouped(tt.input); !errors.Is(err, tt.want) {
+			t.Errorf("ParseGrouped(%q) error = %v, want %v", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestGrouped_FormatAndParseAny(t *testing.T) {
+	id := ID(1234567890123456789)
+
+	if got := id.Format("grouped"); got != id.Grouped() {
+		t.Errorf("Format(grouped) = %q, want %q", got, id.Grouped())
+	}
+
+	got, enc, err := ParseAny(id.Grouped())
+	if err != nil {
+		t.Fatalf("ParseAny() error = %v", err)
+	}
+	if got != id || enc != EncodingGrouped {
+		t.Errorf("ParseAny() = (%d, %s), want (%d, grouped)", got, enc, id)
+	}
+
+	got, enc, err = ParseAny("grp:bneo o6t6 6uye i3")
+	if err != nil {
+		t.Fatalf("ParseAny(grp:) error = %v", err)
+	}
+	if got != id || enc != EncodingGrouped {
+		t.Errorf("ParseAny(grp:) = (%d, %s), want (%d, grouped)", got, enc, id)
+	}
+}
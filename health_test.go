@@ -0,0 +1,156 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealth_OK(t *testing.T) {
+	gen, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if h := gen.Health(); h.Status != HealthOK || !h.LastGenerated.IsZero() {
+		t.Errorf("fresh Health() = %+v, want ok with no LastGenerated", h)
+	}
+
+	gen.MustGenerateID()
+	h := gen.Health()
+	if h.Status != HealthOK || len(h.Problems) != 0 {
+		t.Errorf("Health() = %+v, want ok", h)
+	}
+	if age := h.CheckedAt.Sub(h.LastGenerated); age < 0 || age > time.Second {
+		t.Errorf("LastGenerated = %v, want just before CheckedAt %v", h.LastGenerated, h.CheckedAt)
+	}
+}
+
+func TestHealth_ClockErrors(t *testing.T) {
+	gen, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Drift beyond tolerance fails generation
+	gen.mu.Lock()
+	gen.lastTimestamp = gen.currentTimestamp() + 100
+	gen.lastClock = gen.lastTimestamp
+	gen.mu.Unlock()
+	if _, err := gen.GenerateID(); !IsClockError(err) {
+		t.Fatalf("GenerateID() error = %v, want ClockError", err)
+	}
+
+	h := gen.Health()
+	if h.Status != HealthUnhealthy || h.ClockErrorRate <= 0 || len(h.Problems) != 1 {
+		t.Fatalf("Health() = %+v, want unhealthy from clock errors", h)
+	}
+	if h.LastGenerated.After(h.CheckedAt) {
+		t.Errorf("LastGenerated %v is after CheckedAt %v", h.LastGenerated, h.CheckedAt)
+	}
+
+	// Readiness fails; liveness does not
+	for _, tc := range []struct {
+		handler http.Handler
+		code    int
+	}{
+		{ReadinessHandler(gen), http.StatusServiceUnavailable},
+		{LivenessHandler(gen), http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Code != tc.code {
+			t.Errorf("status code = %d, want %d", rec.Code, tc.code)
+		}
+		var body struct {
+			Status     string `json:"status"`
+			Generators []struct {
+				Status   string   `json:"status"`
+				WorkerID int64    `json:"worker_id"`
+				Problems []string `json:"problems"`
+			} `json:"generators"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("body is not JSON: %v\n%s", err, rec.Body)
+		}
+		if body.Status != "unhealthy" || len(body.Generators) != 1 || body.Generators[0].WorkerID != 1 {
+			t.Errorf("body = %+v, want one unhealthy generator", body)
+		}
+	}
+
+	// A generator older than the window is unhealthy after a single error,
+	// even on its first check
+	old, _ := New(3)
+	old.epoch = old.epoch.Add(-30 * time.Minute)
+	old.clockBackwardErr.Add(1)
+	if h := old.Health(); h.Status != HealthUnhealthy {
+		t.Errorf("Health() of a 30-minute-old generator with one clock error = %+v, want unhealthy", h)
+	}
+
+	// A negative threshold disables the check
+	cfg := DefaultConfig(2)
+	cfg.Health.MaxClockErrorRate = -1
+	gen, _ = NewWithConfig(cfg)
+	gen.clockBackwardErr.Add(1)
+	if h := gen.Health(); h.Status != HealthOK {
+		t.Errorf("Health() with the check disabled = %v, want ok", h.Status)
+	}
+}
+
+func TestHealth_Degraded(t *testing.T) {
+	// LayoutUltra lasts ~17.4 years; start 85% of the way through it
+	total := time.Duration((int64(1)<<LayoutUltra.TimestampBits)-1) * time.Millisecond
+	cfg := DefaultConfig(1)
+	cfg.Layout = LayoutUltra
+	cfg.Epoch = time.Now().Add(-total / 100 * 85).UnixMilli()
+	cfg.Health.MaxIdle = time.Millisecond
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	gen.MustGenerateID()
+	time.Sleep(20 * time.Millisecond)
+
+	h := gen.Health()
+	if h.Status != HealthDegraded || !h.LifespanApproaching || len(h.Problems) != 2 {
+		t.Errorf("Health() = %+v, want degraded by lifespan and idleness", h)
+	}
+
+	rec := httptest.NewRecorder()
+	ReadinessHandler(gen).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("readiness code = %d for a degraded generator, want 200", rec.Code)
+	}
+}
+
+func TestHealthTracker_Window(t *testing.T) {
+	var tr healthTracker
+	start := time.Unix(1000, 0)
+
+	// No earlier reading: averaged since start
+	clk, ovf := tr.rates(healthSample{at: start.Add(10 * time.Second), clockErrs: 1, overflows: 100}, time.Minute, start)
+	if clk != 0.1 || ovf != 10 {
+		t.Errorf("rates = %v, %v; want 0.1, 10", clk, ovf)
+	}
+
+	// The base is the newest reading at least one window old; counts since
+	// it are divided by the window
+	tr.rates(healthSample{at: start.Add(30 * time.Second), clockErrs: 1, overflows: 100}, time.Minute, start)
+	clk, ovf = tr.rates(healthSample{at: start.Add(100 * time.Second), clockErrs: 1, overflows: 220}, time.Minute, start)
+	if clk != 0 || ovf != 2 {
+		t.Errorf("rates = %v, %v; want 0, 2 (since the reading at 30s)", clk, ovf)
+	}
+
+	// Sparse readings don't dilute a single error
+	clk, _ = tr.rates(healthSample{at: start.Add(30 * time.Minute), clockErrs: 2, overflows: 220}, time.Minute, start)
+	if clk != 1.0/60 {
+		t.Errorf("clock error rate = %v after a 29-minute gap, want 1/60", clk)
+	}
+
+	// Counters reset: rates never go negative
+	clk, ovf = tr.rates(healthSample{at: start.Add(101 * time.Second)}, time.Minute, start)
+	if clk != 0 || ovf != 0 {
+		t.Errorf("rates after reset = %v, %v; want 0, 0", clk, ovf)
+	}
+}
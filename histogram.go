@@ -0,0 +1,184 @@
+// Package snowflake - histogram.go provides lock-free latency histograms.
+//
+// Durations are counted in power-of-two nanosecond buckets, so recording a
+// value is one bits.Len64 and two atomic adds, with no locks or allocation.
+// The resolution (each bucket spans a factor of two) is coarse but enough to
+// tell a few long stalls from many short waits.
+
+package snowflake
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// histogramBuckets is the number of buckets: bucket 0 holds 0ns, bucket i
+// holds [2^(i-1), 2^i) ns, and the last bucket also holds everything longer.
+// 2^39ns is about 9 minutes.
+const histogramBuckets = 40
+
+// histogram is a lock-free, fixed-bucket duration histogram. The zero value is ready to use.
+type histogram struct {
+	sum     atomic.Int64 // Nanoseconds
+	buckets [histogramBuckets]atomic.Int64
+}
+
+// observe records one duration. Negative durations are recorded as 0.
+func (h *histogram) observe(d time.Duration) {
+	ns := max(int64(d), 0)
+	i := min(bits.Len64(uint64(ns)), histogramBuckets-1)
+	h.buckets[i].Add(1)
+	h.sum.Add(ns)
+}
+
+// reset zeroes all counters.
+func (h *histogram) reset() {
+	h.sum.Store(0)
+	for i := range h.buckets {
+		h.buckets[i].Store(0)
+	}
+}
+
+// snapshot copies the current counts.
+//
+// Counters are read one at a time without a lock, so a snapshot taken while
+// values are being recorded may be off by those in-flight values. Count is
+// derived from the buckets so percentiles stay self-consistent.
+func (h *histogram) snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Sum:     time.Duration(h.sum.Load()),
+		Buckets: make([]HistogramBucket, histogramBuckets),
+	}
+	for i := range h.buckets {
+		n := h.buckets[i].Load()
+		s.Buckets[i] = HistogramBucket{UpperBound: bucketUpperBound(i), Count: n}
+		s.Count += n
+	}
+	return s
+}
+
+// bucketUpperBound returns the exclusive upper bound of bucket i.
+func bucketUpperBound(i int) time.Duration {
+	return time.Duration(int64(1) << i)
+}
+
+// bucketLowerBound returns the inclusive lower bound of bucket i.
+func bucketLowerBound(i int) time.Duration {
+	if i == 0 {
+		return 0
+	}
+	return time.Duration(int64(1) << (i - 1))
+}
+
+// HistogramBucket is one bucket of a HistogramSnapshot.
+type HistogramBucket struct {
+	UpperBound time.Duration // Exclusive upper bound (the last bucket also counts longer values)
+	Count      int64         // Values in [previous bucket's UpperBound, UpperBound)
+}
+
+// HistogramSnapshot is a point-in-time copy of a duration histogram.
+//
+// Buckets are in ascending order with power-of-two nanosecond bounds and
+// non-cumulative counts.
+type HistogramSnapshot struct {
+	Count   int64             // Number of recorded values
+	Sum     time.Duration     // Sum of recorded values
+	Buckets []HistogramBucket // Per-bucket counts
+}
+
+// Mean returns the average recorded value, or 0 if empty.
+func (s HistogramSnapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// Percentile returns an estimate of the p-th percentile (0 < p <= 100), or 0 if empty.
+//
+// The estimate interpolates linearly within the bucket holding the
+// percentile, so it is accurate to within that bucket's factor-of-two range.
+//
+// Example:
+//
+//	snap := gen.MetricsSnapshot()
+//	fmt.Printf("p99 latency: %v\n", snap.Latency.Percentile(99))
+func (s HistogramSnapshot) Percentile(p float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	p = min(max(p, 0), 100)
+	rank := p / 100 * float64(s.Count)
+
+	var seen int64
+	for i, b := range s.Buckets {
+		if b.Count == 0 {
+			continue
+		}
+		if float64(seen+b.Count) >= rank {
+			lower, upper := bucketLowerBound(i), b.UpperBound
+			frac := (rank - float64(seen)) / float64(b.Count)
+			return lower + time.Duration(frac*float64(upper-lower))
+		}
+		seen += b.Count
+	}
+	return s.Buckets[len(s.Buckets)-1].UpperBound
+}
+
+// merge adds the counts of other to s, for aggregating several generators.
+func (s HistogramSnapshot) merge(other HistogramSnapshot) HistogramSnapshot {
+	if s.Buckets == nil {
+		s.Buckets = make([]HistogramBucket, len(other.Buckets))
+		copy(s.Buckets, other.Buckets)
+		s.Count, s.Sum = other.Count, other.Sum
+		return s
+	}
+	s.Count += other.Count
+	s.Sum += other.Sum
+	for i := range s.Buckets {
+		s.Buckets[i].Count += other.Buckets[i].Count
+	}
+	return s
+}
+
+// MetricsSnapshot extends Metrics with duration histograms.
+//
+// Histograms are recorded only when Config.EnableMetrics is true.
+type MetricsSnapshot struct {
+	Metrics
+
+	// Latency is the duration of each single-ID call (GenerateID, Generate,
+	// TryGenerateID and their variants), including waiting for the lock.
+	Latency HistogramSnapshot
+
+	// OverflowWait is the duration of each wait for the next time unit after
+	// a sequence overflow.
+	OverflowWait HistogramSnapshot
+
+	// ClockWait is the duration of each wait for the clock to catch up after
+	// it moved backward within tolerance.
+	ClockWait HistogramSnapshot
+}
+
+// MetricsSnapshot returns counters and histograms in one snapshot.
+//
+// Performance: ~1µs (copies 120 histogram buckets)
+// Thread-safe: Yes, uses atomic operations
+//
+// Example:
+//
+//	snap := gen.MetricsSnapshot()
+//	log.Info("generator latency",
+//	    "p50", snap.Latency.Percentile(50),
+//	    "p99", snap.Latency.Percentile(99),
+//	    "overflow_waits", snap.OverflowWait.Count,
+//	    "overflow_wait_p99", snap.OverflowWait.Percentile(99))
+func (g *Generator) MetricsSnapshot() MetricsSnapshot {
+	return MetricsSnapshot{
+		Metrics:      g.GetMetrics(),
+		Latency:      g.latency.snapshot(),
+		OverflowWait: g.overflowWait.snapshot(),
+		ClockWait:    g.clockWait.snapshot(),
+	}
+}
@@ -0,0 +1,117 @@
+package snowflake
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHistogram_Percentile(t *testing.T) {
+	var h histogram
+
+	// 99 fast values and one slow outlier
+	for i := 0; i < 99; i++ {
+		h.observe(time.Microsecond)
+	}
+	h.observe(5 * time.Millisecond)
+
+	s := h.snapshot()
+	if s.Count != 100 {
+		t.Fatalf("Count = %d, want 100", s.Count)
+	}
+	if want := 99*time.Microsecond + 5*time.Millisecond; s.Sum != want {
+		t.Errorf("Sum = %v, want %v", s.Sum, want)
+	}
+
+	// Bucket resolution is a factor of two
+	if p50 := s.Percentile(50); p50 < 512*time.Nanosecond || p50 > 1024*time.Nanosecond {
+		t.Errorf("p50 = %v, want within [512ns, 1024ns]", p50)
+	}
+	if p100 := s.Percentile(100); p100 < 4*time.Millisecond || p100 > 9*time.Millisecond {
+		t.Errorf("p100 = %v, want the 5ms outlier's bucket", p100)
+	}
+	if mean := s.Mean(); mean != s.Sum/100 {
+		t.Errorf("Mean() = %v, want %v", mean, s.Sum/100)
+	}
+
+	// Out-of-range values land in the end buckets
+	h.observe(-time.Second)
+	h.observe(time.Hour)
+	s = h.snapshot()
+	if s.Buckets[0].Count != 1 || s.Buckets[len(s.Buckets)-1].Count != 1 {
+		t.Errorf("end buckets = %d, %d; want 1, 1", s.Buckets[0].Count, s.Buckets[len(s.Buckets)-1].Count)
+	}
+
+	h.reset()
+	if s := h.snapshot(); s.Count != 0 || s.Sum != 0 || s.Percentile(99) != 0 {
+		t.Errorf("after reset = %+v, want empty", s)
+	}
+}
+
+func TestMetricsSnapshot(t *testing.T) {
+	gen, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		gen.MustGenerateID()
+	}
+	// Two full time units force at least one overflow wait
+	ctx := context.Background()
+	gen.Reserve(ctx, 4096)
+	gen.Reserve(ctx, 4096)
+
+	snap := gen.MetricsSnapshot()
+	if snap.Latency.Count != 100 {
+		t.Errorf("Latency.Count = %d, want 100", snap.Latency.Count)
+	}
+	if snap.Latency.Percentile(50) <= 0 {
+		t.Errorf("Latency p50 = %v, want > 0", snap.Latency.Percentile(50))
+	}
+	if snap.OverflowWait.Count != snap.SequenceOverflow || snap.OverflowWait.Count == 0 {
+		t.Errorf("OverflowWait.Count = %d, want SequenceOverflow (%d) > 0", snap.OverflowWait.Count, snap.SequenceOverflow)
+	}
+	if snap.Generated != 100+2*4096 {
+		t.Errorf("Generated = %d, want %d", snap.Generated, 100+2*4096)
+	}
+
+	gen.ResetMetrics()
+	snap = gen.MetricsSnapshot()
+	if snap.Latency.Count != 0 || snap.OverflowWait.Count != 0 {
+		t.Errorf("after ResetMetrics histograms = %d, %d; want 0", snap.Latency.Count, snap.OverflowWait.Count)
+	}
+}
+
+func TestMetricsSnapshot_Disabled(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.EnableMetrics = false
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	gen.MustGenerateID()
+	snap := gen.MetricsSnapshot()
+	if snap.Latency.Count != 0 {
+		t.Errorf("Latency.Count = %d with metrics disabled, want 0", snap.Latency.Count)
+	}
+	if snap.Generated != 1 {
+		t.Errorf("Generated = %d, counters are kept regardless", snap.Generated)
+	}
+}
+
+func TestPool_MetricsSnapshot(t *testing.T) {
+	pool, err := NewPool(PoolConfig{Config: DefaultConfig(0), Size: 3})
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	for i := 0; i < 30; i++ {
+		pool.MustGenerateID()
+	}
+
+	snap := pool.MetricsSnapshot()
+	if snap.Latency.Count != 30 || snap.Generated != 30 {
+		t.Errorf("Latency.Count = %d, Generated = %d; want 30, 30", snap.Latency.Count, snap.Generated)
+	}
+}
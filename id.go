@@ -446,10 +446,13 @@ func (id *ID) UnmarshalText(text []byte) error {
 // Handles multiple database column types: BIGINT, VARCHAR, TEXT.
 //
 // Supported types:
-//   - int64: Direct mapping from BIGINT columns
-//   - []byte: From VARCHAR/TEXT columns
-//   - string: From VARCHAR/TEXT columns
-//   - nil: Treated as zero ID
+//   - int64, int32, int: Direct mapping from integer columns
+//   - uint64: From unsigned columns, if it fits in int64
+//   - string: Decimal integers from VARCHAR/TEXT columns
+//   - []byte: Decimal integers, or any format accepted by ParseAny
+//   - nil: Treated as zero ID (use NullID to detect NULL)
+//
+// float64 is rejected, since it cannot represent IDs above 2^53 exactly.
 //
 // Performance: ~50ns (type switch + conversion)
 //
@@ -463,25 +466,11 @@ func (id *ID) Scan(value interface{}) error {
 		return nil
 	}
 
-	switch v := value.(type) {
-	case int64:
-		*id = ID(v)
-	case []byte:
-		i, err := strconv.ParseInt(string(v), 10, 64)
-		if err != nil {
-			return err
-		}
-		*id = ID(i)
-	case string:
-		i, err := strconv.ParseInt(v, 10, 64)
-		if err != nil {
-			return err
-		}
-		*id = ID(i)
-	default:
-		return fmt.Errorf("cannot scan %T into ID", value)
+	parsed, err := scanID(value)
+	if err != nil {
+		return err
 	}
-
+	*id = parsed
 	return nil
 }
 
@@ -713,13 +702,7 @@ func (id ID) Time() time.Time {
 //	id, _ := gen.GenerateID()
 //	t := id.TimeWithLayout(snowflake.LayoutSuperior)
 func (id ID) TimeWithLayout(layout BitLayout) time.Time {
-	timestampShift, _, _, _ := layout.CalculateShifts()
-
-	// Extract timestamp in time units and convert to milliseconds
-	timeUnits := int64(id) >> timestampShift
-	ms := (timeUnits * layout.TimeUnit.Milliseconds()) + Epoch
-
-	return time.Unix(ms/1000, (ms%1000)*1000000)
+	return Scheme{Layout: layout, Epoch: Epoch}.Time(id)
 }
 
 // Timestamp returns the timestamp component in milliseconds since Unix epoch.
@@ -748,11 +731,7 @@ func (id ID) Timestamp() int64 {
 //
 //	ts := id.TimestampWithLayout(snowflake.LayoutSuperior)
 func (id ID) TimestampWithLayout(layout BitLayout) int64 {
-	timestampShift, _, _, _ := layout.CalculateShifts()
-
-	// Extract timestamp in time units and convert to milliseconds
-	timeUnits := int64(id) >> timestampShift
-	return (timeUnits * layout.TimeUnit.Milliseconds()) + Epoch
+	return Scheme{Layout: layout, Epoch: Epoch}.Time(id).UnixMilli()
 }
 
 // Worker returns the worker ID component.
@@ -844,15 +823,7 @@ func (id ID) Components() (timestamp int64, workerID int64, sequence int64) {
 //	fmt.Printf("Generated by worker %d at %v with sequence %d\n",
 //	    worker, time.UnixMilli(ts), seq)
 func (id ID) ComponentsWithLayout(layout BitLayout) (timestamp int64, workerID int64, sequence int64) {
-	timestampShift, workerShift, maxWorker, maxSequence := layout.CalculateShifts()
-
-	// Extract timestamp in time units and convert to milliseconds
-	timeUnits := int64(id) >> timestampShift
-	timestamp = (timeUnits * layout.TimeUnit.Milliseconds()) + Epoch
-
-	workerID = (int64(id) >> workerShift) & maxWorker
-	sequence = int64(id) & maxSequence
-	return
+	return Scheme{Layout: layout, Epoch: Epoch}.Components(id)
 }
 
 // ============================================================================
@@ -1135,6 +1106,8 @@ func (id ID) ShardByWorker(numShards int64) int64 {
 //	// Partition by day
 //	dayBucket := id.ShardByTime(24 * time.Hour)
 //	tableName := fmt.Sprintf("events_%s", time.Unix(dayBucket*86400, 0).Format("2006_01_02"))
+//
+// For bucket names, ID bounds and partition DDL, see Partitioner.
 func (id ID) ShardByTime(bucketSize time.Duration) int64 {
 	if bucketSize <= 0 {
 		return 0
@@ -1154,8 +1127,13 @@ func (id ID) ShardByTime(bucketSize time.Duration) int64 {
 //   - "base58", "b58", "58": Base58 (Bitcoin-style)
 //   - "base62", "b62", "62": Base62 (URL-safe)
 //   - "base64", "b64", "64": Base64
+//   - "base64url", "b64url": URL-safe Base64
+//   - "grouped", "grp": Grouped z-base-32 with check character (see Grouped)
 //   - "decimal", "dec", "d", "": Decimal (default)
 //
+// Names are matched case-insensitively (see ParseEncoding). Unknown formats
+// fall back to decimal.
+//
 // Performance: Varies by format (see individual encoding methods)
 //
 // Example:
@@ -1165,26 +1143,11 @@ func (id ID) ShardByTime(bucketSize time.Duration) int64 {
 //	id.Format("b58")    // "BukQL2gPvMW"
 //	id.Format("")       // "1234567890123456789" (decimal)
 func (id ID) Format(format string) string {
-	switch format {
-	case "hex", "x":
-		return id.Hex()
-	case "binary", "bin", "b":
-		return id.Base2()
-	case "base32", "b32", "32":
-		return id.Base32()
-	case "base36", "b36", "36":
-		return id.Base36()
-	case "base58", "b58", "58":
-		return id.Base58()
-	case "base62", "b62", "62":
-		return id.Base62()
-	case "base64", "b64", "64":
-		return id.Base64()
-	case "decimal", "dec", "d", "":
-		return id.String()
-	default:
+	enc, err := ParseEncoding(format)
+	if err != nil {
 		return id.String()
 	}
+	return enc.Encode(id)
 }
 
 // IDWithFormat wraps an ID with a custom format for JSON marshaling.
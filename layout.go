@@ -7,8 +7,14 @@
 package snowflake
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,11 +28,15 @@ import (
 // # Constraints
 //
 // The sum of all bits must equal 63 (64-bit signed int, excluding sign bit).
-// Each component must be positive and reasonable:
+// By default each component must also be in a standard range:
 //   - TimestampBits: 38-42 (provides 8.7 to 139 years)
 //   - WorkerBits: 8-18 (supports 256 to 262,144 nodes)
 //   - SequenceBits: 6-14 (provides 64 to 16,384 IDs per time unit)
 //
+// Set AllowNonStandard to use other splits, such as 20 worker bits with 5
+// sequence bits for large fleets of low-rate devices; Lint still reports
+// components outside these ranges.
+//
 // # Performance
 //
 // Bit layout is validated once at generator creation. All bit operations
@@ -44,17 +54,17 @@ import (
 type BitLayout struct {
 	// TimestampBits is the number of bits allocated for timestamp.
 	// More bits = longer lifespan, fewer bits for workers/sequence.
-	// Range: 38-42 bits (8.7 to 139 years with 1ms precision)
+	// Standard range: 38-42 bits (8.7 to 139 years with 1ms precision)
 	TimestampBits int
 
 	// WorkerBits is the number of bits allocated for worker/node ID.
 	// More bits = more distributed nodes, fewer bits for timestamp/sequence.
-	// Range: 8-18 bits (256 to 262,144 nodes)
+	// Standard range: 8-18 bits (256 to 262,144 nodes)
 	WorkerBits int
 
 	// SequenceBits is the number of bits allocated for sequence counter.
 	// More bits = higher throughput per node, fewer bits for timestamp/workers.
-	// Range: 6-14 bits (64 to 16,384 IDs per time unit)
+	// Standard range: 6-14 bits (64 to 16,384 IDs per time unit)
 	SequenceBits int
 
 	// TimeUnit is the precision of the timestamp.
@@ -62,6 +72,13 @@ type BitLayout struct {
 	// Larger units = coarser precision, longer lifespan.
 	// Common values: 1ms (default), 2ms, 10ms
 	TimeUnit time.Duration
+
+	// AllowNonStandard lifts the standard bit ranges, so Validate only checks
+	// that the components are non-negative, the timestamp has 1-62 bits,
+	// the bits sum to 63 and the time unit is positive. Use Lint to see
+	// which ranges the layout leaves. The spec form of such a layout carries
+	// a "custom:" prefix.
+	AllowNonStandard bool
 }
 
 // Pre-defined layouts optimized for different use cases.
@@ -280,9 +297,9 @@ var (
 //
 // A valid layout must:
 //   - Sum to exactly 63 bits
-//   - Have positive values for all components
-//   - Have reasonable ranges (to prevent overflow/underflow)
+//   - Have no negative components and 1-62 timestamp bits
 //   - Have a positive time unit
+//   - Keep each component in its standard range, unless AllowNonStandard is set
 //
 // Returns an error describing the specific validation failure.
 //
@@ -305,19 +322,12 @@ func (l BitLayout) Validate() error {
 		return fmt.Errorf("%w: total bits must equal 63, got %d (%d+%d+%d)",
 			ErrInvalidBitLayout, totalBits, l.TimestampBits, l.WorkerBits, l.SequenceBits)
 	}
-
-	// Check reasonable ranges to prevent practical issues
-	if l.TimestampBits < 38 || l.TimestampBits > 42 {
-		return fmt.Errorf("%w: timestamp bits should be 38-42 for reasonable lifespan, got %d",
-			ErrInvalidBitLayout, l.TimestampBits)
-	}
-	if l.WorkerBits < 8 || l.WorkerBits > 18 {
-		return fmt.Errorf("%w: worker bits should be 8-18 for practical deployment, got %d",
-			ErrInvalidBitLayout, l.WorkerBits)
+	if l.TimestampBits == 0 {
+		return fmt.Errorf("%w: timestamp needs at least one bit", ErrInvalidBitLayout)
 	}
-	if l.SequenceBits < 6 || l.SequenceBits > 14 {
-		return fmt.Errorf("%w: sequence bits should be 6-14 for reasonable throughput, got %d",
-			ErrInvalidBitLayout, l.SequenceBits)
+	if l.TimestampBits > 62 {
+		// Epoch plus maximum timestamp must fit in int64 time units
+		return fmt.Errorf("%w: timestamp bits cannot exceed 62, got %d", ErrInvalidBitLayout, l.TimestampBits)
 	}
 
 	// Check time unit
@@ -325,18 +335,53 @@ func (l BitLayout) Validate() error {
 		return fmt.Errorf("%w: time unit must be positive, got %v", ErrInvalidBitLayout, l.TimeUnit)
 	}
 
+	// Check reasonable ranges to prevent practical issues
+	if !l.AllowNonStandard {
+		if warnings := l.Lint(); len(warnings) > 0 {
+			return fmt.Errorf("%w: %s (set AllowNonStandard to permit)", ErrInvalidBitLayout, warnings[0])
+		}
+	}
+
 	return nil
 }
 
+// Lint returns advisory warnings for components outside their standard
+// ranges, whether or not AllowNonStandard is set. Validate rejects a standard
+// layout with warnings; a non-standard one may have any number.
+//
+// Example:
+//
+//	l := snowflake.BitLayout{TimestampBits: 38, WorkerBits: 20, SequenceBits: 5,
+//	    TimeUnit: 10 * time.Millisecond, AllowNonStandard: true}
+//	for _, w := range l.Lint() {
+//	    log.Println(w) // worker bits should be 8-18 for practical deployment, got 20
+//	}
+func (l BitLayout) Lint() []string {
+	var warnings []string
+	if l.TimestampBits < 38 || l.TimestampBits > 42 {
+		warnings = append(warnings, fmt.Sprintf("timestamp bits should be 38-42 for reasonable lifespan, got %d",
+			l.TimestampBits))
+	}
+	if l.WorkerBits < 8 || l.WorkerBits > 18 {
+		warnings = append(warnings, fmt.Sprintf("worker bits should be 8-18 for practical deployment, got %d",
+			l.WorkerBits))
+	}
+	if l.SequenceBits < 6 || l.SequenceBits > 14 {
+		warnings = append(warnings, fmt.Sprintf("sequence bits should be 6-14 for reasonable throughput, got %d",
+			l.SequenceBits))
+	}
+	return warnings
+}
+
 // CalculateCapacity returns the theoretical capacity of this layout.
 //
 // This provides useful information for capacity planning and deployment decisions.
 //
 // Performance: ~20ns (simple arithmetic)
 func (l BitLayout) CalculateCapacity() LayoutCapacity {
-	maxWorkers := int64(1 << l.WorkerBits)
-	maxSequence := int64(1 << l.SequenceBits)
-	maxTimestamp := int64(1 << l.TimestampBits)
+	maxWorkers := int64(1) << l.WorkerBits
+	maxSequence := int64(1) << l.SequenceBits
+	maxTimestamp := int64(1) << l.TimestampBits
 
 	// Calculate lifespan - use float64 to avoid overflow for large values
 	// (40 bits * 10ms would overflow int64 when multiplied in nanoseconds)
@@ -353,12 +398,19 @@ func (l BitLayout) CalculateCapacity() LayoutCapacity {
 	}
 	lifespan := time.Duration(int64(totalNanoseconds))
 
-	// Calculate throughput per worker
+	// Calculate throughput per worker, saturating for non-standard layouts
+	// with very wide sequence fields
 	idsPerTimeUnit := maxSequence
-	throughputPerWorker := int64(float64(idsPerTimeUnit) / l.TimeUnit.Seconds())
+	throughputPerWorker := int64(math.MaxInt64)
+	if perSecond := float64(idsPerTimeUnit) / l.TimeUnit.Seconds(); perSecond < math.MaxInt64 {
+		throughputPerWorker = int64(perSecond)
+	}
 
-	// Calculate total system capacity
-	totalThroughput := throughputPerWorker * maxWorkers
+	// Calculate total system capacity, saturating likewise
+	totalThroughput := int64(math.MaxInt64)
+	if throughputPerWorker <= math.MaxInt64/maxWorkers {
+		totalThroughput = throughputPerWorker * maxWorkers
+	}
 
 	return LayoutCapacity{
 		MaxWorkers:          maxWorkers,
@@ -417,6 +469,7 @@ func (l BitLayout) ValidateWorkerID(workerID int64) error {
 //   - 4ms → shift 2 (>>2)
 //   - 8ms → shift 3 (>>3)
 //   - 10ms → -1 (use division, not power-of-2)
+//   - 100µs, 1.5ms → -1 (not a whole number of milliseconds)
 //
 // Performance: ~5ns (integer arithmetic)
 //
@@ -426,7 +479,7 @@ func (l BitLayout) ValidateWorkerID(workerID int64) error {
 //	if shift >= 0 {
 //	    timeUnits = milliseconds >> shift  // Fast bitshift
 //	} else {
-//	    timeUnits = milliseconds / layout.TimeUnit.Milliseconds()  // Fallback
+//	    timeUnits = nanoseconds / int64(layout.TimeUnit)  // Fallback
 //	}
 func (l BitLayout) TimeUnitShift() int8 {
 	return calculateTimeUnitShift(l.TimeUnit)
@@ -434,11 +487,15 @@ func (l BitLayout) TimeUnitShift() int8 {
 
 // calculateTimeUnitShift computes the right-shift amount for a time unit.
 //
-// For power-of-2 time units, this returns the shift amount. For non-power-of-2,
-// returns -1 to indicate division should be used instead.
+// For power-of-2 millisecond time units, this returns the shift amount. For
+// anything else, including sub-millisecond units, returns -1 to indicate
+// division should be used instead.
 //
 // Performance: ~10ns (bit manipulation)
 func calculateTimeUnitShift(timeUnit time.Duration) int8 {
+	if timeUnit%time.Millisecond != 0 {
+		return -1 // Fractional milliseconds would be truncated
+	}
 	ms := timeUnit.Milliseconds()
 
 	// Check if power of 2 using bit manipulation
@@ -503,3 +560,187 @@ func (c LayoutCapacity) String() string {
 	return fmt.Sprintf("MaxWorkers: %d, ThroughputPerWorker: %d/sec, Lifespan: %d years, TimeUnit: %v",
 		c.MaxWorkers, c.ThroughputPerWorker, years, c.TimeUnit)
 }
+
+// ============================================================================
+// Named layouts
+// ============================================================================
+
+// layoutRegistry maps lower-case names to layouts for ParseBitLayout and the CLI.
+var layoutRegistry = struct {
+	sync.RWMutex
+	layouts map[string]BitLayout
+}{layouts: map[string]BitLayout{
+	"default":   LayoutDefault,
+	"superior":  LayoutSuperior,
+	"extreme":   LayoutExtreme,
+	"ultra":     LayoutUltra,
+	"longlife":  LayoutLongLife,
+	"sonyflake": LayoutSonyflake,
+	"ultimate":  LayoutUltimate,
+	"megascale": LayoutMegaScale,
+}}
+
+// RegisterLayout makes a layout available by name to ParseBitLayout,
+// LookupLayout and configuration loading. Names are case-insensitive and may
+// not contain '/', '@' or spaces.
+//
+// The predefined layouts are registered as "default", "superior", "extreme",
+// "ultra", "longlife", "sonyflake", "ultimate" and "megascale". Registering a
+// name twice is an error, so a name always means the same layout within a
+// process; register at init time.
+//
+// Thread-safe: Yes
+//
+// Example:
+//
+//	func init() {
+//	    snowflake.RegisterLayout("events", snowflake.BitLayout{
+//	        TimestampBits: 39, WorkerBits: 12, SequenceBits: 12, TimeUnit: time.Millisecond,
+//	    })
+//	}
+func RegisterLayout(name string, l BitLayout) error {
+	key := strings.ToLower(name)
+	if key == "" || strings.ContainsAny(key, "/@ \t\n") {
+		return fmt.Errorf("%w: invalid layout name %q", ErrInvalidBitLayout, name)
+	}
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	layoutRegistry.Lock()
+	defer layoutRegistry.Unlock()
+	if existing, ok := layoutRegistry.layouts[key]; ok {
+		return fmt.Errorf("%w: layout name %q already registered as %v", ErrInvalidBitLayout, name, existing)
+	}
+	layoutRegistry.layouts[key] = l
+	return nil
+}
+
+// LookupLayout returns the layout registered under name, ignoring case.
+func LookupLayout(name string) (BitLayout, bool) {
+	layoutRegistry.RLock()
+	defer layoutRegistry.RUnlock()
+	l, ok := layoutRegistry.layouts[strings.ToLower(name)]
+	return l, ok
+}
+
+// LayoutNames returns the registered layout names in sorted order.
+func LayoutNames() []string {
+	layoutRegistry.RLock()
+	defer layoutRegistry.RUnlock()
+	names := make([]string, 0, len(layoutRegistry.layouts))
+	for name := range layoutRegistry.layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ============================================================================
+// Text form
+// ============================================================================
+
+// customSpecPrefix marks specs of layouts with AllowNonStandard set.
+const customSpecPrefix = "custom:"
+
+// String returns the canonical spec "timestamp/worker/sequence@unit", e.g.
+// "41/10/12@1ms", which ParseBitLayout reads back. Layouts with
+// AllowNonStandard set are prefixed with "custom:".
+func (l BitLayout) String() string {
+	spec := fmt.Sprintf("%d/%d/%d@%v", l.TimestampBits, l.WorkerBits, l.SequenceBits, l.TimeUnit)
+	if l.AllowNonStandard {
+		return customSpecPrefix + spec
+	}
+	return spec
+}
+
+// MarshalText implements encoding.TextMarshaler, so layouts encode as their
+// spec string in JSON, YAML and flags.
+func (l BitLayout) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// ParseBitLayout parses a layout from a preset name or a bit spec.
+//
+// Names are looked up with LookupLayout: the predefined layouts without the
+// "Layout" prefix ("superior", "LongLife") and any added with RegisterLayout.
+// A spec gives the timestamp, worker and sequence bits, optionally followed
+// by "@" and the time unit (default 1ms): "41/10/12", "39/15/9@10ms". A
+// "custom:" prefix sets AllowNonStandard: "custom:38/20/5@10ms".
+//
+// The result is validated; errors wrap ErrInvalidBitLayout.
+//
+// Example:
+//
+//	layout, err := snowflake.ParseBitLayout("superior")
+//	layout, err := snowflake.ParseBitLayout("40/14/9@1ms")
+func ParseBitLayout(s string) (BitLayout, error) {
+	s = strings.TrimSpace(s)
+	if named, ok := LookupLayout(s); ok {
+		return named, nil
+	}
+
+	l := BitLayout{TimeUnit: time.Millisecond}
+	rest, custom := strings.CutPrefix(s, customSpecPrefix)
+	l.AllowNonStandard = custom
+
+	spec, unit, hasUnit := strings.Cut(rest, "@")
+	if hasUnit {
+		d, err := time.ParseDuration(strings.TrimSpace(unit))
+		if err != nil {
+			return BitLayout{}, fmt.Errorf("%w: bad time unit in %q: %v", ErrInvalidBitLayout, s, err)
+		}
+		l.TimeUnit = d
+	}
+
+	parts := strings.Split(spec, "/")
+	if len(parts) != 3 {
+		return BitLayout{}, fmt.Errorf("%w: %q is neither a preset name nor a timestamp/worker/sequence spec like 41/10/12@1ms",
+			ErrInvalidBitLayout, s)
+	}
+	bits := [3]*int{&l.TimestampBits, &l.WorkerBits, &l.SequenceBits}
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return BitLayout{}, fmt.Errorf("%w: bad bit count %q in %q", ErrInvalidBitLayout, part, s)
+		}
+		*bits[i] = n
+	}
+
+	if err := l.Validate(); err != nil {
+		return BitLayout{}, err
+	}
+	return l, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseBitLayout, so
+// layouts can be read from JSON strings, flags and configuration files.
+func (l *BitLayout) UnmarshalText(text []byte) error {
+	parsed, err := ParseBitLayout(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// UnmarshalJSON accepts a string for ParseBitLayout, or an object with the
+// BitLayout fields as encoding/json decodes structs by default (TimeUnit in
+// nanoseconds), so previously stored layouts keep decoding.
+func (l *BitLayout) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return l.UnmarshalText([]byte(s))
+	}
+
+	type plain BitLayout // Without methods, to decode as a struct
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*l = BitLayout(p)
+	return nil
+}
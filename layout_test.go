@@ -1,7 +1,9 @@
 package snowflake
 
 import (
+	"encoding/json"
 	"errors"
+	"math"
 	"testing"
 	"time"
 )
@@ -868,3 +870,240 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// ============================================================================
+// ParseBitLayout() Tests
+// ============================================================================
+
+func TestParseBitLayout(t *testing.T) {
+	tests := []struct {
+		in   string
+		want BitLayout
+	}{
+		{"superior", LayoutSuperior},
+		{" Sonyflake ", LayoutSonyflake},
+		{"41/10/12", LayoutDefault},
+		{"41/10/12@1ms", LayoutDefault},
+		{"39/16/8@10ms", BitLayout{TimestampBits: 39, WorkerBits: 16, SequenceBits: 8, TimeUnit: 10 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		got, err := ParseBitLayout(tt.in)
+		if err != nil {
+			t.Errorf("ParseBitLayout(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBitLayout(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "nope", "41/10", "41/10/x", "41/10/11", "41/10/12@soon"} {
+		if _, err := ParseBitLayout(in); !errors.Is(err, ErrInvalidBitLayout) {
+			t.Errorf("ParseBitLayout(%q) error = %v, want ErrInvalidBitLayout", in, err)
+		}
+	}
+}
+
+func TestBitLayout_UnmarshalJSON(t *testing.T) {
+	var l BitLayout
+	if err := json.Unmarshal([]byte(`"40/14/9@1ms"`), &l); err != nil || l != LayoutSuperior {
+		t.Errorf("Unmarshal(string) = %+v, %v; want LayoutSuperior", l, err)
+	}
+
+	// Struct form, as encoding/json wrote BitLayout before it had methods
+	data, _ := json.Marshal(struct {
+		TimestampBits, WorkerBits, SequenceBits int
+		TimeUnit                                time.Duration
+	}{41, 10, 12, time.Millisecond})
+	l = BitLayout{}
+	if err := json.Unmarshal(data, &l); err != nil || l != LayoutDefault {
+		t.Errorf("Unmarshal(%s) = %+v, %v; want LayoutDefault", data, l, err)
+	}
+
+	if err := json.Unmarshal([]byte(`"41/10/13"`), &l); !errors.Is(err, ErrInvalidBitLayout) {
+		t.Errorf("Unmarshal(invalid) error = %v, want ErrInvalidBitLayout", err)
+	}
+}
+
+func TestBitLayout_String_RoundTrip(t *testing.T) {
+	for _, name := range LayoutNames() {
+		l, _ := LookupLayout(name)
+		got, err := ParseBitLayout(l.String())
+		if err != nil || got != l {
+			t.Errorf("ParseBitLayout(%q) = %+v, %v; want %s", l.String(), got, err, name)
+		}
+	}
+	if got := LayoutSuperior.String(); got != "40/14/9@1ms" {
+		t.Errorf("LayoutSuperior.String() = %q, want 40/14/9@1ms", got)
+	}
+
+	data, err := json.Marshal(struct{ Layout BitLayout }{LayoutUltra})
+	if err != nil || string(data) != `{"Layout":"39/15/9@1ms"}` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+	var out struct{ Layout BitLayout }
+	if err := json.Unmarshal(data, &out); err != nil || out.Layout != LayoutUltra {
+		t.Errorf("Unmarshal(%s) = %+v, %v", data, out, err)
+	}
+}
+
+func TestRegisterLayout(t *testing.T) {
+	custom := BitLayout{TimestampBits: 39, WorkerBits: 12, SequenceBits: 12, TimeUnit: time.Millisecond}
+	if err := RegisterLayout("Test-Events", custom); err != nil {
+		t.Fatalf("RegisterLayout() error = %v", err)
+	}
+	if l, ok := LookupLayout("test-events"); !ok || l != custom {
+		t.Errorf("LookupLayout() = %+v, %v", l, ok)
+	}
+	if l, err := ParseBitLayout("TEST-EVENTS"); err != nil || l != custom {
+		t.Errorf("ParseBitLayout() = %+v, %v", l, err)
+	}
+
+	found := false
+	for _, name := range LayoutNames() {
+		found = found || name == "test-events"
+	}
+	if !found {
+		t.Errorf("LayoutNames() = %v, missing test-events", LayoutNames())
+	}
+
+	bad := []struct {
+		name   string
+		layout BitLayout
+	}{
+		{"test-events", custom},   // Duplicate
+		{"superior", custom},      // Predefined
+		{"", custom},              // Empty
+		{"41/10/12", custom},      // Spec-like
+		{"test-bad", BitLayout{}}, // Invalid layout
+	}
+	for _, tt := range bad {
+		if err := RegisterLayout(tt.name, tt.layout); !errors.Is(err, ErrInvalidBitLayout) {
+			t.Errorf("RegisterLayout(%q) error = %v, want ErrInvalidBitLayout", tt.name, err)
+		}
+	}
+}
+
+// ============================================================================
+// AllowNonStandard and Lint() Tests
+// ============================================================================
+
+func TestBitLayout_AllowNonStandard(t *testing.T) {
+	fleet := BitLayout{TimestampBits: 38, WorkerBits: 20, SequenceBits: 5, TimeUnit: 10 * time.Millisecond}
+	if err := fleet.Validate(); !errors.Is(err, ErrInvalidBitLayout) {
+		t.Errorf("Validate() without AllowNonStandard = %v, want ErrInvalidBitLayout", err)
+	}
+
+	fleet.AllowNonStandard = true
+	if err := fleet.Validate(); err != nil {
+		t.Fatalf("Validate() with AllowNonStandard = %v", err)
+	}
+	if warnings := fleet.Lint(); len(warnings) != 2 {
+		t.Errorf("Lint() = %q, want worker and sequence warnings", warnings)
+	}
+	if warnings := LayoutDefault.Lint(); len(warnings) != 0 {
+		t.Errorf("LayoutDefault.Lint() = %q, want none", warnings)
+	}
+
+	// Hard invariants still apply
+	hard := []BitLayout{
+		{TimestampBits: 40, WorkerBits: 20, SequenceBits: 5, TimeUnit: time.Millisecond, AllowNonStandard: true},
+		{TimestampBits: 0, WorkerBits: 50, SequenceBits: 13, TimeUnit: time.Millisecond, AllowNonStandard: true},
+		{TimestampBits: 64, WorkerBits: -1, SequenceBits: 0, TimeUnit: time.Millisecond, AllowNonStandard: true},
+		{TimestampBits: 38, WorkerBits: 20, SequenceBits: 5, AllowNonStandard: true},
+	}
+	for _, l := range hard {
+		if err := l.Validate(); !errors.Is(err, ErrInvalidBitLayout) {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalidBitLayout", l, err)
+		}
+	}
+
+	// The spec form carries the opt-in
+	if got := fleet.String(); got != "custom:38/20/5@10ms" {
+		t.Errorf("String() = %q, want custom:38/20/5@10ms", got)
+	}
+	if got, err := ParseBitLayout(fleet.String()); err != nil || got != fleet {
+		t.Errorf("ParseBitLayout(%q) = %+v, %v", fleet.String(), got, err)
+	}
+	if _, err := ParseBitLayout("38/20/5@10ms"); !errors.Is(err, ErrInvalidBitLayout) {
+		t.Errorf("ParseBitLayout(without prefix) error = %v, want ErrInvalidBitLayout", err)
+	}
+}
+
+func TestBitLayout_AllowNonStandard_Generate(t *testing.T) {
+	fleet := BitLayout{TimestampBits: 38, WorkerBits: 20, SequenceBits: 5,
+		TimeUnit: 10 * time.Millisecond, AllowNonStandard: true}
+	cfg := DefaultConfig(1_000_000)
+	cfg.Layout = fleet
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	seen := make(map[ID]bool)
+	var last ID
+	for i := 0; i < 100; i++ {
+		id, err := gen.GenerateID()
+		if err != nil {
+			t.Fatalf("GenerateID() error = %v", err)
+		}
+		if seen[id] || id <= last {
+			t.Fatalf("ID %d repeated or out of order after %d", id, last)
+		}
+		seen[id], last = true, id
+	}
+	if _, worker, _ := gen.Scheme().Components(last); worker != 1_000_000 {
+		t.Errorf("Components() worker = %d, want 1000000", worker)
+	}
+}
+
+func TestBitLayout_AllowNonStandard_Extremes(t *testing.T) {
+	// 63 timestamp bits would overflow epoch + timestamp in int64
+	all := BitLayout{TimestampBits: 63, TimeUnit: time.Millisecond, AllowNonStandard: true}
+	if err := all.Validate(); !errors.Is(err, ErrInvalidBitLayout) {
+		t.Errorf("Validate(63/0/0) = %v, want ErrInvalidBitLayout", err)
+	}
+	if _, err := ParseBitLayout("custom:63/0/0@1ms"); !errors.Is(err, ErrInvalidBitLayout) {
+		t.Errorf("ParseBitLayout(custom:63/0/0@1ms) error = %v, want ErrInvalidBitLayout", err)
+	}
+
+	extremes := []BitLayout{
+		{TimestampBits: 62, WorkerBits: 1, SequenceBits: 0, TimeUnit: time.Millisecond, AllowNonStandard: true},
+		{TimestampBits: 62, WorkerBits: 0, SequenceBits: 1, TimeUnit: time.Nanosecond, AllowNonStandard: true},
+		{TimestampBits: 1, WorkerBits: 62, SequenceBits: 0, TimeUnit: time.Millisecond, AllowNonStandard: true},
+		{TimestampBits: 1, WorkerBits: 0, SequenceBits: 62, TimeUnit: time.Nanosecond, AllowNonStandard: true},
+		{TimestampBits: 1, WorkerBits: 31, SequenceBits: 31, TimeUnit: time.Nanosecond, AllowNonStandard: true},
+	}
+	for _, l := range extremes {
+		if err := l.Validate(); err != nil {
+			t.Errorf("Validate(%v) = %v", l, err)
+			continue
+		}
+		c := l.CalculateCapacity()
+		if c.Lifespan <= 0 || c.MaxWorkers <= 0 || c.MaxSequence <= 0 || c.MaxTimestamp <= 0 ||
+			c.ThroughputPerWorker <= 0 || c.TotalThroughput < c.ThroughputPerWorker {
+			t.Errorf("CalculateCapacity(%v) = %+v, want positive values", l, c)
+		}
+
+		cfg := DefaultConfig(0)
+		cfg.Layout = l
+		gen, err := NewWithConfig(cfg)
+		if err != nil {
+			t.Errorf("NewWithConfig(%v) error = %v", l, err)
+			continue
+		}
+		info := gen.LifespanInfo()
+		if info.OverflowDate.Before(time.UnixMilli(Epoch)) || info.TotalLifespan <= 0 || info.Remaining < 0 {
+			t.Errorf("LifespanInfo(%v) = %+v, want an overflow date after the epoch", l, info)
+		}
+	}
+
+	// 62 timestamp bits of 1ms outlast time.Duration
+	wide := extremes[0].CalculateCapacity()
+	if wide.Lifespan < 292*365*24*time.Hour {
+		t.Errorf("CalculateCapacity(62/1/0).Lifespan = %v, want saturated near 292 years", wide.Lifespan)
+	}
+	if narrow := extremes[3].CalculateCapacity(); narrow.ThroughputPerWorker != math.MaxInt64 {
+		t.Errorf("CalculateCapacity(1/0/62@1ns).ThroughputPerWorker = %d, want saturated", narrow.ThroughputPerWorker)
+	}
+}
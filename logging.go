@@ -0,0 +1,117 @@
+// Package snowflake - logging.go integrates IDs with log/slog and fmt.
+//
+// IDs log as a group of their decimal value and decoded components, so log
+// pipelines can filter by worker or time without decoding IDs themselves.
+// Decoding uses DefaultScheme unless a Scheme is given.
+
+package snowflake
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// LogValue implements slog.LogValuer. The ID is logged as a group of its
+// decimal value (as a string, like MarshalJSON), time, worker and sequence,
+// decoded with DefaultScheme. Use Scheme.LogValue for other layouts or epochs.
+//
+// Example:
+//
+//	slog.Info("order created", "order_id", id)
+//	// JSON handler:
+//	// {"msg":"order created","order_id":{"id":"1234567890123456789",
+//	//   "time":"2024-03-05T10:20:30.123Z","worker":42,"sequence":7}}
+func (id ID) LogValue() slog.Value {
+	return DefaultScheme.LogValue(id)
+}
+
+// LogValue returns the slog group ID.LogValue logs, decoded with this scheme.
+//
+// Example:
+//
+//	scheme := gen.Scheme()
+//	logger.Info("order created", "order_id", scheme.LogValue(id))
+func (s Scheme) LogValue(id ID) slog.Value {
+	_, worker, seq := s.Components(id)
+	return slog.GroupValue(
+		slog.String("id", id.String()),
+		slog.Time("time", s.Time(id).UTC()),
+		slog.Int64("worker", worker),
+		slog.Int64("sequence", seq),
+	)
+}
+
+// FmtID formats an ID with fmt verbs. Obtain one with ID.Fmt or Scheme.Fmt.
+//
+// ID cannot implement fmt.Formatter itself because ID.Format(string) predates
+// it, and as a fmt.Stringer a bare ID prints %x and %X as the hex bytes of its
+// decimal string. FmtID formats:
+//
+//	%v %s %d  decimal
+//	%x %X     hexadecimal (lower/upper case)
+//	%b %o     binary, octal
+//	%q        quoted decimal
+//	%+v       decimal with components: 1234 (time=2024-03-05T10:20:30.123Z worker=42 seq=7)
+//	%#v       snowflake.ID(1234)
+//
+// Width, padding and '#' flags apply to the integer verbs as for int64.
+type FmtID struct {
+	id     ID
+	scheme Scheme
+}
+
+// Fmt returns the ID wrapped for fmt verbs, decoded with DefaultScheme for %+v.
+//
+// Example:
+//
+//	fmt.Printf("%x\n", id.Fmt())  // 112210f47de98115
+//	fmt.Printf("%+v\n", id.Fmt()) // 1234567890123456789 (time=... worker=42 seq=7)
+func (id ID) Fmt() FmtID {
+	return FmtID{id: id, scheme: DefaultScheme}
+}
+
+// Fmt returns id wrapped for fmt verbs, decoded with this scheme for %+v.
+func (s Scheme) Fmt(id ID) FmtID {
+	return FmtID{id: id, scheme: s}
+}
+
+// ID returns the wrapped ID.
+func (f FmtID) ID() ID {
+	return f.id
+}
+
+// String returns the decimal form of the ID.
+func (f FmtID) String() string {
+	return f.id.String()
+}
+
+// Format implements fmt.Formatter.
+func (f FmtID) Format(st fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case st.Flag('+'):
+			_, worker, seq := f.scheme.Components(f.id)
+			layout := "2006-01-02T15:04:05.000Z07:00"
+			if f.scheme.Layout.TimeUnit%time.Millisecond != 0 {
+				layout = "2006-01-02T15:04:05.000000Z07:00" // Sub-millisecond units
+			}
+			fmt.Fprintf(st, "%d (time=%s worker=%d seq=%d)", int64(f.id),
+				f.scheme.Time(f.id).UTC().Format(layout), worker, seq)
+		case st.Flag('#'):
+			fmt.Fprintf(st, "snowflake.ID(%d)", int64(f.id))
+		default:
+			fmt.Fprintf(st, fmt.FormatString(st, 'd'), int64(f.id))
+		}
+	case 's':
+		fmt.Fprintf(st, fmt.FormatString(st, 'd'), int64(f.id))
+	case 'q':
+		fmt.Fprint(st, strconv.Quote(f.id.String()))
+	case 'd', 'x', 'X', 'b', 'o', 'O':
+		fmt.Fprintf(st, fmt.FormatString(st, verb), int64(f.id))
+	default:
+		fmt.Fprintf(st, "%%!%c(snowflake.ID=%d)", verb, int64(f.id))
+	}
+}
@@ -0,0 +1,105 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestID_LogValue(t *testing.T) {
+	at := time.Date(2025, 3, 5, 10, 20, 30, 123e6, time.UTC)
+	id := ID((at.UnixMilli()-Epoch)<<TimestampShift | 42<<WorkerIDShift | 7)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("created", "order_id", id)
+
+	var rec struct {
+		OrderID struct {
+			ID       string    `json:"id"`
+			Time     time.Time `json:"time"`
+			Worker   int64     `json:"worker"`
+			Sequence int64     `json:"sequence"`
+		} `json:"order_id"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("log output is not JSON: %v\n%s", err, buf.String())
+	}
+	got := rec.OrderID
+	if got.ID != id.String() || !got.Time.Equal(at) || got.Worker != 42 || got.Sequence != 7 {
+		t.Errorf("logged %+v, want id %s at %v worker 42 seq 7", got, id, at)
+	}
+}
+
+func TestScheme_LogValue(t *testing.T) {
+	scheme := Scheme{Layout: LayoutSuperior, Epoch: Epoch}
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	id := scheme.MinIDForTime(at) | ID(1000)<<LayoutSuperior.SequenceBits | 3
+
+	attrs := scheme.LogValue(id).Group()
+	want := map[string]string{"id": id.String(), "time": at.String(), "worker": "1000", "sequence": "3"}
+	for _, a := range attrs {
+		if a.Value.String() != want[a.Key] {
+			t.Errorf("%s = %s, want %s", a.Key, a.Value, want[a.Key])
+		}
+	}
+}
+
+func TestFmtID(t *testing.T) {
+	id := ID(1234567890123456789)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "1234567890123456789"},
+		{"%s", "1234567890123456789"},
+		{"%d", "1234567890123456789"},
+		{"%x", "112210f47de98115"},
+		{"%X", "112210F47DE98115"},
+		{"%#x", "0x112210f47de98115"},
+		{"%b", fmt.Sprintf("%b", int64(id))},
+		{"%q", `"1234567890123456789"`},
+		{"%#v", "snowflake.ID(1234567890123456789)"},
+		{"%22d", "   1234567890123456789"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, id.Fmt()); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	_, worker, seq := id.Components()
+	got := fmt.Sprintf("%+v", id.Fmt())
+	want := fmt.Sprintf("worker=%d seq=%d)", worker, seq)
+	if !strings.HasPrefix(got, "1234567890123456789 (time=") || !strings.HasSuffix(got, want) {
+		t.Errorf("Sprintf(%%+v) = %q, want decimal with components ending %q", got, want)
+	}
+}
+
+func TestConfig_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := DefaultConfig(1)
+	cfg.Name = "orders"
+	cfg.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	// Recoverable drift is logged at Warn
+	gen.mu.Lock()
+	gen.lastTimestamp = gen.currentTimestamp() + 2
+	gen.lastClock = gen.lastTimestamp
+	gen.mu.Unlock()
+	gen.MustGenerateID()
+
+	out := buf.String()
+	for _, want := range []string{"level=WARN", "clock moved backwards", "generator=orders", "worker_id=1", "recovered=true"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output missing %q:\n%s", want, out)
+		}
+	}
+}
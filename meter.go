@@ -0,0 +1,152 @@
+// Package snowflake - meter.go registers generator metrics with callback-based
+// metric APIs such as OpenTelemetry's asynchronous instruments.
+//
+// The core package does not import OpenTelemetry. Instead, AsyncMeter mirrors
+// the shape of its observable counters and gauges, and a few lines of glue in
+// the application adapt a real meter to it (see RegisterMetrics).
+
+package snowflake
+
+import (
+	"context"
+	"strconv"
+)
+
+// Attribute is a key/value pair attached to an observation, equivalent to an
+// OpenTelemetry attribute.KeyValue with a string value.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Int64Observe records one int64 observation with its attributes.
+type Int64Observe func(value int64, attrs ...Attribute)
+
+// Float64Observe records one float64 observation with its attributes.
+type Float64Observe func(value float64, attrs ...Attribute)
+
+// Int64Callback reports the current value of an int64 instrument when the
+// meter collects, like an OpenTelemetry metric.Int64Callback.
+type Int64Callback func(ctx context.Context, observe Int64Observe) error
+
+// Float64Callback reports the current value of a float64 instrument when the
+// meter collects, like an OpenTelemetry metric.Float64Callback.
+type Float64Callback func(ctx context.Context, observe Float64Observe) error
+
+// AsyncMeter creates asynchronous (callback) instruments. It is the subset of
+// an OpenTelemetry metric.Meter that RegisterMetrics needs.
+//
+// Counters are monotonic sums reported as cumulative totals; gauges report the
+// current value. unit follows UCUM as in OpenTelemetry ("s", "us", "1", "{id}").
+type AsyncMeter interface {
+	Int64ObservableCounter(name, description, unit string, callback Int64Callback) error
+	Float64ObservableGauge(name, description, unit string, callback Float64Callback) error
+}
+
+// RegisterMetrics registers instruments reporting the Metrics counters and
+// lifespan of gens with meter.
+//
+// Registered instruments (observations carry snowflake.name and
+// snowflake.worker_id attributes):
+//
+//	snowflake.ids.generated             counter {id}
+//	snowflake.clock_backward            counter {event}
+//	snowflake.clock_backward.errors     counter {event}
+//	snowflake.sequence_overflow         counter {event}
+//	snowflake.borrowed                  counter {time_unit}
+//	snowflake.wait_time                 counter us
+//	snowflake.clock.reanchors           counter {event}
+//	snowflake.borrow.lead               gauge   s
+//	snowflake.clock.offset              gauge   s
+//	snowflake.lifespan.utilization      gauge   1
+//	snowflake.lifespan.remaining        gauge   s
+//
+// It stops at and returns the first registration error.
+//
+// Example (bridging an OpenTelemetry meter):
+//
+//	type otelMeter struct{ m metric.Meter }
+//
+//	func (o otelMeter) Int64ObservableCounter(name, desc, unit string, cb snowflake.Int64Callback) error {
+//	    _, err := o.m.Int64ObservableCounter(name, metric.WithDescription(desc), metric.WithUnit(unit),
+//	        metric.WithInt64Callback(func(ctx context.Context, obs metric.Int64Observer) error {
+//	            return cb(ctx, func(v int64, attrs ...snowflake.Attribute) {
+//	                obs.Observe(v, metric.WithAttributes(toOtel(attrs)...))
+//	            })
+//	        }))
+//	    return err
+//	}
+//
+//	// Float64ObservableGauge likewise
+//
+//	err := snowflake.RegisterMetrics(otelMeter{otel.Meter("ids")}, gen)
+func RegisterMetrics(meter AsyncMeter, gens ...*Generator) error {
+	attrs := make([][]Attribute, len(gens))
+	for i, g := range gens {
+		attrs[i] = []Attribute{
+			{Key: "snowflake.name", Value: g.Name()},
+			{Key: "snowflake.worker_id", Value: strconv.FormatInt(g.WorkerID(), 10)},
+		}
+	}
+
+	counters := []struct {
+		name, description, unit string
+		value                   func(m Metrics) int64
+	}{
+		{"snowflake.ids.generated", "IDs generated", "{id}",
+			func(m Metrics) int64 { return m.Generated }},
+		{"snowflake.clock_backward", "Clock backward events, including recovered ones", "{event}",
+			func(m Metrics) int64 { return m.ClockBackward }},
+		{"snowflake.clock_backward.errors", "Clock backward events that failed generation", "{event}",
+			func(m Metrics) int64 { return m.ClockBackwardErr }},
+		{"snowflake.sequence_overflow", "Sequence exhaustion events", "{event}",
+			func(m Metrics) int64 { return m.SequenceOverflow }},
+		{"snowflake.borrowed", "Time units borrowed ahead of the clock", "{time_unit}",
+			func(m Metrics) int64 { return m.Borrowed }},
+		{"snowflake.wait_time", "Time spent waiting for the clock", "us",
+			func(m Metrics) int64 { return m.WaitTimeUs }},
+		{"snowflake.clock.reanchors", "Clock re-anchors by a DriftMonitor", "{event}",
+			func(m Metrics) int64 { return m.Reanchors }},
+	}
+	gauges := []struct {
+		name, description, unit string
+		value                   func(g *Generator) float64
+	}{
+		{"snowflake.borrow.lead", "Lead of borrowed timestamps over the clock", "s",
+			func(g *Generator) float64 { return float64(g.GetMetrics().LeadUs) / 1e6 }},
+		{"snowflake.clock.offset", "Wall clock minus generator clock at the last DriftMonitor check", "s",
+			func(g *Generator) float64 { return float64(g.GetMetrics().ClockOffsetUs) / 1e6 }},
+		{"snowflake.lifespan.utilization", "Fraction of the timestamp range used", "1",
+			func(g *Generator) float64 { return g.LifespanInfo().Utilization }},
+		{"snowflake.lifespan.remaining", "Time until the timestamp field overflows", "s",
+			func(g *Generator) float64 { return g.LifespanInfo().Remaining.Seconds() }},
+	}
+
+	for _, c := range counters {
+		value := c.value
+		err := meter.Int64ObservableCounter(c.name, c.description, c.unit,
+			func(_ context.Context, observe Int64Observe) error {
+				for i, g := range gens {
+					observe(value(g.GetMetrics()), attrs[i]...)
+				}
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+	}
+	for _, gg := range gauges {
+		value := gg.value
+		err := meter.Float64ObservableGauge(gg.name, gg.description, gg.unit,
+			func(_ context.Context, observe Float64Observe) error {
+				for i, g := range gens {
+					observe(value(g), attrs[i]...)
+				}
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
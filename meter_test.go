@@ -0,0 +1,109 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeMeter records registered callbacks and collects them on demand.
+type fakeMeter struct {
+	units    map[string]string
+	counters map[string]Int64Callback
+	gauges   map[string]Float64Callback
+	failOn   string
+}
+
+func newFakeMeter() *fakeMeter {
+	return &fakeMeter{
+		units:    make(map[string]string),
+		counters: make(map[string]Int64Callback),
+		gauges:   make(map[string]Float64Callback),
+	}
+}
+
+func (f *fakeMeter) Int64ObservableCounter(name, _, unit string, cb Int64Callback) error {
+	if name == f.failOn {
+		return errors.New("duplicate instrument")
+	}
+	f.units[name], f.counters[name] = unit, cb
+	return nil
+}
+
+func (f *fakeMeter) Float64ObservableGauge(name, _, unit string, cb Float64Callback) error {
+	if name == f.failOn {
+		return errors.New("duplicate instrument")
+	}
+	f.units[name], f.gauges[name] = unit, cb
+	return nil
+}
+
+// collectInt64 returns the observations of a counter keyed by worker ID.
+func (f *fakeMeter) collectInt64(t *testing.T, name string) map[string]int64 {
+	t.Helper()
+	cb, ok := f.counters[name]
+	if !ok {
+		t.Fatalf("counter %s not registered", name)
+	}
+	got := make(map[string]int64)
+	err := cb(context.Background(), func(v int64, attrs ...Attribute) {
+		got[attrValue(attrs, "snowflake.worker_id")] = v
+	})
+	if err != nil {
+		t.Fatalf("callback %s error = %v", name, err)
+	}
+	return got
+}
+
+func attrValue(attrs []Attribute, key string) string {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	a, _ := New(1)
+	b, _ := New(2)
+	for i := 0; i < 4; i++ {
+		a.MustGenerateID()
+	}
+	b.MustGenerateID()
+
+	meter := newFakeMeter()
+	if err := RegisterMetrics(meter, a, b); err != nil {
+		t.Fatalf("RegisterMetrics() error = %v", err)
+	}
+
+	got := meter.collectInt64(t, "snowflake.ids.generated")
+	if got["1"] != 4 || got["2"] != 1 {
+		t.Errorf("snowflake.ids.generated = %v, want worker 1: 4, worker 2: 1", got)
+	}
+
+	// Callbacks read live values
+	a.MustGenerateID()
+	if got := meter.collectInt64(t, "snowflake.ids.generated"); got["1"] != 5 {
+		t.Errorf("after another ID, worker 1 = %d, want 5", got["1"])
+	}
+
+	var utilization float64
+	err := meter.gauges["snowflake.lifespan.utilization"](context.Background(), func(v float64, attrs ...Attribute) {
+		if attrValue(attrs, "snowflake.worker_id") == "1" {
+			utilization = v
+		}
+	})
+	if err != nil || utilization <= 0 || utilization >= 1 {
+		t.Errorf("snowflake.lifespan.utilization = %v (err %v), want within (0, 1)", utilization, err)
+	}
+	if meter.units["snowflake.lifespan.remaining"] != "s" {
+		t.Errorf("unit of snowflake.lifespan.remaining = %q, want s", meter.units["snowflake.lifespan.remaining"])
+	}
+
+	meter = newFakeMeter()
+	meter.failOn = "snowflake.sequence_overflow"
+	if err := RegisterMetrics(meter, a); err == nil {
+		t.Error("RegisterMetrics() error = nil, want the meter's registration error")
+	}
+}
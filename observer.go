@@ -0,0 +1,385 @@
+// Package snowflake - observer.go reports generator incidents as they happen.
+//
+// Metrics counts incidents; an Observer is told about each one, with its size
+// and outcome, so it can be logged, traced or alerted on. Events are recorded
+// while the generator holds its lock and delivered after the lock is
+// released, so a slow observer never blocks other callers.
+
+package snowflake
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Observer receives incident events from a Generator. Set it via Config.Observer.
+//
+// Callbacks are invoked on the goroutine whose call triggered the event, after
+// the generator's lock is released. Implementations shared by several
+// generators (for example across a Pool) must be safe for concurrent use; the
+// workerID argument tells the generators apart.
+//
+// Embed NopObserver to implement only some of the callbacks.
+type Observer interface {
+	// OnClockBackward is called when the clock is found behind the last
+	// timestamp used. recovered reports whether the drift was waited out
+	// (true) or generation failed with a *ClockError or cancellation (false).
+	OnClockBackward(workerID int64, drift time.Duration, recovered bool)
+
+	// OnSequenceOverflow is called when a time unit's sequences are exhausted.
+	// wait is how long the generator waited for the next time unit; it is 0
+	// when the time unit was borrowed ahead (Config.MaxBorrowAhead) or the
+	// wait was refused (TryGenerateID, Config.MaxWait).
+	OnSequenceOverflow(workerID int64, wait time.Duration)
+
+	// OnLifespanThreshold is called once per generator, the first time an ID
+	// is generated after TimestampWarningThreshold of the layout's lifespan
+	// has been used.
+	OnLifespanThreshold(workerID int64, info LifespanInfo)
+
+	// OnTimestampOverflow is called when the timestamp no longer fits the
+	// layout's timestamp field. Generation fails with err from then on.
+	OnTimestampOverflow(workerID int64, err *OverflowError)
+}
+
+// DriftObserver is implemented by Observers that also want clock drift events
+// from a DriftMonitor. It is separate from Observer so that existing Observer
+// implementations keep compiling; NopObserver, MultiObserver and the adapters
+// in this package implement it.
+type DriftObserver interface {
+	// OnClockDrift is called when a DriftMonitor finds the wall clock offset
+	// from the generator's clock by at least DriftConfig.Threshold (offset is
+	// positive when the generator is behind), and after each re-anchor.
+	// reanchored reports whether the generator's clock was moved by offset.
+	OnClockDrift(workerID int64, offset time.Duration, reanchored bool)
+}
+
+// NopObserver implements Observer with no-op callbacks.
+//
+// Example:
+//
+//	type overflowCounter struct {
+//	    snowflake.NopObserver
+//	    n atomic.Int64
+//	}
+//
+//	func (c *overflowCounter) OnSequenceOverflow(int64, time.Duration) { c.n.Add(1) }
+type NopObserver struct{}
+
+// OnClockBackward implements Observer.
+func (NopObserver) OnClockBackward(int64, time.Duration, bool) {}
+
+// OnSequenceOverflow implements Observer.
+func (NopObserver) OnSequenceOverflow(int64, time.Duration) {}
+
+// OnLifespanThreshold implements Observer.
+func (NopObserver) OnLifespanThreshold(int64, LifespanInfo) {}
+
+// OnTimestampOverflow implements Observer.
+func (NopObserver) OnTimestampOverflow(int64, *OverflowError) {}
+
+// OnClockDrift implements DriftObserver.
+func (NopObserver) OnClockDrift(int64, time.Duration, bool) {}
+
+// MultiObserver returns an Observer that forwards every event to each of
+// observers in order. Nil observers are skipped.
+func MultiObserver(observers ...Observer) Observer {
+	list := make(multiObserver, 0, len(observers))
+	for _, o := range observers {
+		if o != nil {
+			list = append(list, o)
+		}
+	}
+	return list
+}
+
+// multiObserver fans events out to several observers.
+type multiObserver []Observer
+
+func (m multiObserver) OnClockBackward(workerID int64, drift time.Duration, recovered bool) {
+	for _, o := range m {
+		o.OnClockBackward(workerID, drift, recovered)
+	}
+}
+
+func (m multiObserver) OnSequenceOverflow(workerID int64, wait time.Duration) {
+	for _, o := range m {
+		o.OnSequenceOverflow(workerID, wait)
+	}
+}
+
+func (m multiObserver) OnLifespanThreshold(workerID int64, info LifespanInfo) {
+	for _, o := range m {
+		o.OnLifespanThreshold(workerID, info)
+	}
+}
+
+func (m multiObserver) OnTimestampOverflow(workerID int64, err *OverflowError) {
+	for _, o := range m {
+		o.OnTimestampOverflow(workerID, err)
+	}
+}
+
+func (m multiObserver) OnClockDrift(workerID int64, offset time.Duration, reanchored bool) {
+	for _, o := range m {
+		if d, ok := o.(DriftObserver); ok {
+			d.OnClockDrift(workerID, offset, reanchored)
+		}
+	}
+}
+
+// ============================================================================
+// log/slog adapter
+// ============================================================================
+
+// SlogObserver logs events with a *slog.Logger.
+//
+// Levels: unrecovered clock drift and timestamp overflow log at Error, recovered
+// drift, wall clock offsets and the lifespan threshold at Warn, and sequence
+// overflows (routine under load) at Debug.
+//
+// Example:
+//
+//	cfg := snowflake.DefaultConfig(workerID)
+//	cfg.Observer = snowflake.NewSlogObserver(slog.Default())
+type SlogObserver struct {
+	logger *slog.Logger
+}
+
+// NewSlogObserver returns an Observer that logs to logger. A nil logger uses slog.Default().
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogObserver{logger: logger}
+}
+
+// OnClockBackward implements Observer.
+func (s *SlogObserver) OnClockBackward(workerID int64, drift time.Duration, recovered bool) {
+	level := slog.LevelWarn
+	if !recovered {
+		level = slog.LevelError
+	}
+	s.logger.LogAttrs(context.Background(), level, "snowflake: clock moved backwards",
+		slog.Int64("worker_id", workerID),
+		slog.Duration("drift", drift),
+		slog.Bool("recovered", recovered))
+}
+
+// OnSequenceOverflow implements Observer.
+func (s *SlogObserver) OnSequenceOverflow(workerID int64, wait time.Duration) {
+	s.logger.LogAttrs(context.Background(), slog.LevelDebug, "snowflake: sequence overflow",
+		slog.Int64("worker_id", workerID),
+		slog.Duration("wait", wait))
+}
+
+// OnLifespanThreshold implements Observer.
+func (s *SlogObserver) OnLifespanThreshold(workerID int64, info LifespanInfo) {
+	s.logger.LogAttrs(context.Background(), slog.LevelWarn, "snowflake: approaching timestamp overflow",
+		slog.Int64("worker_id", workerID),
+		slog.Float64("utilization", info.Utilization),
+		slog.Duration("remaining", info.Remaining),
+		slog.Time("overflow_date", info.OverflowDate))
+}
+
+// OnTimestampOverflow implements Observer.
+func (s *SlogObserver) OnTimestampOverflow(workerID int64, err *OverflowError) {
+	s.logger.LogAttrs(context.Background(), slog.LevelError, "snowflake: timestamp overflow",
+		slog.Int64("worker_id", workerID),
+		slog.String("error", err.Error()))
+}
+
+// OnClockDrift implements DriftObserver.
+func (s *SlogObserver) OnClockDrift(workerID int64, offset time.Duration, reanchored bool) {
+	s.logger.LogAttrs(context.Background(), slog.LevelWarn, "snowflake: wall clock offset from generator clock",
+		slog.Int64("worker_id", workerID),
+		slog.Duration("offset", offset),
+		slog.Bool("reanchored", reanchored))
+}
+
+// ============================================================================
+// Channel adapter
+// ============================================================================
+
+// EventKind identifies the type of an Event.
+type EventKind int
+
+const (
+	// EventClockBackward corresponds to Observer.OnClockBackward.
+	EventClockBackward EventKind = iota
+
+	// EventSequenceOverflow corresponds to Observer.OnSequenceOverflow.
+	EventSequenceOverflow
+
+	// EventLifespanThreshold corresponds to Observer.OnLifespanThreshold.
+	EventLifespanThreshold
+
+	// EventTimestampOverflow corresponds to Observer.OnTimestampOverflow.
+	EventTimestampOverflow
+
+	// EventClockDrift corresponds to DriftObserver.OnClockDrift.
+	EventClockDrift
+)
+
+// String returns a human-readable name for the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventClockBackward:
+		return "clock_backward"
+	case EventSequenceOverflow:
+		return "sequence_overflow"
+	case EventLifespanThreshold:
+		return "lifespan_threshold"
+	case EventTimestampOverflow:
+		return "timestamp_overflow"
+	case EventClockDrift:
+		return "clock_drift"
+	default:
+		return "unknown_event"
+	}
+}
+
+// Event is an Observer callback delivered as a value by ChannelObserver.
+//
+// Only the fields relevant to Kind are set.
+type Event struct {
+	Kind     EventKind
+	WorkerID int64
+	Time     time.Time // When the event was delivered
+
+	Drift      time.Duration  // EventClockBackward; the offset for EventClockDrift
+	Recovered  bool           // EventClockBackward
+	Reanchored bool           // EventClockDrift
+	Wait       time.Duration  // EventSequenceOverflow
+	Lifespan   LifespanInfo   // EventLifespanThreshold
+	Err        *OverflowError // EventTimestampOverflow
+}
+
+// ChannelObserver delivers events on a buffered channel for consumption by
+// another goroutine.
+//
+// Sends never block ID generation: when the buffer is full the event is
+// dropped and counted in Dropped.
+//
+// Example:
+//
+//	events := snowflake.NewChannelObserver(256)
+//	cfg.Observer = events
+//
+//	go func() {
+//	    for ev := range events.Events() {
+//	        alerts.Send(ev.Kind.String(), ev.WorkerID)
+//	    }
+//	}()
+type ChannelObserver struct {
+	ch      chan Event
+	dropped atomic.Int64
+}
+
+// NewChannelObserver returns a ChannelObserver whose channel buffers up to size events.
+func NewChannelObserver(size int) *ChannelObserver {
+	return &ChannelObserver{ch: make(chan Event, max(size, 0))}
+}
+
+// Events returns the channel events are delivered on. It is never closed.
+func (c *ChannelObserver) Events() <-chan Event {
+	return c.ch
+}
+
+// Dropped returns the number of events discarded because the channel was full.
+func (c *ChannelObserver) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+// send delivers ev without blocking.
+func (c *ChannelObserver) send(ev Event) {
+	ev.Time = time.Now()
+	select {
+	case c.ch <- ev:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// OnClockBackward implements Observer.
+func (c *ChannelObserver) OnClockBackward(workerID int64, drift time.Duration, recovered bool) {
+	c.send(Event{Kind: EventClockBackward, WorkerID: workerID, Drift: drift, Recovered: recovered})
+}
+
+// OnSequenceOverflow implements Observer.
+func (c *ChannelObserver) OnSequenceOverflow(workerID int64, wait time.Duration) {
+	c.send(Event{Kind: EventSequenceOverflow, WorkerID: workerID, Wait: wait})
+}
+
+// OnLifespanThreshold implements Observer.
+func (c *ChannelObserver) OnLifespanThreshold(workerID int64, info LifespanInfo) {
+	c.send(Event{Kind: EventLifespanThreshold, WorkerID: workerID, Lifespan: info})
+}
+
+// OnTimestampOverflow implements Observer.
+func (c *ChannelObserver) OnTimestampOverflow(workerID int64, err *OverflowError) {
+	c.send(Event{Kind: EventTimestampOverflow, WorkerID: workerID, Err: err})
+}
+
+// OnClockDrift implements DriftObserver.
+func (c *ChannelObserver) OnClockDrift(workerID int64, offset time.Duration, reanchored bool) {
+	c.send(Event{Kind: EventClockDrift, WorkerID: workerID, Drift: offset, Reanchored: reanchored})
+}
+
+// ============================================================================
+// Generator plumbing
+// ============================================================================
+
+// notifyLocked records an event for delivery once g.mu is released.
+//
+// The caller must hold g.mu. Without an observer this is a no-op, so the hot
+// path pays only a nil check.
+func (g *Generator) notifyLocked(event func(Observer)) {
+	if g.observer != nil {
+		g.pending = append(g.pending, event)
+	}
+}
+
+// notifyClockBackwardLocked records a clock-backward event for drift time units.
+func (g *Generator) notifyClockBackwardLocked(drift int64, recovered bool) {
+	if g.observer != nil {
+		d := time.Duration(drift) * g.timeUnit
+		g.notifyLocked(func(o Observer) { o.OnClockBackward(g.workerID, d, recovered) })
+	}
+}
+
+// notifySequenceOverflowLocked records a sequence-overflow event.
+func (g *Generator) notifySequenceOverflowLocked(wait time.Duration) {
+	if g.observer != nil {
+		g.notifyLocked(func(o Observer) { o.OnSequenceOverflow(g.workerID, wait) })
+	}
+}
+
+// generatorObserver combines cfg.Observer with a SlogObserver for cfg.Logger.
+func generatorObserver(cfg Config) Observer {
+	if cfg.Logger == nil {
+		return cfg.Observer
+	}
+	logger := cfg.Logger
+	if cfg.Name != "" {
+		logger = logger.With(slog.String("generator", cfg.Name))
+	}
+	if cfg.Observer == nil {
+		return NewSlogObserver(logger)
+	}
+	return MultiObserver(cfg.Observer, NewSlogObserver(logger))
+}
+
+// unlockAndNotify releases g.mu and then delivers the events recorded while it
+// was held. Generation paths defer it in place of g.mu.Unlock.
+func (g *Generator) unlockAndNotify() {
+	events := g.pending
+	g.pending = nil
+	g.mu.Unlock()
+
+	for _, event := range events {
+		event(g.observer)
+	}
+}
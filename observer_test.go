@@ -0,0 +1,225 @@
+package snowflake
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingObserver records events for assertions.
+type recordingObserver struct {
+	mu        sync.Mutex
+	clock     []bool // recovered flags
+	drifts    []time.Duration
+	overflows []time.Duration
+	lifespans []LifespanInfo
+	tsErrs    []*OverflowError
+
+	onEvent func() // Called after each event, outside the generator lock
+}
+
+func (r *recordingObserver) OnClockBackward(_ int64, drift time.Duration, recovered bool) {
+	r.mu.Lock()
+	r.clock = append(r.clock, recovered)
+	r.drifts = append(r.drifts, drift)
+	r.mu.Unlock()
+	r.fire()
+}
+
+func (r *recordingObserver) OnSequenceOverflow(_ int64, wait time.Duration) {
+	r.mu.Lock()
+	r.overflows = append(r.overflows, wait)
+	r.mu.Unlock()
+	r.fire()
+}
+
+func (r *recordingObserver) OnLifespanThreshold(_ int64, info LifespanInfo) {
+	r.mu.Lock()
+	r.lifespans = append(r.lifespans, info)
+	r.mu.Unlock()
+	r.fire()
+}
+
+func (r *recordingObserver) OnTimestampOverflow(_ int64, err *OverflowError) {
+	r.mu.Lock()
+	r.tsErrs = append(r.tsErrs, err)
+	r.mu.Unlock()
+	r.fire()
+}
+
+func (r *recordingObserver) fire() {
+	if r.onEvent != nil {
+		r.onEvent()
+	}
+}
+
+func newObservedGenerator(t *testing.T, obs Observer, layout BitLayout, epoch time.Time) *Generator {
+	t.Helper()
+	cfg := DefaultConfig(1)
+	cfg.Layout = layout
+	cfg.Epoch = epoch.UnixMilli()
+	cfg.Observer = obs
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	return gen
+}
+
+func TestObserver_ClockBackward(t *testing.T) {
+	obs := &recordingObserver{}
+	gen := newObservedGenerator(t, obs, LayoutDefault, time.UnixMilli(Epoch))
+
+	// Recoverable drift: waited out
+	gen.mu.Lock()
+	gen.lastTimestamp = gen.currentTimestamp() + 2
+	gen.lastClock = gen.lastTimestamp
+	gen.mu.Unlock()
+	if _, err := gen.GenerateID(); err != nil {
+		t.Fatalf("GenerateID() error = %v", err)
+	}
+
+	// Drift beyond tolerance: fails
+	gen.mu.Lock()
+	gen.lastTimestamp = gen.currentTimestamp() + 100
+	gen.lastClock = gen.lastTimestamp
+	gen.mu.Unlock()
+	if _, err := gen.GenerateID(); !IsClockError(err) {
+		t.Fatalf("GenerateID() error = %v, want ClockError", err)
+	}
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if len(obs.clock) != 2 || !obs.clock[0] || obs.clock[1] {
+		t.Fatalf("recovered flags = %v, want [true false]", obs.clock)
+	}
+	if obs.drifts[0] < time.Millisecond || obs.drifts[0] > 2*time.Millisecond {
+		t.Errorf("recovered drift = %v, want 1-2ms", obs.drifts[0])
+	}
+	if obs.drifts[1] < 99*time.Millisecond {
+		t.Errorf("unrecovered drift = %v, want ~100ms", obs.drifts[1])
+	}
+}
+
+func TestObserver_SequenceOverflowWithoutLock(t *testing.T) {
+	obs := &recordingObserver{}
+	gen := newObservedGenerator(t, obs, LayoutDefault, time.UnixMilli(Epoch))
+
+	// The callback generates an ID itself, which would deadlock if the
+	// generator still held its lock
+	var reentered ID
+	obs.onEvent = func() {
+		if reentered == 0 {
+			reentered = gen.MustGenerateID()
+		}
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := gen.Reserve(ctx, 4096); err != nil {
+			t.Fatalf("Reserve() error = %v", err)
+		}
+	}
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if len(obs.overflows) == 0 {
+		t.Fatal("no OnSequenceOverflow events after reserving three full time units")
+	}
+	if int64(len(obs.overflows)) != gen.GetMetrics().SequenceOverflow {
+		t.Errorf("%d events, want one per SequenceOverflow (%d)", len(obs.overflows), gen.GetMetrics().SequenceOverflow)
+	}
+	if reentered == 0 {
+		t.Error("callback did not run")
+	}
+}
+
+func TestObserver_Lifespan(t *testing.T) {
+	// LayoutUltra lasts ~17.4 years; start 85% of the way through it
+	total := time.Duration((int64(1)<<LayoutUltra.TimestampBits)-1) * time.Millisecond
+	obs := &recordingObserver{}
+	gen := newObservedGenerator(t, obs, LayoutUltra, time.Now().Add(-total/100*85))
+
+	for i := 0; i < 10; i++ {
+		gen.MustGenerateID()
+	}
+
+	obs.mu.Lock()
+	if len(obs.lifespans) != 1 {
+		t.Fatalf("OnLifespanThreshold called %d times, want once", len(obs.lifespans))
+	}
+	if info := obs.lifespans[0]; !info.IsApproaching || info.Utilization < 0.84 || info.TotalLifespan != total {
+		t.Errorf("LifespanInfo = %+v, want ~85%% of %v", info, total)
+	}
+	obs.mu.Unlock()
+
+	// Past the end of the layout's lifespan generation fails
+	obs = &recordingObserver{}
+	gen = newObservedGenerator(t, obs, LayoutUltra, time.Now().Add(-total-time.Hour))
+	if _, err := gen.GenerateID(); !IsOverflowError(err) {
+		t.Fatalf("GenerateID() error = %v, want OverflowError", err)
+	}
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if len(obs.tsErrs) != 1 || obs.tsErrs[0].Type != TimestampOverflowType {
+		t.Errorf("OnTimestampOverflow events = %v, want one timestamp overflow", obs.tsErrs)
+	}
+}
+
+func TestObserver_TimestampOverflowMillis(t *testing.T) {
+	// 10ms units: the error reports milliseconds, not time units
+	layout := BitLayout{TimestampBits: 35, WorkerBits: 16, SequenceBits: 12, TimeUnit: 10 * time.Millisecond, AllowNonStandard: true}
+	total := time.Duration(int64(1)<<layout.TimestampBits) * layout.TimeUnit
+	obs := &recordingObserver{}
+	gen := newObservedGenerator(t, obs, layout, time.Now().Add(-total-time.Hour))
+
+	before := time.Now().UnixMilli()
+	_, err := gen.GenerateID()
+	overflowErr, ok := GetOverflowError(err)
+	if !ok || overflowErr.Type != TimestampOverflowType {
+		t.Fatalf("GenerateID() error = %v, want timestamp OverflowError", err)
+	}
+	if overflowErr.Timestamp < before-10 || overflowErr.Timestamp > time.Now().UnixMilli() {
+		t.Errorf("Timestamp = %d, want milliseconds near %d", overflowErr.Timestamp, before)
+	}
+}
+
+func TestChannelObserver(t *testing.T) {
+	ch := NewChannelObserver(1)
+	ch.OnSequenceOverflow(7, time.Millisecond)
+	ch.OnClockBackward(7, time.Millisecond, true) // Buffer full: dropped
+
+	ev := <-ch.Events()
+	if ev.Kind != EventSequenceOverflow || ev.WorkerID != 7 || ev.Wait != time.Millisecond || ev.Time.IsZero() {
+		t.Errorf("event = %+v, want sequence overflow for worker 7", ev)
+	}
+	if ch.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", ch.Dropped())
+	}
+	if EventLifespanThreshold.String() != "lifespan_threshold" {
+		t.Errorf("EventLifespanThreshold.String() = %q", EventLifespanThreshold.String())
+	}
+}
+
+func TestSlogObserver(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	obs := MultiObserver(NewSlogObserver(logger), nil, NopObserver{})
+	obs.OnClockBackward(3, 2*time.Millisecond, false)
+	obs.OnSequenceOverflow(3, 0)
+
+	out := buf.String()
+	for _, want := range []string{
+		"level=ERROR", "clock moved backwards", "worker_id=3", "drift=2ms", "recovered=false",
+		"level=DEBUG", "sequence overflow",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+// Package snowflake - parse.go provides unambiguous, format-aware ID parsing.
+//
+// Guessing the encoding of an ID string by trying decimal, Base62, Base58, Hex
+// and Base32 in turn is fragile: a short hex string is also valid Base62, and
+// the first decoder that succeeds wins silently. ParseAny instead honors explicit
+// format tags, and reports an error when an untagged string could be read in
+// more than one way.
+
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Encoding identifies a textual representation of an ID.
+//
+// The string value is the canonical name accepted by ParseEncoding and ID.Format.
+type Encoding string
+
+// Supported encodings.
+const (
+	EncodingDecimal   Encoding = "decimal"
+	EncodingBase2     Encoding = "binary"
+	EncodingBase32    Encoding = "base32"
+	EncodingBase36    Encoding = "base36"
+	EncodingBase58    Encoding = "base58"
+	EncodingBase62    Encoding = "base62"
+	EncodingBase64    Encoding = "base64"
+	EncodingBase64URL Encoding = "base64url"
+	EncodingHex       Encoding = "hex"
+	EncodingGrouped   Encoding = "grouped"
+)
+
+// DefaultParseEncodings is the set of encodings ParseAny considers for untagged
+// input when no explicit set is given.
+//
+// Grouped IDs are only detected in their canonical dashed form, which no other
+// encoding can produce. Base36 and Base64 are excluded because almost every
+// decimal, hex or Base62 string is also valid in those alphabets; use a tag
+// (b36:, b64:) to parse them.
+var DefaultParseEncodings = []Encoding{
+	EncodingDecimal,
+	EncodingBase62,
+	EncodingBase58,
+	EncodingHex,
+	EncodingBase32,
+	EncodingGrouped,
+}
+
+// Errors returned by ParseAny and ParseEncoding.
+var (
+	// ErrAmbiguousID is returned when an untagged string is valid in more than one encoding.
+	ErrAmbiguousID = errors.New("ambiguous ID encoding")
+
+	// ErrUnknownEncoding is returned for unrecognized encoding names or tags.
+	ErrUnknownEncoding = errors.New("unknown ID encoding")
+
+	// ErrEncodingNotAllowed is returned when a tagged string uses an encoding
+	// outside the allowed set.
+	ErrEncodingNotAllowed = errors.New("ID encoding not allowed")
+
+	// ErrUnparseableID is returned when a string is not valid in any allowed encoding.
+	ErrUnparseableID = errors.New("unable to parse ID")
+)
+
+// AmbiguousIDError reports an untagged ID string that decodes in several encodings.
+//
+// Example usage:
+//
+//	id, _, err := snowflake.ParseAny(input)
+//	var ambErr *snowflake.AmbiguousIDError
+//	if errors.As(err, &ambErr) {
+//	    fmt.Printf("%q could be any of %v; add a tag such as b62:\n", ambErr.Input, ambErr.Candidates)
+//	}
+type AmbiguousIDError struct {
+	// Input is the string that was parsed.
+	Input string
+
+	// Candidates lists every encoding the input is valid in, in the order tried.
+	Candidates []Encoding
+}
+
+// Error implements the error interface.
+func (e *AmbiguousIDError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		names[i] = string(c)
+	}
+	return fmt.Sprintf("ambiguous ID encoding: %q is valid as %s; use a format tag",
+		e.Input, strings.Join(names, ", "))
+}
+
+// Unwrap returns the underlying error for errors.Is() compatibility.
+func (e *AmbiguousIDError) Unwrap() error {
+	return ErrAmbiguousID
+}
+
+// encodingTags maps explicit input prefixes to encodings.
+//
+// Tags are matched case-insensitively and longest first, so "b64url:" wins over "b64:".
+var encodingTags = []struct {
+	tag      string
+	encoding Encoding
+}{
+	{"b64url:", EncodingBase64URL},
+	{"decimal:", EncodingDecimal},
+	{"base32:", EncodingBase32},
+	{"base36:", EncodingBase36},
+	{"base58:", EncodingBase58},
+	{"base62:", EncodingBase62},
+	{"base64:", EncodingBase64},
+	{"dec:", EncodingDecimal},
+	{"bin:", EncodingBase2},
+	{"b32:", EncodingBase32},
+	{"b36:", EncodingBase36},
+	{"b58:", EncodingBase58},
+	{"b62:", EncodingBase62},
+	{"b64:", EncodingBase64},
+	{"hex:", EncodingHex},
+	{"grp:", EncodingGrouped},
+	{"0x", EncodingHex},
+	{"0b", EncodingBase2},
+}
+
+// ParseEncoding resolves an encoding name or alias.
+//
+// Accepts the same names as ID.Format (case-insensitive):
+//   - "decimal", "dec", "d", "": Decimal
+//   - "binary", "bin", "b": Binary string
+//   - "base32", "b32", "32": z-base-32
+//   - "base36", "b36", "36": Base36
+//   - "base58", "b58", "58": Base58
+//   - "base62", "b62", "62": Base62
+//   - "base64", "b64", "64": Base64
+//   - "base64url", "b64url": URL-safe Base64
+//   - "hex", "x": Hexadecimal
+//   - "grouped", "grp": Grouped z-base-32 with check character
+//
+// Example:
+//
+//	enc, err := snowflake.ParseEncoding("b62")
+//	fmt.Println(enc.Encode(id))
+func ParseEncoding(name string) (Encoding, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "decimal", "dec", "d", "":
+		return EncodingDecimal, nil
+	case "binary", "bin", "b", "base2":
+		return EncodingBase2, nil
+	case "base32", "b32", "32":
+		return EncodingBase32, nil
+	case "base36", "b36", "36":
+		return EncodingBase36, nil
+	case "base58", "b58", "58":
+		return EncodingBase58, nil
+	case "base62", "b62", "62":
+		return EncodingBase62, nil
+	case "base64", "b64", "64":
+		return EncodingBase64, nil
+	case "base64url", "b64url":
+		return EncodingBase64URL, nil
+	case "hex", "x":
+		return EncodingHex, nil
+	case "grouped", "grp":
+		return EncodingGrouped, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownEncoding, name)
+	}
+}
+
+// Encode returns the ID in this encoding.
+//
+// Unknown encodings fall back to decimal, matching ID.Format.
+func (e Encoding) Encode(id ID) string {
+	switch e {
+	case EncodingBase2:
+		return id.Base2()
+	case EncodingBase32:
+		return id.Base32()
+	case EncodingBase36:
+		return id.Base36()
+	case EncodingBase58:
+		return id.Base58()
+	case EncodingBase62:
+		return id.Base62()
+	case EncodingBase64:
+		return id.Base64()
+	case EncodingBase64URL:
+		return id.Base64URL()
+	case EncodingHex:
+		return id.Hex()
+	case EncodingGrouped:
+		return id.Grouped()
+	default:
+		return id.String()
+	}
+}
+
+// Decode parses s in this encoding.
+//
+// Example:
+//
+//	id, err := snowflake.EncodingBase62.Decode("1tckI1NfUnH")
+func (e Encoding) Decode(s string) (ID, error) {
+	switch e {
+	case EncodingDecimal:
+		return ParseString(s)
+	case EncodingBase2:
+		return ParseBase2(s)
+	case EncodingBase32:
+		return ParseBase32(s)
+	case EncodingBase36:
+		return ParseBase36(s)
+	case EncodingBase58:
+		return ParseBase58(s)
+	case EncodingBase62:
+		return ParseBase62(s)
+	case EncodingBase64:
+		return ParseBase64(s)
+	case EncodingBase64URL:
+		return ParseBase64URL(s)
+	case EncodingHex:
+		return ParseHex(s)
+	case EncodingGrouped:
+		return ParseGrouped(s)
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownEncoding, string(e))
+	}
+}
+
+// isCanonical reports whether s is exactly how this encoding would render id.
+//
+// Untagged detection only accepts canonical forms, so strings with leading zero
+// digits or padding variations don't register as spurious candidates.
+// Hex and grouped IDs are compared case-insensitively because their parsers
+// accept both cases.
+func (e Encoding) isCanonical(id ID, s string) bool {
+	if e == EncodingHex || e == EncodingGrouped {
+		return strings.EqualFold(e.Encode(id), s)
+	}
+	return e.Encode(id) == s
+}
+
+// ParseAny parses an ID in any of the allowed encodings and reports which one matched.
+//
+// # Format Tags
+//
+// An explicit tag selects the encoding and bypasses detection:
+//
+//	0x, hex:          Hexadecimal
+//	0b, bin:          Binary string
+//	dec:, decimal:    Decimal
+//	b32:, base32:     z-base-32
+//	b36:, base36:     Base36
+//	b58:, base58:     Base58
+//	b62:, base62:     Base62
+//	b64:, base64:     Base64
+//	b64url:           URL-safe Base64
+//	grp:              Grouped (separators and case are ignored)
+//
+// # Untagged Input
+//
+// Untagged strings are decoded in every allowed encoding. A string only counts
+// as valid in an encoding if it is the canonical rendering of the decoded value
+// (no leading zero digits). If exactly one encoding matches, it is used; if
+// several match, an *AmbiguousIDError listing the candidates is returned.
+//
+// If allowed is empty, DefaultParseEncodings is used. Tagged input whose
+// encoding is outside the allowed set fails with ErrEncodingNotAllowed.
+//
+// Example:
+//
+//	id, enc, err := snowflake.ParseAny("b62:1tckI1NfUnH")
+//	id, enc, err = snowflake.ParseAny("0x112210f47de98115")
+//	id, enc, err = snowflake.ParseAny(input, snowflake.EncodingDecimal, snowflake.EncodingBase62)
+func ParseAny(s string, allowed ...Encoding) (ID, Encoding, error) {
+	if len(allowed) == 0 {
+		allowed = DefaultParseEncodings
+	}
+	s = strings.TrimSpace(s)
+
+	// Explicit tag: decode exactly as requested
+	lower := strings.ToLower(s)
+	for _, t := range encodingTags {
+		if !strings.HasPrefix(lower, t.tag) {
+			continue
+		}
+		if !containsEncoding(allowed, t.encoding) {
+			return 0, "", fmt.Errorf("%w: %s", ErrEncodingNotAllowed, t.encoding)
+		}
+		id, err := t.encoding.Decode(s[len(t.tag):])
+		if err != nil {
+			return 0, "", fmt.Errorf("%w: %q as %s: %w", ErrUnparseableID, s, t.encoding, err)
+		}
+		return id, t.encoding, nil
+	}
+
+	// Untagged: collect every encoding the string is canonically valid in
+	var (
+		match      ID
+		candidates []Encoding
+	)
+	for _, enc := range allowed {
+		id, err := enc.Decode(s)
+		if err != nil || !enc.isCanonical(id, s) {
+			continue
+		}
+		if len(candidates) == 0 {
+			match = id
+		}
+		candidates = append(candidates, enc)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return 0, "", fmt.Errorf("%w: %q", ErrUnparseableID, s)
+	case 1:
+		return match, candidates[0], nil
+	default:
+		return 0, "", &AmbiguousIDError{Input: s, Candidates: candidates}
+	}
+}
+
+// containsEncoding reports whether set contains enc.
+func containsEncoding(set []Encoding, enc Encoding) bool {
+	for _, e := range set {
+		if e == enc {
+			return true
+		}
+	}
+	return false
+}
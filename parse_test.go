@@ -0,0 +1,196 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+)
+
+// ============================================================================
+// ParseEncoding / Encoding Tests
+// ============================================================================
+
+func TestParseEncoding_Aliases(t *testing.T) {
+	tests := []struct {
+		name string
+		want Encoding
+	}{
+		{"", EncodingDecimal},
+		{"dec", EncodingDecimal},
+		{"bin", EncodingBase2},
+		{"B32", EncodingBase32},
+		{"36", EncodingBase36},
+		{"base58", EncodingBase58},
+		{"b62", EncodingBase62},
+		{"b64", EncodingBase64},
+		{"b64url", EncodingBase64URL},
+		{"x", EncodingHex},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEncoding(tt.name)
+		if err != nil {
+			t.Errorf("ParseEncoding(%q) error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseEncoding(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := ParseEncoding("rot13"); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("ParseEncoding(rot13) error = %v, want ErrUnknownEncoding", err)
+	}
+}
+
+func TestEncoding_RoundTrip(t *testing.T) {
+	id := ID(1234567890123456789)
+	encodings := []Encoding{
+		EncodingDecimal, EncodingBase2, EncodingBase32, EncodingBase36,
+		EncodingBase58, EncodingBase62, EncodingBase64, EncodingBase64URL, EncodingHex,
+		EncodingGrouped,
+	}
+
+	for _, enc := range encodings {
+		t.Run(string(enc), func(t *testing.T) {
+			decoded, err := enc.Decode(enc.Encode(id))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decoded != id {
+				t.Errorf("round trip = %d, want %d", decoded, id)
+			}
+			if got := id.Format(string(enc)); got != enc.Encode(id) {
+				t.Errorf("ID.Format(%q) = %q, want %q", enc, got, enc.Encode(id))
+			}
+		})
+	}
+}
+
+// ============================================================================
+// ParseAny Tests
+// ============================================================================
+
+func TestParseAny_Tags(t *testing.T) {
+	id := ID(1234567890123456789)
+
+	tests := []struct {
+		input string
+		want  Encoding
+	}{
+		{"0x" + id.Hex(), EncodingHex},
+		{"0X" + id.Hex(), EncodingHex},
+		{"hex:" + id.Hex(), EncodingHex},
+		{"b62:" + id.Base62(), EncodingBase62},
+		{"B62:" + id.Base62(), EncodingBase62},
+		{"b58:" + id.Base58(), EncodingBase58},
+		{"b32:" + id.Base32(), EncodingBase32},
+		{"b36:" + id.Base36(), EncodingBase36},
+		{"b64:" + id.Base64(), EncodingBase64},
+		{"b64url:" + id.Base64URL(), EncodingBase64URL},
+		{"0b" + id.Base2(), EncodingBase2},
+		{"dec:" + id.String(), EncodingDecimal},
+		{"  b62:" + id.Base62() + "\n", EncodingBase62},
+	}
+
+	for _, tt := range tests {
+		got, enc, err := ParseAny(tt.input,
+			EncodingDecimal, EncodingBase2, EncodingBase32, EncodingBase36,
+			EncodingBase58, EncodingBase62, EncodingBase64, EncodingBase64URL, EncodingHex)
+		if err != nil {
+			t.Errorf("ParseAny(%q) error = %v", tt.input, err)
+			continue
+		}
+		if got != id || enc != tt.want {
+			t.Errorf("ParseAny(%q) = (%d, %s), want (%d, %s)", tt.input, got, enc, id, tt.want)
+		}
+	}
+}
+
+func TestParseAny_TaggedInvalid(t *testing.T) {
+	_, _, err := ParseAny("hex:zz")
+	if !errors.Is(err, ErrUnparseableID) {
+		t.Errorf("expected ErrUnparseableID, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidHex) {
+		t.Errorf("expected wrapped ErrInvalidHex, got %v", err)
+	}
+}
+
+func TestParseAny_Unambiguous(t *testing.T) {
+	id := ID(1234567890123456789)
+
+	// Full-length encodings of a real ID are distinguishable by alphabet and length
+	tests := []struct {
+		input string
+		want  Encoding
+	}{
+		{id.String(), EncodingDecimal},
+		{id.Hex(), EncodingHex},
+	}
+
+	for _, tt := range tests {
+		got, enc, err := ParseAny(tt.input)
+		if err != nil {
+			t.Errorf("ParseAny(%q) error = %v", tt.input, err)
+			continue
+		}
+		if got != id || enc != tt.want {
+			t.Errorf("ParseAny(%q) = (%d, %s), want (%d, %s)", tt.input, got, enc, id, tt.want)
+		}
+	}
+}
+
+func TestParseAny_Ambiguous(t *testing.T) {
+	// "abc" is canonical Base62, Base58, Hex and z-base-32
+	_, _, err := ParseAny("abc")
+	if !errors.Is(err, ErrAmbiguousID) {
+		t.Fatalf("expected ErrAmbiguousID, got %v", err)
+	}
+
+	var ambErr *AmbiguousIDError
+	if !errors.As(err, &ambErr) {
+		t.Fatalf("expected *AmbiguousIDError, got %T", err)
+	}
+	if len(ambErr.Candidates) < 2 {
+		t.Errorf("Candidates = %v, want at least 2", ambErr.Candidates)
+	}
+	if !containsEncoding(ambErr.Candidates, EncodingHex) || !containsEncoding(ambErr.Candidates, EncodingBase62) {
+		t.Errorf("Candidates = %v, want hex and base62", ambErr.Candidates)
+	}
+}
+
+func TestParseAny_AllowedSet(t *testing.T) {
+	// Restricting the set resolves the ambiguity
+	id, enc, err := ParseAny("abc", EncodingHex)
+	if err != nil {
+		t.Fatalf("ParseAny() error = %v", err)
+	}
+	if id != 0xabc || enc != EncodingHex {
+		t.Errorf("ParseAny() = (%d, %s), want (%d, hex)", id, enc, 0xabc)
+	}
+
+	// Tags outside the set are rejected
+	if _, _, err := ParseAny("b62:abc", EncodingHex); !errors.Is(err, ErrEncodingNotAllowed) {
+		t.Errorf("expected ErrEncodingNotAllowed, got %v", err)
+	}
+
+	// Base36 isn't in the default set
+	if _, _, err := ParseAny("b36:abc"); !errors.Is(err, ErrEncodingNotAllowed) {
+		t.Errorf("expected ErrEncodingNotAllowed for b36 with default set, got %v", err)
+	}
+}
+
+func TestParseAny_NonCanonicalIgnored(t *testing.T) {
+	// Leading zeros are not canonical in any encoding
+	if _, _, err := ParseAny("0012"); !errors.Is(err, ErrUnparseableID) {
+		t.Errorf("expected ErrUnparseableID, got %v", err)
+	}
+}
+
+func TestParseAny_Invalid(t *testing.T) {
+	for _, input := range []string{"", "!!!", "not-an-id"} {
+		if _, _, err := ParseAny(input); !errors.Is(err, ErrUnparseableID) {
+			t.Errorf("ParseAny(%q) error = %v, want ErrUnparseableID", input, err)
+		}
+	}
+}
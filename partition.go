@@ -0,0 +1,372 @@
+// Package snowflake - partition.go plans time-bucketed table partitions.
+//
+// Because the timestamp occupies the high bits of an ID, every time bucket maps
+// to a contiguous ID range. A Partitioner turns a time window into buckets with
+// exact ID bounds and renders them as PostgreSQL, MySQL or SQLite DDL, so tables
+// can be range-partitioned on the primary key alone.
+
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dialect identifies the SQL flavor used when rendering statements.
+type Dialect string
+
+// Supported SQL dialects.
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectMySQL    Dialect = "mysql"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// MaxPartitionBuckets limits how many buckets Partitioner.Buckets returns,
+// guarding against windows that are far too wide for the interval.
+const MaxPartitionBuckets = 100000
+
+// Partition planning errors.
+var (
+	// ErrUnknownDialect is returned when a dialect name is not recognized.
+	ErrUnknownDialect = errors.New("unknown SQL dialect")
+
+	// ErrInvalidInterval is returned when a partition interval is not recognized.
+	ErrInvalidInterval = errors.New("invalid partition interval")
+
+	// ErrTooManyBuckets is returned when a window spans more than MaxPartitionBuckets buckets.
+	ErrTooManyBuckets = errors.New("too many partition buckets")
+)
+
+// ParseDialect converts a dialect name to a Dialect.
+//
+// Accepted names (case-insensitive):
+//   - "postgres", "postgresql", "pg"
+//   - "mysql", "mariadb"
+//   - "sqlite", "sqlite3"
+func ParseDialect(name string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "postgres", "postgresql", "pg":
+		return DialectPostgres, nil
+	case "mysql", "mariadb":
+		return DialectMySQL, nil
+	case "sqlite", "sqlite3":
+		return DialectSQLite, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownDialect, name)
+	}
+}
+
+// ParseInterval converts an interval name or Go duration to a partition interval.
+//
+// Accepted values (case-insensitive):
+//   - "hour", "hourly"
+//   - "day", "daily"
+//   - "week", "weekly"
+//   - any positive duration accepted by time.ParseDuration, e.g. "6h"
+//
+// Calendar months are not supported because their length varies.
+func ParseInterval(s string) (time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "hour", "hourly":
+		return time.Hour, nil
+	case "day", "daily":
+		return 24 * time.Hour, nil
+	case "week", "weekly":
+		return 7 * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidInterval, s)
+	}
+	return d, nil
+}
+
+// Bucket describes one time partition and the IDs it holds.
+type Bucket struct {
+	// Name is the partition table name, e.g. "events_2024_06_01".
+	Name string
+
+	// Start is the first instant of the bucket (inclusive, UTC).
+	Start time.Time
+
+	// End is the first instant after the bucket (exclusive, UTC).
+	End time.Time
+
+	// MinID is the smallest ID that can be generated within the bucket.
+	MinID ID
+
+	// MaxID is the largest ID that can be generated within the bucket.
+	MaxID ID
+}
+
+// Contains reports whether the ID belongs to this bucket.
+func (b Bucket) Contains(id ID) bool {
+	return id >= b.MinID && id <= b.MaxID
+}
+
+// Partitioner splits time into fixed-size buckets and maps them to ID ranges.
+//
+// Buckets are aligned to multiples of Interval counted from January 1, year 1
+// UTC (the same alignment as time.Truncate), so daily buckets start at UTC
+// midnight and weekly buckets start on Monday. With time units that don't
+// divide that alignment (such as 7ms), each boundary moves forward to the next
+// time unit boundary so no unit, and no ID, straddles two buckets.
+//
+// Example:
+//
+//	p, err := snowflake.NewPartitioner("events", gen.Scheme(), 24*time.Hour)
+//	buckets, err := p.Buckets(from, to)
+//	ddl, err := p.DDL(snowflake.DialectPostgres, buckets)
+type Partitioner struct {
+	// Table is the parent table name; bucket names are derived from it.
+	Table string
+
+	// Column is the ID column partitioned on.
+	// Default: "id"
+	Column string
+
+	// Scheme is the layout and epoch of the IDs being partitioned.
+	Scheme Scheme
+
+	// Interval is the length of each bucket. It must be a whole multiple of
+	// the scheme's time unit so bucket ID bounds are exact.
+	Interval time.Duration
+}
+
+// NewPartitioner returns a validated Partitioner.
+//
+// Example:
+//
+//	p, err := snowflake.NewPartitioner("events", snowflake.DefaultScheme, time.Hour)
+func NewPartitioner(table string, scheme Scheme, interval time.Duration) (*Partitioner, error) {
+	p := &Partitioner{
+		Table:    table,
+		Scheme:   scheme.normalized(),
+		Interval: interval,
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Validate checks the table and column names, scheme and interval.
+func (p *Partitioner) Validate() error {
+	if !isSQLIdentifier(p.Table) {
+		return newConfigError("Table", p.Table, "must be a SQL identifier", "letters, digits and underscores, not starting with a digit")
+	}
+	if p.Column != "" && !isSQLIdentifier(p.Column) {
+		return newConfigError("Column", p.Column, "must be a SQL identifier", "letters, digits and underscores, not starting with a digit")
+	}
+	if err := p.Scheme.Validate(); err != nil {
+		return err
+	}
+	unit := p.Scheme.normalized().Layout.TimeUnit
+	if p.Interval <= 0 || p.Interval%unit != 0 {
+		return newConfigError(
+			"Interval",
+			p.Interval.String(),
+			"must be a positive multiple of the time unit",
+			fmt.Sprintf("multiple of %v", unit),
+		)
+	}
+	return nil
+}
+
+// column returns the partition column, defaulting to "id".
+func (p *Partitioner) column() string {
+	if p.Column == "" {
+		return "id"
+	}
+	return p.Column
+}
+
+// BucketFor returns the bucket containing time t.
+//
+// The Partitioner must be valid (see Validate). ID bounds are clamped to the
+// scheme's range, so buckets before the epoch or past the lifespan share them;
+// Buckets clips its window to avoid such buckets.
+func (p *Partitioner) BucketFor(t time.Time) Bucket {
+	start := p.bucketStart(t)
+	end := start.Add(p.Interval)
+	return Bucket{
+		Name:  p.Table + "_" + start.Format(p.nameLayout()),
+		Start: start,
+		End:   end,
+		MinID: p.Scheme.MinIDForTime(start),
+		MaxID: p.Scheme.MaxIDForTime(end.Add(-time.Nanosecond)),
+	}
+}
+
+// bucketStart returns the start of the bucket containing t: the Interval
+// boundary at or before t, snapped forward onto the time unit grid.
+func (p *Partitioner) bucketStart(t time.Time) time.Time {
+	// Every Interval boundary is the same distance from the unit grid, since
+	// Interval is a multiple of the unit; measure it at the boundary nearest
+	// the Unix epoch, where the unit grid is anchored
+	unix := time.Unix(0, 0).UTC()
+	shift := unix.Sub(unix.Truncate(p.Interval)) % p.Scheme.normalized().Layout.TimeUnit
+	return t.UTC().Add(-shift).Truncate(p.Interval).Add(shift)
+}
+
+// BucketForID returns the bucket an ID belongs to.
+//
+// Example:
+//
+//	table := p.BucketForID(id).Name
+//	db.Exec("INSERT INTO "+table+" (id, data) VALUES (?, ?)", id, data)
+func (p *Partitioner) BucketForID(id ID) Bucket {
+	return p.BucketFor(p.Scheme.Time(id))
+}
+
+// Buckets returns every bucket overlapping the window [from, to], in order.
+//
+// The window is clipped to the times the scheme can represent, from its epoch
+// to the end of its lifespan, so no two buckets share ID bounds.
+//
+// Returns a *ConfigError if the Partitioner is invalid, ErrInvalidTimeRange if
+// to is before from or the window lies entirely outside the scheme's range, and
+// ErrTooManyBuckets if the window spans more than MaxPartitionBuckets buckets.
+func (p *Partitioner) Buckets(from, to time.Time) ([]Bucket, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: end %s is before start %s",
+			ErrInvalidTimeRange, to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	s := p.Scheme.normalized()
+	unit := s.Layout.TimeUnit
+	epoch := unitTime(s.epochUnits(), unit)
+	last := unitTime(s.epochUnits()+s.maxTimeUnits(), unit).Add(unit - time.Nanosecond)
+	if to.Before(epoch) {
+		return nil, fmt.Errorf("%w: window ends before epoch %s",
+			ErrInvalidTimeRange, epoch.UTC().Format(time.RFC3339))
+	}
+	if from.After(last) {
+		return nil, fmt.Errorf("%w: window starts after the layout's lifespan", ErrInvalidTimeRange)
+	}
+	if from.Before(epoch) {
+		from = epoch
+	}
+	if to.After(last) {
+		to = last
+	}
+
+	first := p.bucketStart(from)
+	count := to.Sub(first)/p.Interval + 1
+	if count > MaxPartitionBuckets {
+		return nil, fmt.Errorf("%w: window spans %d buckets (max %d)",
+			ErrTooManyBuckets, count, MaxPartitionBuckets)
+	}
+
+	buckets := make([]Bucket, 0, count)
+	for start := first; !start.After(to); start = start.Add(p.Interval) {
+		buckets = append(buckets, p.BucketFor(start))
+	}
+	return buckets, nil
+}
+
+// nameLayout picks the coarsest time format that keeps bucket names unique.
+func (p *Partitioner) nameLayout() string {
+	switch {
+	case p.Interval%(24*time.Hour) == 0:
+		return "2006_01_02"
+	case p.Interval%time.Hour == 0:
+		return "2006_01_02_15"
+	case p.Interval%time.Minute == 0:
+		return "2006_01_02_1504"
+	case p.Interval%time.Second == 0:
+		return "2006_01_02_150405"
+	default:
+		return "2006_01_02_150405_000"
+	}
+}
+
+// ============================================================================
+// DDL Rendering
+// ============================================================================
+
+// DDL renders statements that create the given buckets as partitions.
+//
+// The output depends on the dialect:
+//   - PostgreSQL: one CREATE TABLE ... PARTITION OF statement per bucket. The
+//     parent table must be declared with PARTITION BY RANGE on the ID column.
+//   - MySQL: a single ALTER TABLE ... PARTITION BY RANGE statement covering all
+//     buckets. Rows above the last bucket are rejected until more are added.
+//   - SQLite: one table per bucket with a CHECK constraint on the ID range,
+//     since SQLite has no native partitioning. Only the ID column is declared.
+//
+// Example:
+//
+//	ddl, err := p.DDL(snowflake.DialectPostgres, buckets)
+//	if err != nil {
+//	    return err
+//	}
+//	_, err = db.Exec(ddl)
+func (p *Partitioner) DDL(dialect Dialect, buckets []Bucket) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	switch dialect {
+	case DialectPostgres:
+		for _, bucket := range buckets {
+			fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s PARTITION OF %s\n    FOR VALUES FROM (%d) TO (%s);\n",
+				bucket.Name, p.Table, bucket.MinID, upperBound(bucket.MaxID))
+		}
+	case DialectMySQL:
+		if len(buckets) == 0 {
+			return "", nil
+		}
+		fmt.Fprintf(&b, "ALTER TABLE %s PARTITION BY RANGE (%s) (\n", p.Table, p.column())
+		for i, bucket := range buckets {
+			sep := ","
+			if i == len(buckets)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(&b, "    PARTITION %s VALUES LESS THAN (%s)%s\n",
+				bucket.Name, upperBound(bucket.MaxID), sep)
+		}
+		b.WriteString(");\n")
+	case DialectSQLite:
+		for _, bucket := range buckets {
+			fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s (\n    %s INTEGER PRIMARY KEY CHECK (%s BETWEEN %d AND %d)\n);\n",
+				bucket.Name, p.column(), p.column(), bucket.MinID, bucket.MaxID)
+		}
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownDialect, dialect)
+	}
+	return b.String(), nil
+}
+
+// upperBound renders the exclusive upper bound of a range ending at maxID,
+// using MAXVALUE when maxID+1 would overflow int64.
+func upperBound(maxID ID) string {
+	if maxID == ID(1<<63-1) {
+		return "MAXVALUE"
+	}
+	return fmt.Sprintf("%d", maxID+1)
+}
+
+// isSQLIdentifier reports whether s is a plain unquoted SQL identifier.
+func isSQLIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
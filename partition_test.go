@@ -0,0 +1,260 @@
+package snowflake
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDialect(t *testing.T) {
+	tests := map[string]Dialect{
+		"postgres":   DialectPostgres,
+		"PostgreSQL": DialectPostgres,
+		"pg":         DialectPostgres,
+		"mysql":      DialectMySQL,
+		"mariadb":    DialectMySQL,
+		"sqlite3":    DialectSQLite,
+	}
+	for input, want := range tests {
+		if got, err := ParseDialect(input); err != nil || got != want {
+			t.Errorf("ParseDialect(%q) = (%q, %v), want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseDialect("oracle"); !errors.Is(err, ErrUnknownDialect) {
+		t.Errorf("ParseDialect(oracle) error = %v, want ErrUnknownDialect", err)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := map[string]time.Duration{
+		"hour":  time.Hour,
+		"Daily": 24 * time.Hour,
+		"week":  7 * 24 * time.Hour,
+		"6h":    6 * time.Hour,
+	}
+	for input, want := range tests {
+		if got, err := ParseInterval(input); err != nil || got != want {
+			t.Errorf("ParseInterval(%q) = (%v, %v), want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"month", "-1h", "0s", ""} {
+		if _, err := ParseInterval(input); !errors.Is(err, ErrInvalidInterval) {
+			t.Errorf("ParseInterval(%q) error = %v, want ErrInvalidInterval", input, err)
+		}
+	}
+}
+
+func TestNewPartitioner_Validation(t *testing.T) {
+	if _, err := NewPartitioner("events", DefaultScheme, time.Hour); err != nil {
+		t.Errorf("NewPartitioner() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		table    string
+		scheme   Scheme
+		interval time.Duration
+	}{
+		{"empty table", "", DefaultScheme, time.Hour},
+		{"injection", "events; DROP TABLE users", DefaultScheme, time.Hour},
+		{"zero interval", "events", DefaultScheme, 0},
+		{"sub-unit interval", "events", Scheme{Layout: LayoutUltimate, Epoch: Epoch}, 15 * time.Millisecond},
+		{"bad epoch", "events", Scheme{Epoch: -1}, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPartitioner(tt.table, tt.scheme, tt.interval); !IsConfigError(err) {
+				t.Errorf("NewPartitioner() error = %v, want ConfigError", err)
+			}
+		})
+	}
+}
+
+func TestPartitioner_Buckets(t *testing.T) {
+	p, _ := NewPartitioner("events", DefaultScheme, 24*time.Hour)
+	from := time.Date(2024, 6, 1, 15, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+
+	buckets, err := p.Buckets(from, to)
+	if err != nil {
+		t.Fatalf("Buckets() error = %v", err)
+	}
+
+	wantNames := []string{"events_2024_06_01", "events_2024_06_02", "events_2024_06_03"}
+	if len(buckets) != len(wantNames) {
+		t.Fatalf("Buckets() returned %d buckets, want %d", len(buckets), len(wantNames))
+	}
+	for i, b := range buckets {
+		if b.Name != wantNames[i] {
+			t.Errorf("bucket %d name = %q, want %q", i, b.Name, wantNames[i])
+		}
+		if b.End.Sub(b.Start) != 24*time.Hour {
+			t.Errorf("bucket %d spans %v, want 24h", i, b.End.Sub(b.Start))
+		}
+		if b.MinID != MinIDForTime(b.Start) {
+			t.Errorf("bucket %d MinID = %d, want %d", i, b.MinID, MinIDForTime(b.Start))
+		}
+		// Buckets are contiguous in ID space
+		if i > 0 && buckets[i-1].MaxID+1 != b.MinID {
+			t.Errorf("gap between bucket %d and %d: %d..%d", i-1, i, buckets[i-1].MaxID, b.MinID)
+		}
+	}
+
+	if _, err := p.Buckets(to, from); !errors.Is(err, ErrInvalidTimeRange) {
+		t.Errorf("Buckets(reversed) error = %v, want ErrInvalidTimeRange", err)
+	}
+
+	small, _ := NewPartitioner("events", DefaultScheme, time.Second)
+	if _, err := small.Buckets(from, from.Add(365*24*time.Hour)); !errors.Is(err, ErrTooManyBuckets) {
+		t.Errorf("Buckets(too wide) error = %v, want ErrTooManyBuckets", err)
+	}
+}
+
+func TestPartitioner_Buckets_Invalid(t *testing.T) {
+	// A zero interval must not reach the bucket count division
+	p := &Partitioner{Table: "t", Scheme: DefaultScheme}
+	if _, err := p.Buckets(time.Now(), time.Now()); !IsConfigError(err) {
+		t.Errorf("Buckets() on a zero-value Partitioner error = %v, want ConfigError", err)
+	}
+}
+
+func TestPartitioner_Buckets_ClippedToScheme(t *testing.T) {
+	p, _ := NewPartitioner("events", DefaultScheme, 24*time.Hour)
+	epoch := time.UnixMilli(Epoch)
+
+	buckets, err := p.Buckets(epoch.Add(-48*time.Hour), epoch.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Buckets() error = %v", err)
+	}
+	if len(buckets) != 2 || !buckets[0].Start.Equal(epoch) {
+		t.Fatalf("Buckets() across the epoch = %+v, want 2 buckets from the epoch", buckets)
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].MinID != buckets[i-1].MaxID+1 {
+			t.Errorf("buckets %d and %d overlap or leave a gap: %d..%d", i-1, i, buckets[i-1].MaxID, buckets[i].MinID)
+		}
+	}
+
+	// The last bucket ends with the lifespan instead of repeating MAXVALUE
+	end := DefaultScheme.Time(DefaultScheme.MaxIDForTime(epoch.Add(100 * 365 * 24 * time.Hour)))
+	buckets, err = p.Buckets(end.Add(-24*time.Hour), end.Add(72*time.Hour))
+	if err != nil {
+		t.Fatalf("Buckets() across the lifespan end error = %v", err)
+	}
+	if len(buckets) != 2 || buckets[1].MaxID != ID(1<<63-1) || buckets[0].MaxID >= buckets[1].MinID {
+		t.Errorf("Buckets() across the lifespan end = %+v, want 2 distinct buckets ending at the last ID", buckets)
+	}
+
+	if _, err := p.Buckets(epoch.Add(-72*time.Hour), epoch.Add(-time.Hour)); !errors.Is(err, ErrInvalidTimeRange) {
+		t.Errorf("Buckets() before the epoch error = %v, want ErrInvalidTimeRange", err)
+	}
+	if _, err := p.Buckets(end.Add(time.Hour), end.Add(48*time.Hour)); !errors.Is(err, ErrInvalidTimeRange) {
+		t.Errorf("Buckets() after the lifespan error = %v, want ErrInvalidTimeRange", err)
+	}
+}
+
+func TestPartitioner_Buckets_NonDecimalUnit(t *testing.T) {
+	// 7ms units don't divide the year-1 alignment of time.Truncate, so bucket
+	// boundaries must be snapped onto the unit grid
+	unit := 7 * time.Millisecond
+	scheme := Scheme{Layout: BitLayout{TimestampBits: 38, WorkerBits: 13, SequenceBits: 12, TimeUnit: unit}, Epoch: Epoch}
+	p, err := NewPartitioner("events", scheme, 7*time.Hour)
+	if err != nil {
+		t.Fatalf("NewPartitioner() error = %v", err)
+	}
+
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	buckets, err := p.Buckets(from, from.Add(72*time.Hour))
+	if err != nil {
+		t.Fatalf("Buckets() error = %v", err)
+	}
+	for i, b := range buckets {
+		if b.Start.UnixNano()%int64(unit) != 0 {
+			t.Errorf("bucket %d starts at %v, inside a time unit", i, b.Start)
+		}
+		if b.End.Sub(b.Start) != 7*time.Hour {
+			t.Errorf("bucket %d spans %v, want 7h", i, b.End.Sub(b.Start))
+		}
+		if i > 0 && buckets[i-1].MaxID+1 != b.MinID {
+			t.Errorf("buckets %d and %d overlap or leave a gap: %d..%d", i-1, i, buckets[i-1].MaxID, b.MinID)
+		}
+		if got := p.BucketForID(b.MinID); got != b {
+			t.Errorf("BucketForID(bucket %d MinID) = %+v, want %+v", i, got, b)
+		}
+		if got := p.BucketForID(b.MaxID); got != b {
+			t.Errorf("BucketForID(bucket %d MaxID) = %+v, want %+v", i, got, b)
+		}
+	}
+}
+
+func TestPartitioner_BucketForID(t *testing.T) {
+	cfg := DefaultConfig(9)
+	cfg.Layout = LayoutUltimate
+	cfg.Epoch = 1600000000000
+	gen, _ := NewWithConfig(cfg)
+
+	p, err := NewPartitioner("logs", gen.Scheme(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewPartitioner() error = %v", err)
+	}
+
+	id := gen.MustGenerateID()
+	b := p.BucketForID(id)
+	if !b.Contains(id) {
+		t.Errorf("BucketForID(%d) = [%d, %d], does not contain the ID", id, b.MinID, b.MaxID)
+	}
+	if !strings.HasPrefix(b.Name, "logs_") || len(b.Name) != len("logs_2006_01_02_15") {
+		t.Errorf("BucketForID().Name = %q, want hourly name", b.Name)
+	}
+}
+
+func TestPartitioner_DDL(t *testing.T) {
+	p, _ := NewPartitioner("events", DefaultScheme, 24*time.Hour)
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	buckets, _ := p.Buckets(day, day.Add(24*time.Hour))
+
+	pg, err := p.DDL(DialectPostgres, buckets)
+	if err != nil {
+		t.Fatalf("DDL(postgres) error = %v", err)
+	}
+	wantPG := "CREATE TABLE IF NOT EXISTS events_2024_06_01 PARTITION OF events\n" +
+		"    FOR VALUES FROM (55082955571200000) TO (55445343436800000);\n"
+	if !strings.HasPrefix(pg, wantPG) {
+		t.Errorf("DDL(postgres) =\n%s\nwant prefix\n%s", pg, wantPG)
+	}
+
+	mysql, err := p.DDL(DialectMySQL, buckets)
+	if err != nil {
+		t.Fatalf("DDL(mysql) error = %v", err)
+	}
+	wantMySQL := "ALTER TABLE events PARTITION BY RANGE (id) (\n" +
+		"    PARTITION events_2024_06_01 VALUES LESS THAN (55445343436800000),\n" +
+		"    PARTITION events_2024_06_02 VALUES LESS THAN (55807731302400000)\n" +
+		");\n"
+	if mysql != wantMySQL {
+		t.Errorf("DDL(mysql) =\n%s\nwant\n%s", mysql, wantMySQL)
+	}
+
+	sqlite, err := p.DDL(DialectSQLite, buckets[:1])
+	if err != nil {
+		t.Fatalf("DDL(sqlite) error = %v", err)
+	}
+	if !strings.Contains(sqlite, "CHECK (id BETWEEN 55082955571200000 AND 55445343436799999)") {
+		t.Errorf("DDL(sqlite) = %s, missing CHECK constraint", sqlite)
+	}
+
+	if _, err := p.DDL("oracle", buckets); !errors.Is(err, ErrUnknownDialect) {
+		t.Errorf("DDL(oracle) error = %v, want ErrUnknownDialect", err)
+	}
+}
+
+func TestPartitioner_DDLMaxValue(t *testing.T) {
+	p, _ := NewPartitioner("events", DefaultScheme, 24*time.Hour)
+	b := p.BucketFor(time.UnixMilli(Epoch).Add(100 * 365 * 24 * time.Hour))
+
+	pg, _ := p.DDL(DialectPostgres, []Bucket{b})
+	if !strings.Contains(pg, "TO (MAXVALUE)") {
+		t.Errorf("DDL past lifespan = %s, want MAXVALUE upper bound", pg)
+	}
+}
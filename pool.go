@@ -0,0 +1,298 @@
+// Package snowflake - pool.go spreads ID generation across several generators.
+//
+// A single Generator is limited to one time unit's worth of sequences at a
+// time and serializes callers on one mutex. A Pool owns several generators
+// with distinct worker IDs, so throughput scales with the number of members
+// while every ID stays unique: IDs from different members differ in their
+// worker bits.
+
+package snowflake
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// PoolStrategy selects how a Pool picks a member for each call.
+type PoolStrategy int
+
+const (
+	// PoolRoundRobin cycles through members in order. Load is spread evenly,
+	// but concurrent callers may still contend on the same member.
+	PoolRoundRobin PoolStrategy = iota
+
+	// PoolAffinity prefers the member last used on the calling P (logical
+	// processor), via sync.Pool's per-P caches. Goroutines on different Ps
+	// mostly use different members, minimizing mutex contention.
+	PoolAffinity
+)
+
+// String returns the strategy name.
+func (s PoolStrategy) String() string {
+	switch s {
+	case PoolRoundRobin:
+		return "round-robin"
+	case PoolAffinity:
+		return "affinity"
+	default:
+		return fmt.Sprintf("PoolStrategy(%d)", int(s))
+	}
+}
+
+// PoolConfig configures a Pool.
+type PoolConfig struct {
+	// Config is the configuration shared by all members. Its WorkerID is the
+	// first worker ID when WorkerIDs is empty.
+	Config Config
+
+	// WorkerIDs lists the worker ID of each member. They must be distinct and
+	// fit the layout. If empty, Size consecutive IDs starting at
+	// Config.WorkerID are used.
+	WorkerIDs []int64
+
+	// Size is the number of members when WorkerIDs is empty.
+	// Default: runtime.GOMAXPROCS(0)
+	Size int
+
+	// Strategy selects how members are picked.
+	// Default: PoolRoundRobin
+	Strategy PoolStrategy
+}
+
+// workerIDs returns the worker IDs the pool will use.
+func (c PoolConfig) workerIDs() []int64 {
+	if len(c.WorkerIDs) > 0 {
+		return c.WorkerIDs
+	}
+	size := c.Size
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	ids := make([]int64, size)
+	for i := range ids {
+		ids[i] = c.Config.WorkerID + int64(i)
+	}
+	return ids
+}
+
+// Pool generates IDs from several member generators with distinct worker IDs.
+//
+// IDs from a Pool are unique, but unlike a single Generator they are not
+// strictly increasing across calls: two IDs generated in the same time unit by
+// different members are ordered by worker ID, not by call order.
+//
+// Example:
+//
+//	cfg := snowflake.DefaultConfig(0)
+//	pool, err := snowflake.NewPool(snowflake.PoolConfig{
+//	    Config:   cfg,
+//	    Size:     8, // worker IDs 0-7
+//	    Strategy: snowflake.PoolAffinity,
+//	})
+//	id, err := pool.GenerateID()
+type Pool struct {
+	members  []*Generator
+	strategy PoolStrategy
+	next     atomic.Uint64 // Round-robin cursor
+	affinity sync.Pool     // Per-P cache of *Generator for PoolAffinity
+}
+
+// Validate checks the strategy and that the worker IDs are distinct and all
+// fit the layout's worker bits.
+func (c PoolConfig) Validate() error {
+	if c.Strategy != PoolRoundRobin && c.Strategy != PoolAffinity {
+		return newConfigError("Strategy", c.Strategy.String(), "unknown strategy", "PoolRoundRobin or PoolAffinity")
+	}
+
+	base := c.Config
+	if err := base.Validate(); err != nil {
+		return err
+	}
+
+	_, _, maxWorker, _ := base.Layout.CalculateShifts()
+	seen := make(map[int64]bool)
+	for i, workerID := range c.workerIDs() {
+		field := fmt.Sprintf("WorkerIDs[%d]", i)
+		if err := base.Layout.ValidateWorkerID(workerID); err != nil {
+			return newConfigError(
+				field,
+				fmt.Sprintf("%d", workerID),
+				"out of valid range for layout",
+				fmt.Sprintf("must be between 0 and %d (%d bits)", maxWorker, base.Layout.WorkerBits),
+			)
+		}
+		if seen[workerID] {
+			return newConfigError(
+				field,
+				fmt.Sprintf("%d", workerID),
+				"duplicate worker ID",
+				"pool members must have distinct worker IDs",
+			)
+		}
+		seen[workerID] = true
+	}
+	return nil
+}
+
+// NewPool validates cfg and creates one member generator per worker ID.
+//
+// Returns a ConfigError if a worker ID is duplicated or does not fit the
+// layout; no generators are created in that case.
+func NewPool(cfg PoolConfig) (*Pool, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	workerIDs := cfg.workerIDs()
+	members := make([]*Generator, 0, len(workerIDs))
+	for _, workerID := range workerIDs {
+		memberCfg := cfg.Config
+		memberCfg.WorkerID = workerID
+		gen, err := NewWithConfig(memberCfg)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, gen)
+	}
+
+	p := &Pool{
+		members:  members,
+		strategy: cfg.Strategy,
+	}
+	p.affinity.New = func() any {
+		return p.roundRobin()
+	}
+	return p, nil
+}
+
+// roundRobin returns the next member in order.
+func (p *Pool) roundRobin() *Generator {
+	n := p.next.Add(1) - 1
+	return p.members[n%uint64(len(p.members))]
+}
+
+// pick selects a member according to the strategy. For PoolAffinity the
+// caller must hand the member back with release.
+func (p *Pool) pick() *Generator {
+	if p.strategy == PoolAffinity {
+		return p.affinity.Get().(*Generator)
+	}
+	return p.roundRobin()
+}
+
+// release returns a member picked with PoolAffinity to the per-P cache.
+func (p *Pool) release(g *Generator) {
+	if p.strategy == PoolAffinity {
+		p.affinity.Put(g)
+	}
+}
+
+// GenerateID generates an ID from one of the members.
+//
+// Thread-safe: Yes
+func (p *Pool) GenerateID() (ID, error) {
+	return p.GenerateIDWithContext(context.Background())
+}
+
+// GenerateIDWithContext generates an ID from one of the members with context support.
+//
+// Thread-safe: Yes
+func (p *Pool) GenerateIDWithContext(ctx context.Context) (ID, error) {
+	g := p.pick()
+	id, err := g.GenerateIDWithContext(ctx)
+	p.release(g)
+	return id, err
+}
+
+// MustGenerateID generates an ID and panics on error.
+func (p *Pool) MustGenerateID() ID {
+	id, err := p.GenerateID()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// GenerateBatch generates count IDs from a single member.
+//
+// See Generator.GenerateBatch for error semantics.
+//
+// Thread-safe: Yes
+func (p *Pool) GenerateBatch(ctx context.Context, count int) ([]ID, error) {
+	g := p.pick()
+	ids, err := g.GenerateBatch(ctx, count)
+	p.release(g)
+	return ids, err
+}
+
+// Size returns the number of members.
+func (p *Pool) Size() int {
+	return len(p.members)
+}
+
+// Members returns the member generators in configuration order.
+//
+// The slice is a copy; the generators are shared with the pool.
+func (p *Pool) Members() []*Generator {
+	return append([]*Generator(nil), p.members...)
+}
+
+// WorkerIDs returns the worker IDs of the members.
+func (p *Pool) WorkerIDs() []int64 {
+	ids := make([]int64, len(p.members))
+	for i, g := range p.members {
+		ids[i] = g.WorkerID()
+	}
+	return ids
+}
+
+// Scheme returns the layout and epoch shared by all members.
+func (p *Pool) Scheme() Scheme {
+	return p.members[0].Scheme()
+}
+
+// GetMetrics returns the sum of all members' metrics. LeadUs is the largest
+// lead of any member and ClockOffsetUs the largest offset in magnitude.
+//
+// Thread-safe: Yes, uses atomic operations
+func (p *Pool) GetMetrics() Metrics {
+	var total Metrics
+	for _, g := range p.members {
+		m := g.GetMetrics()
+		total.Generated += m.Generated
+		total.ClockBackward += m.ClockBackward
+		total.ClockBackwardErr += m.ClockBackwardErr
+		total.SequenceOverflow += m.SequenceOverflow
+		total.WaitTimeUs += m.WaitTimeUs
+		total.Borrowed += m.Borrowed
+		total.LeadUs = max(total.LeadUs, m.LeadUs)
+		total.Reanchors += m.Reanchors
+		if max(m.ClockOffsetUs, -m.ClockOffsetUs) > max(total.ClockOffsetUs, -total.ClockOffsetUs) {
+			total.ClockOffsetUs = m.ClockOffsetUs
+		}
+	}
+	return total
+}
+
+// MetricsSnapshot returns members' counters and histograms merged, as in GetMetrics.
+func (p *Pool) MetricsSnapshot() MetricsSnapshot {
+	var total MetricsSnapshot
+	for _, g := range p.members {
+		snap := g.MetricsSnapshot()
+		total.Latency = total.Latency.merge(snap.Latency)
+		total.OverflowWait = total.OverflowWait.merge(snap.OverflowWait)
+		total.ClockWait = total.ClockWait.merge(snap.ClockWait)
+	}
+	total.Metrics = p.GetMetrics()
+	return total
+}
+
+// ResetMetrics resets the metrics of every member.
+func (p *Pool) ResetMetrics() {
+	for _, g := range p.members {
+		g.ResetMetrics()
+	}
+}
@@ -0,0 +1,145 @@
+package snowflake
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestPoolConfig_Validate(t *testing.T) {
+	cfg := DefaultConfig(0)
+
+	valid := []PoolConfig{
+		{Config: cfg, Size: 4},
+		{Config: cfg, WorkerIDs: []int64{1, 5, 1023}},
+		{Config: cfg, Size: 2, Strategy: PoolAffinity},
+	}
+	for _, pc := range valid {
+		if err := pc.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", pc, err)
+		}
+	}
+
+	small := cfg
+	small.Layout = BitLayout{TimestampBits: 41, WorkerBits: 8, SequenceBits: 14, TimeUnit: LayoutDefault.TimeUnit}
+
+	invalid := []PoolConfig{
+		{Config: cfg, WorkerIDs: []int64{1, 2, 1}},
+		{Config: cfg, WorkerIDs: []int64{0, 1024}},
+		{Config: cfg, WorkerIDs: []int64{-1}},
+		{Config: withWorker(cfg, 1020), Size: 8},
+		{Config: withWorker(small, 250), Size: 8},
+		{Config: cfg, Size: 2, Strategy: PoolStrategy(9)},
+	}
+	for _, pc := range invalid {
+		if err := pc.Validate(); !IsConfigError(err) {
+			t.Errorf("Validate(%+v) error = %v, want ConfigError", pc, err)
+		}
+		if _, err := NewPool(pc); err == nil {
+			t.Errorf("NewPool(%+v) succeeded, want error", pc)
+		}
+	}
+}
+
+func withWorker(cfg Config, workerID int64) Config {
+	cfg.WorkerID = workerID
+	return cfg
+}
+
+func TestNewPool_WorkerIDs(t *testing.T) {
+	pool, err := NewPool(PoolConfig{Config: DefaultConfig(10), Size: 3})
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	got := pool.WorkerIDs()
+	if len(got) != 3 || got[0] != 10 || got[1] != 11 || got[2] != 12 {
+		t.Errorf("WorkerIDs() = %v, want [10 11 12]", got)
+	}
+	if pool.Size() != 3 || len(pool.Members()) != 3 {
+		t.Errorf("Size() = %d, want 3", pool.Size())
+	}
+}
+
+func TestPool_Unique(t *testing.T) {
+	for _, strategy := range []PoolStrategy{PoolRoundRobin, PoolAffinity} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			pool, err := NewPool(PoolConfig{Config: DefaultConfig(0), Size: 4, Strategy: strategy})
+			if err != nil {
+				t.Fatalf("NewPool() error = %v", err)
+			}
+
+			const goroutines, perGoroutine = 8, 2000
+			var mu sync.Mutex
+			seen := make(map[ID]bool, goroutines*perGoroutine)
+			var wg sync.WaitGroup
+
+			for i := 0; i < goroutines; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					local := make([]ID, 0, perGoroutine)
+					for j := 0; j < perGoroutine; j++ {
+						id, err := pool.GenerateID()
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						local = append(local, id)
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					for _, id := range local {
+						if seen[id] {
+							t.Errorf("duplicate ID %d", id)
+						}
+						seen[id] = true
+					}
+				}()
+			}
+			wg.Wait()
+
+			if m := pool.GetMetrics(); m.Generated != goroutines*perGoroutine {
+				t.Errorf("GetMetrics().Generated = %d, want %d", m.Generated, goroutines*perGoroutine)
+			}
+		})
+	}
+}
+
+func TestPool_RoundRobinSpreads(t *testing.T) {
+	pool, err := NewPool(PoolConfig{Config: DefaultConfig(0), Size: 4})
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+
+	for i := 0; i < 8; i++ {
+		pool.MustGenerateID()
+	}
+	for _, g := range pool.Members() {
+		if n := g.GetMetrics().Generated; n != 2 {
+			t.Errorf("worker %d generated %d, want 2", g.WorkerID(), n)
+		}
+	}
+}
+
+func TestPool_GenerateBatchAndReset(t *testing.T) {
+	pool, err := NewPool(PoolConfig{Config: DefaultConfig(0), Size: 2})
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+
+	ids, err := pool.GenerateBatch(context.Background(), 100)
+	if err != nil || len(ids) != 100 {
+		t.Fatalf("GenerateBatch() = %d IDs, %v", len(ids), err)
+	}
+	if m := pool.GetMetrics(); m.Generated != 100 {
+		t.Errorf("GetMetrics().Generated = %d, want 100", m.Generated)
+	}
+
+	pool.ResetMetrics()
+	if m := pool.GetMetrics(); m.Generated != 0 {
+		t.Errorf("after ResetMetrics Generated = %d, want 0", m.Generated)
+	}
+	if pool.Scheme() != pool.Members()[0].Scheme() {
+		t.Errorf("Scheme() = %+v, want members' scheme", pool.Scheme())
+	}
+}
@@ -0,0 +1,181 @@
+// Package snowflake - prometheus.go exposes generator metrics in the
+// Prometheus text exposition format.
+//
+// The format is simple enough to write directly, so there is no dependency on
+// the Prometheus client library. Every series carries name and worker labels,
+// so one handler can serve several generators (or all members of a Pool).
+
+package snowflake
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the Content-Type of the text exposition format.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsHandler returns an http.Handler that serves the metrics of gens in
+// the Prometheus text exposition format.
+//
+// Exported families (all prefixed with snowflake_):
+//
+//	ids_generated_total, clock_backward_total, clock_backward_errors_total,
+//	sequence_overflow_total, borrowed_time_units_total  counters
+//	reanchors_total, wait_time_seconds_total            counters
+//	borrow_lead_seconds, clock_offset_seconds           gauges
+//	lifespan_utilization_ratio, lifespan_remaining_seconds,
+//	lifespan_overflow_timestamp_seconds                 gauges
+//	generate_latency_seconds, overflow_wait_seconds,
+//	clock_wait_seconds                                  histograms
+//	generator_info                                      gauge (layout and epoch labels)
+//
+// Each series is labelled with the generator's Config.Name ("name") and
+// worker ID ("worker"); the combination must be unique across gens.
+//
+// Example:
+//
+//	http.Handle("/metrics", snowflake.MetricsHandler(orders, users))
+//
+//	// All members of a pool
+//	http.Handle("/metrics", snowflake.MetricsHandler(pool.Members()...))
+func MetricsHandler(gens ...*Generator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		if err := WritePrometheus(w, gens...); err != nil {
+			// Headers are already sent; nothing useful to report to the client
+			return
+		}
+	})
+}
+
+// WritePrometheus writes the metrics of gens to w in the Prometheus text
+// exposition format, as served by MetricsHandler.
+//
+// Use it to append snowflake metrics to an existing exposition endpoint.
+func WritePrometheus(w io.Writer, gens ...*Generator) error {
+	bw := bufio.NewWriter(w)
+
+	snaps := make([]MetricsSnapshot, len(gens))
+	infos := make([]LifespanInfo, len(gens))
+	labels := make([]string, len(gens))
+	for i, g := range gens {
+		snaps[i] = g.MetricsSnapshot()
+		infos[i] = g.LifespanInfo()
+		labels[i] = fmt.Sprintf(`name="%s",worker="%d"`, escapeLabelValue(g.Name()), g.WorkerID())
+	}
+
+	counter := func(name, help string, value func(i int) float64) {
+		writeFamilyHeader(bw, name, help, "counter")
+		for i := range gens {
+			writeSample(bw, name, labels[i], value(i))
+		}
+	}
+	gauge := func(name, help string, value func(i int) float64) {
+		writeFamilyHeader(bw, name, help, "gauge")
+		for i := range gens {
+			writeSample(bw, name, labels[i], value(i))
+		}
+	}
+	hist := func(name, help string, value func(i int) HistogramSnapshot) {
+		writeFamilyHeader(bw, name, help, "histogram")
+		for i := range gens {
+			writeHistogram(bw, name, labels[i], value(i))
+		}
+	}
+
+	counter("snowflake_ids_generated_total", "Total number of IDs generated.",
+		func(i int) float64 { return float64(snaps[i].Generated) })
+	counter("snowflake_clock_backward_total", "Clock backward events, including recovered ones.",
+		func(i int) float64 { return float64(snaps[i].ClockBackward) })
+	counter("snowflake_clock_backward_errors_total", "Clock backward events that exceeded tolerance and failed generation.",
+		func(i int) float64 { return float64(snaps[i].ClockBackwardErr) })
+	counter("snowflake_sequence_overflow_total", "Sequence exhaustion events.",
+		func(i int) float64 { return float64(snaps[i].SequenceOverflow) })
+	counter("snowflake_borrowed_time_units_total", "Time units borrowed ahead of the clock in burst mode.",
+		func(i int) float64 { return float64(snaps[i].Borrowed) })
+	counter("snowflake_reanchors_total", "Times a DriftMonitor re-anchored the generator's clock.",
+		func(i int) float64 { return float64(snaps[i].Reanchors) })
+	counter("snowflake_wait_time_seconds_total", "Total time spent waiting for the clock.",
+		func(i int) float64 { return float64(snaps[i].WaitTimeUs) / 1e6 })
+
+	gauge("snowflake_borrow_lead_seconds", "Current lead of borrowed timestamps over the clock.",
+		func(i int) float64 { return float64(snaps[i].LeadUs) / 1e6 })
+	gauge("snowflake_clock_offset_seconds", "Wall clock minus generator clock at the last DriftMonitor check.",
+		func(i int) float64 { return float64(snaps[i].ClockOffsetUs) / 1e6 })
+	gauge("snowflake_lifespan_utilization_ratio", "Fraction of the layout's timestamp range used (0-1).",
+		func(i int) float64 { return infos[i].Utilization })
+	gauge("snowflake_lifespan_remaining_seconds", "Time until the timestamp field overflows.",
+		func(i int) float64 { return infos[i].Remaining.Seconds() })
+	gauge("snowflake_lifespan_overflow_timestamp_seconds", "Unix time at which the timestamp field overflows.",
+		func(i int) float64 { return float64(infos[i].OverflowDate.Unix()) })
+
+	hist("snowflake_generate_latency_seconds", "Latency of single-ID generation calls.",
+		func(i int) HistogramSnapshot { return snaps[i].Latency })
+	hist("snowflake_overflow_wait_seconds", "Waits for the next time unit after sequence overflow.",
+		func(i int) HistogramSnapshot { return snaps[i].OverflowWait })
+	hist("snowflake_clock_wait_seconds", "Waits for the clock to catch up after it moved backward.",
+		func(i int) HistogramSnapshot { return snaps[i].ClockWait })
+
+	writeFamilyHeader(bw, "snowflake_generator_info", "Generator configuration (value is always 1).", "gauge")
+	for i, g := range gens {
+		l := g.layout
+		info := fmt.Sprintf(`%s,timestamp_bits="%d",worker_bits="%d",sequence_bits="%d",time_unit="%s",epoch="%d"`,
+			labels[i], l.TimestampBits, l.WorkerBits, l.SequenceBits, l.TimeUnit, g.epochMillis)
+		writeSample(bw, "snowflake_generator_info", info, 1)
+	}
+
+	return bw.Flush()
+}
+
+// writeFamilyHeader writes the HELP and TYPE lines of a metric family.
+func writeFamilyHeader(w *bufio.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// writeSample writes one sample line.
+func writeSample(w *bufio.Writer, name, labels string, value float64) {
+	fmt.Fprintf(w, "%s{%s} %s\n", name, labels, formatPromFloat(value))
+}
+
+// writeHistogram writes the cumulative buckets, sum and count of one histogram.
+//
+// The last internal bucket has no real upper bound, so it is reported only
+// through the +Inf bucket.
+func writeHistogram(w *bufio.Writer, name, labels string, h HistogramSnapshot) {
+	var cumulative int64
+	for i := 0; i < len(h.Buckets)-1; i++ {
+		cumulative += h.Buckets[i].Count
+		le := formatPromFloat(h.Buckets[i].UpperBound.Seconds())
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, le, cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.Count)
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", name, labels, formatPromFloat(h.Sum.Seconds()))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.Count)
+}
+
+// formatPromFloat formats a sample value as the exposition format expects.
+func formatPromFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// labelValueEscaper escapes backslash, double quote and newline in label values.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes a label value for the exposition format.
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
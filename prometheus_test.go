@@ -0,0 +1,207 @@
+package snowflake
+
+import (
+	"bufio"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// promSample is one parsed sample line.
+type promSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// parsePrometheus parses text exposition output, failing the test on any
+// malformed line or sample without a preceding TYPE line.
+func parsePrometheus(t *testing.T, text string) (samples []promSample, types map[string]string) {
+	t.Helper()
+	types = make(map[string]string)
+	helps := make(map[string]bool)
+
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "# HELP "):
+			fields := strings.SplitN(line, " ", 4)
+			if len(fields) != 4 {
+				t.Fatalf("malformed HELP line %q", line)
+			}
+			helps[fields[2]] = true
+			continue
+		case strings.HasPrefix(line, "# TYPE "):
+			fields := strings.Fields(line)
+			if len(fields) != 4 {
+				t.Fatalf("malformed TYPE line %q", line)
+			}
+			if _, dup := types[fields[2]]; dup {
+				t.Fatalf("duplicate TYPE for %s", fields[2])
+			}
+			types[fields[2]] = fields[3]
+			continue
+		}
+
+		open, end := strings.IndexByte(line, '{'), strings.LastIndexByte(line, '}')
+		if open < 0 || end < open {
+			t.Fatalf("sample without labels %q", line)
+		}
+		s := promSample{name: line[:open], labels: parseLabels(t, line[open+1:end])}
+		v, err := strconv.ParseFloat(strings.TrimSpace(line[end+1:]), 64)
+		if err != nil {
+			t.Fatalf("bad value in %q: %v", line, err)
+		}
+		s.value = v
+
+		family := s.name
+		for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+			if base := strings.TrimSuffix(family, suffix); types[base] == "histogram" {
+				family = base
+			}
+		}
+		if types[family] == "" || !helps[family] {
+			t.Fatalf("sample %s has no HELP/TYPE", s.name)
+		}
+		samples = append(samples, s)
+	}
+	return samples, types
+}
+
+// parseLabels parses a label set such as a="1",b="x\"y".
+func parseLabels(t *testing.T, s string) map[string]string {
+	t.Helper()
+	labels := make(map[string]string)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || len(s) < eq+2 || s[eq+1] != '"' {
+			t.Fatalf("malformed labels %q", s)
+		}
+		key := s[:eq]
+		var val strings.Builder
+		i := eq + 2
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				if s[i] == 'n' {
+					val.WriteByte('\n')
+					continue
+				}
+			}
+			val.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			t.Fatalf("unterminated label value in %q", s)
+		}
+		labels[key] = val.String()
+		s = strings.TrimPrefix(s[i+1:], ",")
+	}
+	return labels
+}
+
+// findSample returns the value of the sample with the given name and labels.
+func findSample(t *testing.T, samples []promSample, name string, labels map[string]string) float64 {
+	t.Helper()
+outer:
+	for _, s := range samples {
+		if s.name != name {
+			continue
+		}
+		for k, v := range labels {
+			if s.labels[k] != v {
+				continue outer
+			}
+		}
+		return s.value
+	}
+	t.Fatalf("no sample %s%v", name, labels)
+	return 0
+}
+
+func TestMetricsHandler(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.Name = "orders"
+	orders, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	cfg = DefaultConfig(2)
+	cfg.Name = `us"ers`
+	cfg.Layout = LayoutSuperior
+	users, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		orders.MustGenerateID()
+	}
+	users.MustGenerateID()
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(orders, users).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != PrometheusContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, PrometheusContentType)
+	}
+
+	samples, types := parsePrometheus(t, rec.Body.String())
+	ordersLabels := map[string]string{"name": "orders", "worker": "1"}
+	usersLabels := map[string]string{"name": `us"ers`, "worker": "2"}
+
+	if v := findSample(t, samples, "snowflake_ids_generated_total", ordersLabels); v != 10 {
+		t.Errorf("orders ids_generated_total = %v, want 10", v)
+	}
+	if v := findSample(t, samples, "snowflake_ids_generated_total", usersLabels); v != 1 {
+		t.Errorf("users ids_generated_total = %v, want 1", v)
+	}
+
+	for name, typ := range map[string]string{
+		"snowflake_ids_generated_total":         "counter",
+		"snowflake_clock_backward_total":        "counter",
+		"snowflake_clock_backward_errors_total": "counter",
+		"snowflake_sequence_overflow_total":     "counter",
+		"snowflake_borrowed_time_units_total":   "counter",
+		"snowflake_wait_time_seconds_total":     "counter",
+		"snowflake_borrow_lead_seconds":         "gauge",
+		"snowflake_lifespan_utilization_ratio":  "gauge",
+		"snowflake_lifespan_remaining_seconds":  "gauge",
+		"snowflake_generate_latency_seconds":    "histogram",
+		"snowflake_generator_info":              "gauge",
+	} {
+		if types[name] != typ {
+			t.Errorf("TYPE %s = %q, want %q", name, types[name], typ)
+		}
+	}
+
+	info := orders.LifespanInfo()
+	if v := findSample(t, samples, "snowflake_lifespan_utilization_ratio", ordersLabels); v <= 0 || v > info.Utilization+0.01 {
+		t.Errorf("lifespan_utilization_ratio = %v, want ~%v", v, info.Utilization)
+	}
+
+	findSample(t, samples, "snowflake_generator_info", map[string]string{
+		"name": `us"ers`, "worker": "2",
+		"timestamp_bits": "40", "worker_bits": "14", "sequence_bits": "9", "time_unit": "1ms",
+	})
+
+	// Histogram buckets are cumulative and end with +Inf == _count
+	var prev float64
+	var inf bool
+	for _, s := range samples {
+		if s.name != "snowflake_generate_latency_seconds_bucket" || s.labels["name"] != "orders" {
+			continue
+		}
+		if s.value < prev {
+			t.Fatalf("bucket le=%s = %v, below previous %v", s.labels["le"], s.value, prev)
+		}
+		prev = s.value
+		inf = s.labels["le"] == "+Inf"
+	}
+	if !inf || prev != 10 {
+		t.Errorf("last bucket +Inf=%v value %v, want +Inf with 10", inf, prev)
+	}
+	if v := findSample(t, samples, "snowflake_generate_latency_seconds_count", ordersLabels); v != 10 {
+		t.Errorf("latency _count = %v, want 10", v)
+	}
+}
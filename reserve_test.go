@@ -0,0 +1,224 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestIDRange_Methods(t *testing.T) {
+	r := NewIDRange(100, 5)
+
+	if r.First() != 100 || r.Last() != 104 || r.Len() != 5 {
+		t.Errorf("range = (%d, %d, %d), want (100, 104, 5)", r.First(), r.Last(), r.Len())
+	}
+	if !r.Contains(100) || !r.Contains(104) || r.Contains(99) || r.Contains(105) {
+		t.Error("Contains() boundaries are wrong")
+	}
+	if r.At(3) != 103 {
+		t.Errorf("At(3) = %d, want 103", r.At(3))
+	}
+	if r.String() != "[100..104]" {
+		t.Errorf("String() = %q, want [100..104]", r.String())
+	}
+
+	var visited []ID
+	r.Each(func(id ID) bool {
+		visited = append(visited, id)
+		return id < 102
+	})
+	if len(visited) != 3 || visited[2] != 102 {
+		t.Errorf("Each() with early stop visited %v, want [100 101 102]", visited)
+	}
+
+	ids := r.IDs()
+	if len(ids) != 5 || ids[0] != 100 || ids[4] != 104 {
+		t.Errorf("IDs() = %v", ids)
+	}
+
+	var empty IDRange
+	if empty.Len() != 0 || empty.Contains(0) || empty.String() != "[]" || len(empty.IDs()) != 0 {
+		t.Error("zero IDRange should be empty")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("At() out of range should panic")
+		}
+	}()
+	r.At(5)
+}
+
+func TestReserve_Block(t *testing.T) {
+	gen, _ := New(42)
+	ctx := context.Background()
+
+	r, err := gen.Reserve(ctx, 4096)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if r.Len() != 4096 {
+		t.Fatalf("Reserve() Len = %d, want 4096", r.Len())
+	}
+
+	// Every ID in the block decodes to the same timestamp and worker
+	ts0, _, _ := r.First().Components()
+	for _, id := range []ID{r.First(), r.At(2048), r.Last()} {
+		ts, worker, _ := id.Components()
+		if ts != ts0 || worker != 42 {
+			t.Errorf("ID %d decodes to (ts %d, worker %d), want (%d, 42)", id, ts, worker, ts0)
+		}
+	}
+	if _, _, seq := r.First().Components(); seq != 0 {
+		t.Errorf("full block should start at sequence 0, got %d", seq)
+	}
+
+	// Subsequent IDs are past the block
+	next := gen.MustGenerateID()
+	if next <= r.Last() {
+		t.Errorf("GenerateID() = %d after reservation ending at %d", next, r.Last())
+	}
+
+	if got := gen.GetMetrics().Generated; got != 4097 {
+		t.Errorf("Generated metric = %d, want 4097", got)
+	}
+}
+
+func TestReserve_Errors(t *testing.T) {
+	gen, _ := New(1)
+
+	if _, err := gen.Reserve(context.Background(), 4097); !errors.Is(err, ErrReserveTooLarge) {
+		t.Errorf("Reserve(4097) error = %v, want ErrReserveTooLarge", err)
+	}
+	if r, err := gen.Reserve(context.Background(), 0); err != nil || r.Len() != 0 {
+		t.Errorf("Reserve(0) = (%v, %v), want empty range", r, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := gen.Reserve(ctx, 10); !errors.Is(err, ErrContextCanceled) {
+		t.Errorf("Reserve(canceled) error = %v, want ErrContextCanceled", err)
+	}
+
+	cfg := DefaultConfig(1)
+	cfg.Layout = LayoutUltimate // 128 sequences per unit
+	small, _ := NewWithConfig(cfg)
+	if _, err := small.Reserve(context.Background(), 129); !errors.Is(err, ErrReserveTooLarge) {
+		t.Errorf("Reserve(129) on LayoutUltimate error = %v, want ErrReserveTooLarge", err)
+	}
+}
+
+func TestReserveRanges(t *testing.T) {
+	gen, _ := New(7)
+	ctx := context.Background()
+
+	// Leave the current time unit partially used
+	gen.MustGenerateID()
+
+	ranges, err := gen.ReserveRanges(ctx, 10000)
+	if err != nil {
+		t.Fatalf("ReserveRanges() error = %v", err)
+	}
+
+	total := 0
+	var prev ID
+	for i, r := range ranges {
+		if r.Len() == 0 || r.Len() > 4096 {
+			t.Errorf("range %d has length %d", i, r.Len())
+		}
+		if i > 0 && r.First() <= prev {
+			t.Errorf("range %d starts at %d, not after %d", i, r.First(), prev)
+		}
+		prev = r.Last()
+		total += r.Len()
+	}
+	if total != 10000 {
+		t.Errorf("ReserveRanges() reserved %d IDs, want 10000", total)
+	}
+	if len(ranges) < 3 || len(ranges) > 4 {
+		t.Errorf("ReserveRanges(10000) returned %d ranges, want 3 or 4", len(ranges))
+	}
+}
+
+func TestReserveRanges_ReleasesLock(t *testing.T) {
+	gen, _ := New(7)
+	ctx := context.Background()
+
+	// ~250 time units of IDs; GenerateID must not wait for all of them
+	done := make(chan []IDRange)
+	go func() {
+		ranges, err := gen.ReserveRanges(ctx, 1_000_000)
+		if err != nil {
+			t.Errorf("ReserveRanges() error = %v", err)
+		}
+		done <- ranges
+	}()
+	for gen.GetMetrics().Generated == 0 {
+		runtime.Gosched()
+	}
+
+	id, err := gen.GenerateID()
+	if err != nil {
+		t.Fatalf("GenerateID() error = %v", err)
+	}
+	select {
+	case <-done:
+		t.Fatal("GenerateID() waited for the whole ReserveRanges call")
+	default:
+	}
+
+	for _, r := range <-done {
+		if r.Contains(id) {
+			t.Errorf("ID %d from GenerateID is inside reserved range %v", id, r)
+		}
+	}
+}
+
+func TestReserve_ConcurrentUniqueness(t *testing.T) {
+	gen, _ := New(3)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	seen := make(map[ID]bool)
+	record := func(id ID) {
+		mu.Lock()
+		defer mu.Unlock()
+		if seen[id] {
+			t.Errorf("duplicate ID %d", id)
+		}
+		seen[id] = true
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				switch (w + i) % 3 {
+				case 0:
+					r, err := gen.Reserve(ctx, 300)
+					if err != nil {
+						t.Errorf("Reserve() error = %v", err)
+						return
+					}
+					r.Each(func(id ID) bool { record(id); return true })
+				case 1:
+					ids, err := gen.GenerateBatch(ctx, 50)
+					if err != nil {
+						t.Errorf("GenerateBatch() error = %v", err)
+						return
+					}
+					for _, id := range ids {
+						record(id)
+					}
+				default:
+					record(gen.MustGenerateID())
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
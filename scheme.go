@@ -0,0 +1,311 @@
+// Package snowflake - scheme.go binds a bit layout to an epoch.
+//
+// A Scheme is everything needed to interpret an ID without the generator that
+// produced it: where the timestamp, worker and sequence bits live, and what
+// instant timestamp zero refers to. It is used to convert between wall-clock
+// time and IDs, e.g. to turn a time window into an ID range for index scans.
+
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Scheme describes how IDs are composed: a bit layout plus a custom epoch.
+//
+// IDs from generators with the same layout and epoch share a Scheme, and can be
+// compared, range-scanned and decoded consistently.
+//
+// Example:
+//
+//	scheme := snowflake.Scheme{Layout: snowflake.LayoutSuperior, Epoch: snowflake.Epoch}
+//	first, last, err := scheme.IDRangeForWindow(start, end)
+//	rows, err := db.Query("SELECT * FROM events WHERE id BETWEEN ? AND ?", first, last)
+type Scheme struct {
+	// Layout is the bit allocation of the IDs.
+	Layout BitLayout
+
+	// Epoch is the custom epoch in milliseconds since the Unix epoch.
+	Epoch int64
+}
+
+// DefaultScheme is the scheme used by DefaultConfig: LayoutDefault with the 2024 epoch.
+var DefaultScheme = Scheme{
+	Layout: LayoutDefault,
+	Epoch:  Epoch,
+}
+
+// ErrInvalidTimeRange is returned when a time window is empty or lies entirely
+// outside the range a scheme can represent.
+var ErrInvalidTimeRange = errors.New("invalid time range")
+
+// NewScheme returns a validated Scheme.
+//
+// A zero-valued layout defaults to LayoutDefault, matching Config.Validate.
+func NewScheme(layout BitLayout, epoch int64) (Scheme, error) {
+	s := Scheme{Layout: layout, Epoch: epoch}
+	if err := s.Validate(); err != nil {
+		return Scheme{}, err
+	}
+	return s.normalized(), nil
+}
+
+// Validate checks that the layout is valid and the epoch is positive.
+func (s Scheme) Validate() error {
+	s = s.normalized()
+	if err := s.Layout.Validate(); err != nil {
+		return err
+	}
+	if s.Epoch <= 0 {
+		return newConfigError(
+			"Epoch",
+			fmt.Sprintf("%d", s.Epoch),
+			"must be positive",
+			"epoch timestamp in milliseconds must be > 0",
+		)
+	}
+	return nil
+}
+
+// normalized returns the scheme with a zero-valued layout replaced by LayoutDefault.
+func (s Scheme) normalized() Scheme {
+	if s.Layout.TimestampBits == 0 && s.Layout.WorkerBits == 0 && s.Layout.SequenceBits == 0 {
+		s.Layout = LayoutDefault
+	}
+	return s
+}
+
+// Scheme returns the layout and epoch this generator composes IDs with.
+//
+// Example:
+//
+//	scheme := gen.Scheme()
+//	first, last := scheme.MinIDForTime(start), scheme.MaxIDForTime(end)
+func (g *Generator) Scheme() Scheme {
+	return Scheme{Layout: g.layout, Epoch: g.epochMillis}
+}
+
+// ============================================================================
+// Time to ID Conversion
+// ============================================================================
+
+// timeUnits converts a wall-clock time to the scheme's timestamp field value.
+//
+// This mirrors currentTimestamp and NewWithConfig: both the time and the epoch are
+// truncated to whole time units before subtracting, so a time anywhere inside a
+// unit maps to the same field value the generator would have used.
+func (s Scheme) timeUnits(t time.Time) int64 {
+	return unitsAt(t, s.Layout.TimeUnit) - s.epochUnits()
+}
+
+// epochUnits returns the epoch in whole time units since the Unix epoch, as
+// NewWithConfig computes it.
+func (s Scheme) epochUnits() int64 {
+	return unitsAt(time.UnixMilli(s.Epoch), s.Layout.TimeUnit)
+}
+
+// maxTimeUnits returns the largest timestamp field value the layout can hold.
+func (s Scheme) maxTimeUnits() int64 {
+	return (int64(1) << s.Layout.TimestampBits) - 1
+}
+
+// clampTimeUnits limits a timestamp field value to the representable range.
+func (s Scheme) clampTimeUnits(units int64) int64 {
+	if units < 0 {
+		return 0
+	}
+	if maxUnits := s.maxTimeUnits(); units > maxUnits {
+		return maxUnits
+	}
+	return units
+}
+
+// MinIDForTime returns the smallest ID any worker could generate at time t.
+//
+// Because IDs only carry time-unit precision, the result is the first ID of the
+// unit containing t (worker 0, sequence 0), so IDs generated earlier within the
+// same unit compare greater than or equal to it. Times before the epoch or past
+// the end of the layout's lifespan are clamped to the first or last unit.
+//
+// Performance: ~20ns (arithmetic only)
+//
+// Example:
+//
+//	since := snowflake.DefaultScheme.MinIDForTime(time.Now().Add(-time.Hour))
+//	db.Query("SELECT * FROM events WHERE id >= ?", since)
+func (s Scheme) MinIDForTime(t time.Time) ID {
+	s = s.normalized()
+	timestampShift, _, _, _ := s.Layout.CalculateShifts()
+	return ID(s.clampTimeUnits(s.timeUnits(t)) << timestampShift)
+}
+
+// MaxIDForTime returns the largest ID any worker could generate at time t.
+//
+// This is the last ID of the unit containing t: every worker and sequence bit set.
+// Times outside the representable range are clamped like MinIDForTime.
+//
+// Performance: ~20ns (arithmetic only)
+//
+// Example:
+//
+//	until := snowflake.DefaultScheme.MaxIDForTime(cutoff)
+//	db.Exec("DELETE FROM events WHERE id <= ?", until)
+func (s Scheme) MaxIDForTime(t time.Time) ID {
+	s = s.normalized()
+	timestampShift, _, _, _ := s.Layout.CalculateShifts()
+	lowBits := (int64(1) << timestampShift) - 1
+	return ID(s.clampTimeUnits(s.timeUnits(t))<<timestampShift | lowBits)
+}
+
+// IDRangeForWindow returns the inclusive ID range covering every ID generated
+// between start and end (inclusive), suitable for "WHERE id BETWEEN first AND last".
+//
+// The range is widened to whole time units at both ends, so with a 10ms time unit
+// a window starting at 12:00:00.005 includes IDs from 12:00:00.000.
+//
+// Returns ErrInvalidTimeRange if end is before start, or if the window lies
+// entirely before the epoch or after the layout's lifespan.
+//
+// Example:
+//
+//	first, last, err := gen.Scheme().IDRangeForWindow(start, end)
+//	if err != nil {
+//	    return err
+//	}
+//	rows, err := db.Query("SELECT * FROM events WHERE id BETWEEN ? AND ?", first, last)
+func (s Scheme) IDRangeForWindow(start, end time.Time) (first, last ID, err error) {
+	s = s.normalized()
+	if end.Before(start) {
+		return 0, 0, fmt.Errorf("%w: end %s is before start %s",
+			ErrInvalidTimeRange, end.Format(time.RFC3339Nano), start.Format(time.RFC3339Nano))
+	}
+	if s.timeUnits(end) < 0 {
+		return 0, 0, fmt.Errorf("%w: window ends before epoch %s",
+			ErrInvalidTimeRange, time.UnixMilli(s.Epoch).UTC().Format(time.RFC3339))
+	}
+	if s.timeUnits(start) > s.maxTimeUnits() {
+		return 0, 0, fmt.Errorf("%w: window starts after the layout's lifespan", ErrInvalidTimeRange)
+	}
+	return s.MinIDForTime(start), s.MaxIDForTime(end), nil
+}
+
+// MinIDForTime returns the smallest ID that could be generated at time t
+// using DefaultScheme. See Scheme.MinIDForTime.
+func MinIDForTime(t time.Time) ID {
+	return DefaultScheme.MinIDForTime(t)
+}
+
+// MaxIDForTime returns the largest ID that could be generated at time t
+// using DefaultScheme. See Scheme.MaxIDForTime.
+func MaxIDForTime(t time.Time) ID {
+	return DefaultScheme.MaxIDForTime(t)
+}
+
+// IDRangeForWindow returns the inclusive ID range for a time window using
+// DefaultScheme. See Scheme.IDRangeForWindow.
+func IDRangeForWindow(start, end time.Time) (first, last ID, err error) {
+	return DefaultScheme.IDRangeForWindow(start, end)
+}
+
+// ============================================================================
+// ID Decoding
+// ============================================================================
+
+// Time returns the start of the time unit an ID was generated in.
+//
+// Unlike ID.TimeWithLayout, this honors the scheme's custom epoch.
+//
+// Example:
+//
+//	createdAt := gen.Scheme().Time(id)
+func (s Scheme) Time(id ID) time.Time {
+	s = s.normalized()
+	timestampShift, _, _, _ := s.Layout.CalculateShifts()
+	return unitTime((int64(id)>>timestampShift)+s.epochUnits(), s.Layout.TimeUnit)
+}
+
+// Components extracts timestamp (milliseconds since Unix epoch), worker ID and
+// sequence from an ID using the scheme's layout and epoch. With sub-millisecond
+// time units the timestamp is truncated to the millisecond; use Time for full
+// precision.
+//
+// Example:
+//
+//	ts, worker, seq := gen.Scheme().Components(id)
+func (s Scheme) Components(id ID) (timestamp int64, workerID int64, sequence int64) {
+	s = s.normalized()
+	_, workerShift, maxWorker, maxSequence := s.Layout.CalculateShifts()
+
+	timestamp = s.Time(id).UnixMilli()
+	workerID = (int64(id) >> workerShift) & maxWorker
+	sequence = int64(id) & maxSequence
+	return
+}
+
+// floorDiv divides rounding toward negative infinity, so pre-1970 times map to
+// the correct time unit.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// unitsAt returns the number of whole time units between the Unix epoch and t,
+// rounded toward negative infinity.
+//
+// Units that divide or are multiples of a second avoid int64 nanoseconds, so
+// any time is exact. Other units split the seconds into whole periods of
+// lcm(unit, 1s) first, so they are exact for any time too.
+func unitsAt(t time.Time, unit time.Duration) int64 {
+	switch {
+	case time.Second%unit == 0:
+		return t.Unix()*int64(time.Second/unit) + int64(t.Nanosecond())/int64(unit)
+	case unit%time.Second == 0:
+		return floorDiv(t.Unix(), int64(unit/time.Second))
+	default:
+		// period seconds hold exactly perPeriod units
+		g := gcd(int64(unit), int64(time.Second))
+		period, perPeriod := int64(unit)/g, int64(time.Second)/g
+		periods := floorDiv(t.Unix(), period)
+		rest := (t.Unix()-periods*period)*int64(time.Second) + int64(t.Nanosecond())
+		return periods*perPeriod + rest/int64(unit)
+	}
+}
+
+// unitTime returns the start of time unit n after the Unix epoch; the inverse
+// of unitsAt.
+func unitTime(n int64, unit time.Duration) time.Time {
+	switch {
+	case time.Second%unit == 0:
+		perSecond := int64(time.Second / unit)
+		return time.Unix(n/perSecond, n%perSecond*int64(unit))
+	case unit%time.Second == 0:
+		return time.Unix(n*int64(unit/time.Second), 0)
+	default:
+		// Split n so neither product overflows: n*unit ns = q*unit s + r*unit ns
+		q, r := n/int64(time.Second), n%int64(time.Second)
+		return time.Unix(q*int64(unit), r*int64(unit))
+	}
+}
+
+// gcd returns the greatest common divisor of two positive integers.
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// unitsDuration returns n time units as a Duration, saturating at the largest
+// Duration (about 292 years) instead of overflowing.
+func unitsDuration(n int64, unit time.Duration) time.Duration {
+	if n > 0 && n > math.MaxInt64/int64(unit) {
+		return math.MaxInt64
+	}
+	return time.Duration(n) * unit
+}
@@ -0,0 +1,339 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Scheme Validation Tests
+// ============================================================================
+
+func TestNewScheme(t *testing.T) {
+	s, err := NewScheme(BitLayout{}, Epoch)
+	if err != nil {
+		t.Fatalf("NewScheme() error = %v", err)
+	}
+	if s.Layout != LayoutDefault {
+		t.Errorf("zero layout should default to LayoutDefault, got %+v", s.Layout)
+	}
+
+	if _, err := NewScheme(LayoutDefault, 0); !IsConfigError(err) {
+		t.Errorf("NewScheme(epoch=0) error = %v, want ConfigError", err)
+	}
+
+	bad := BitLayout{TimestampBits: 40, WorkerBits: 10, SequenceBits: 10, TimeUnit: time.Millisecond}
+	if _, err := NewScheme(bad, Epoch); !errors.Is(err, ErrInvalidBitLayout) {
+		t.Errorf("NewScheme(bad layout) error = %v, want ErrInvalidBitLayout", err)
+	}
+}
+
+func TestGenerator_Scheme(t *testing.T) {
+	cfg := DefaultConfig(7)
+	cfg.Layout = LayoutSuperior
+	cfg.Epoch = 1600000000000
+	gen, _ := NewWithConfig(cfg)
+
+	s := gen.Scheme()
+	if s.Layout != LayoutSuperior || s.Epoch != 1600000000000 {
+		t.Errorf("Scheme() = %+v, want LayoutSuperior with epoch 1600000000000", s)
+	}
+}
+
+// ============================================================================
+// Time to ID Range Tests
+// ============================================================================
+
+func TestScheme_RangeContainsGeneratedIDs(t *testing.T) {
+	layouts := []struct {
+		name   string
+		layout BitLayout
+		epoch  int64
+	}{
+		{"LayoutDefault", LayoutDefault, Epoch},
+		{"LayoutSuperior", LayoutSuperior, Epoch},
+		{"LayoutUltimate", LayoutUltimate, Epoch},
+		{"LayoutSonyflake custom epoch", LayoutSonyflake, 1600000000005},
+	}
+
+	for _, tt := range layouts {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig(3)
+			cfg.Layout = tt.layout
+			cfg.Epoch = tt.epoch
+			gen, err := NewWithConfig(cfg)
+			if err != nil {
+				t.Fatalf("NewWithConfig() error = %v", err)
+			}
+			scheme := gen.Scheme()
+
+			start := time.Now()
+			ids := make([]ID, 500)
+			for i := range ids {
+				ids[i] = gen.MustGenerateID()
+			}
+			end := time.Now()
+
+			first, last, err := scheme.IDRangeForWindow(start, end)
+			if err != nil {
+				t.Fatalf("IDRangeForWindow() error = %v", err)
+			}
+			for _, id := range ids {
+				if id < first || id > last {
+					t.Fatalf("ID %d outside range [%d, %d]", id, first, last)
+				}
+			}
+
+			// IDs from before the window must sort below it
+			if before := scheme.MaxIDForTime(start.Add(-time.Second)); before >= first {
+				t.Errorf("MaxIDForTime(start-1s) = %d, should be < %d", before, first)
+			}
+		})
+	}
+}
+
+func TestScheme_TimeUnitRounding(t *testing.T) {
+	s := Scheme{Layout: LayoutUltimate, Epoch: Epoch} // 10ms units
+	unitStart := time.UnixMilli(Epoch + 123450)
+	mid := unitStart.Add(7 * time.Millisecond)
+	next := unitStart.Add(10 * time.Millisecond)
+
+	if s.MinIDForTime(mid) != s.MinIDForTime(unitStart) {
+		t.Errorf("MinIDForTime should round down to the unit start")
+	}
+	if s.MaxIDForTime(mid) != s.MaxIDForTime(unitStart) {
+		t.Errorf("MaxIDForTime should cover the whole unit")
+	}
+	if s.MaxIDForTime(mid)+1 != s.MinIDForTime(next) {
+		t.Errorf("MaxIDForTime(t)+1 = %d, want MinIDForTime(next unit) = %d",
+			s.MaxIDForTime(mid)+1, s.MinIDForTime(next))
+	}
+
+	// Components of the bounds decode back to the unit start
+	ts, worker, seq := s.Components(s.MinIDForTime(mid))
+	if ts != unitStart.UnixMilli() || worker != 0 || seq != 0 {
+		t.Errorf("Components(min) = (%d, %d, %d), want (%d, 0, 0)", ts, worker, seq, unitStart.UnixMilli())
+	}
+	ts, worker, seq = s.Components(s.MaxIDForTime(mid))
+	if ts != unitStart.UnixMilli() || worker != 65535 || seq != 127 {
+		t.Errorf("Components(max) = (%d, %d, %d), want (%d, 65535, 127)", ts, worker, seq, unitStart.UnixMilli())
+	}
+}
+
+func TestScheme_Clamping(t *testing.T) {
+	s := DefaultScheme
+
+	if got := s.MinIDForTime(time.UnixMilli(Epoch - 1000)); got != 0 {
+		t.Errorf("MinIDForTime(before epoch) = %d, want 0", got)
+	}
+
+	farFuture := time.UnixMilli(Epoch).Add(100 * 365 * 24 * time.Hour)
+	if got := s.MaxIDForTime(farFuture); got != ID(1<<63-1) {
+		t.Errorf("MaxIDForTime(after lifespan) = %d, want max int64", got)
+	}
+}
+
+func TestScheme_IDRangeForWindowErrors(t *testing.T) {
+	now := time.Now()
+	epoch := time.UnixMilli(Epoch)
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{"end before start", now, now.Add(-time.Second)},
+		{"before epoch", epoch.Add(-2 * time.Hour), epoch.Add(-time.Hour)},
+		{"after lifespan", epoch.Add(70 * 365 * 24 * time.Hour), epoch.Add(71 * 365 * 24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := IDRangeForWindow(tt.start, tt.end); !errors.Is(err, ErrInvalidTimeRange) {
+				t.Errorf("IDRangeForWindow() error = %v, want ErrInvalidTimeRange", err)
+			}
+		})
+	}
+
+	// A window straddling the epoch is clamped rather than rejected
+	first, _, err := IDRangeForWindow(epoch.Add(-time.Hour), epoch.Add(time.Hour))
+	if err != nil || first != 0 {
+		t.Errorf("IDRangeForWindow(straddling epoch) = (%d, %v), want (0, nil)", first, err)
+	}
+}
+
+func TestScheme_ComponentsMatchID(t *testing.T) {
+	gen, _ := New(42)
+	id := gen.MustGenerateID()
+
+	ts, worker, seq := DefaultScheme.Components(id)
+	wantTs, wantWorker, wantSeq := id.Components()
+	if ts != wantTs || worker != wantWorker || seq != wantSeq {
+		t.Errorf("Components() = (%d, %d, %d), want (%d, %d, %d)",
+			ts, worker, seq, wantTs, wantWorker, wantSeq)
+	}
+	if !DefaultScheme.Time(id).Equal(id.Time()) {
+		t.Errorf("Time() = %v, want %v", DefaultScheme.Time(id), id.Time())
+	}
+}
+
+// ============================================================================
+// Arbitrary Time Unit Tests
+// ============================================================================
+
+func TestUnitsAt_RoundTrip(t *testing.T) {
+	units := []time.Duration{
+		100 * time.Microsecond, 250 * time.Microsecond, time.Millisecond,
+		1500 * time.Microsecond, 10 * time.Millisecond, 2 * time.Second,
+	}
+	times := []time.Time{
+		time.Date(2024, 3, 5, 10, 20, 30, 123456789, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 999000000, time.UTC),
+		time.UnixMilli(Epoch),
+	}
+	for _, unit := range units {
+		for _, tm := range times {
+			n := unitsAt(tm, unit)
+			start := unitTime(n, unit)
+			if start.After(tm) || !start.Add(unit).After(tm) {
+				t.Errorf("unit %v: unitTime(unitsAt(%v)) = %v, want the start of the unit containing it", unit, tm, start)
+			}
+			if got := unitsAt(start, unit); got != n {
+				t.Errorf("unit %v: unitsAt(%v) = %d, want %d", unit, start, got, n)
+			}
+		}
+	}
+}
+
+func TestUnitTime_FarFuture(t *testing.T) {
+	// Units that neither divide nor are multiples of a second must not
+	// overflow int64 nanoseconds past 2262
+	tests := []struct {
+		layout BitLayout
+		unitMs int64
+	}{
+		{BitLayout{TimestampBits: 42, WorkerBits: 12, SequenceBits: 9, TimeUnit: 3 * time.Millisecond}, 3},
+		{BitLayout{TimestampBits: 41, WorkerBits: 10, SequenceBits: 12, TimeUnit: 7 * time.Millisecond}, 7},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig(1)
+		cfg.Layout = tt.layout
+		gen, err := NewWithConfig(cfg)
+		if err != nil {
+			t.Fatalf("%v: NewWithConfig() error = %v", tt.layout.TimeUnit, err)
+		}
+
+		last := Epoch/tt.unitMs + (int64(1)<<tt.layout.TimestampBits - 1)
+		want := time.UnixMilli(last * tt.unitMs)
+		if got := gen.LifespanInfo().OverflowDate; !got.Equal(want) {
+			t.Errorf("%v/%d bits: OverflowDate = %v, want %v", tt.layout.TimeUnit, tt.layout.TimestampBits, got, want)
+		}
+		if got := unitsAt(want, tt.layout.TimeUnit); got != last {
+			t.Errorf("%v: unitsAt(%v) = %d, want %d", tt.layout.TimeUnit, want, got, last)
+		}
+		if got := unitsAt(want.Add(-time.Nanosecond), tt.layout.TimeUnit); got != last-1 {
+			t.Errorf("%v: unitsAt(%v) = %d, want %d", tt.layout.TimeUnit, want.Add(-time.Nanosecond), got, last-1)
+		}
+	}
+}
+
+func TestGenerator_SubMillisecondTimeUnit(t *testing.T) {
+	for _, unit := range []time.Duration{100 * time.Microsecond, 250 * time.Microsecond, 1500 * time.Microsecond} {
+		t.Run(unit.String(), func(t *testing.T) {
+			layout := BitLayout{TimestampBits: 41, WorkerBits: 10, SequenceBits: 12, TimeUnit: unit}
+			if shift := layout.TimeUnitShift(); shift != -1 {
+				t.Errorf("TimeUnitShift() = %d, want -1", shift)
+			}
+
+			cfg := DefaultConfig(7)
+			cfg.Layout = layout
+			gen, err := NewWithConfig(cfg)
+			if err != nil {
+				t.Fatalf("NewWithConfig() error = %v", err)
+			}
+			scheme := gen.Scheme()
+
+			before := time.Now()
+			id, err := gen.GenerateID()
+			if err != nil {
+				t.Fatalf("GenerateID() error = %v", err)
+			}
+			after := time.Now()
+
+			got := scheme.Time(id)
+			if got.Before(before.Add(-unit)) || got.After(after) {
+				t.Errorf("Scheme.Time() = %v, want within one %v before [%v, %v]", got, unit, before, after)
+			}
+			if time.Second%unit == 0 && got.Nanosecond()%int(unit) != 0 {
+				t.Errorf("Scheme.Time() = %v, not aligned to %v", got, unit)
+			}
+
+			// Default-epoch accessors agree with the scheme
+			if tl := id.TimeWithLayout(layout); !tl.Equal(got) {
+				t.Errorf("TimeWithLayout() = %v, want %v", tl, got)
+			}
+			ts, worker, _ := id.ComponentsWithLayout(layout)
+			if ts != got.UnixMilli() || worker != 7 {
+				t.Errorf("ComponentsWithLayout() = %d, %d; want %d, 7", ts, worker, got.UnixMilli())
+			}
+			if ms := id.TimestampWithLayout(layout); ms != got.UnixMilli() {
+				t.Errorf("TimestampWithLayout() = %d, want %d", ms, got.UnixMilli())
+			}
+			if first := scheme.MinIDForTime(got); first > id || scheme.MaxIDForTime(got) < id {
+				t.Errorf("ID %d outside [MinIDForTime, MaxIDForTime] of its own time", id)
+			}
+
+			info := gen.LifespanInfo()
+			wantLifespan := time.Duration(1<<41-1) * unit
+			if info.TotalLifespan != wantLifespan {
+				t.Errorf("TotalLifespan = %v, want %v", info.TotalLifespan, wantLifespan)
+			}
+			if want := time.UnixMilli(Epoch).Add(wantLifespan); !info.OverflowDate.Equal(want) {
+				t.Errorf("OverflowDate = %v, want %v", info.OverflowDate, want)
+			}
+		})
+	}
+}
+
+func TestLifespanInfo_LongLifespanSaturates(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.Layout = LayoutMegaScale // 2^40 units of 10ms overflows time.Duration
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := gen.LifespanInfo()
+	if info.TotalLifespan <= 0 || info.Remaining <= 0 {
+		t.Errorf("LifespanInfo() = %+v, want positive saturated durations", info)
+	}
+	if years := info.OverflowDate.Year() - time.UnixMilli(Epoch).Year(); years < 340 || years > 350 {
+		t.Errorf("OverflowDate = %v, want about 348 years after the epoch", info.OverflowDate)
+	}
+}
+
+func TestScheme_SQLExpr_SubMillisecond(t *testing.T) {
+	s := Scheme{Layout: BitLayout{TimestampBits: 41, WorkerBits: 10, SequenceBits: 12, TimeUnit: 250 * time.Microsecond}, Epoch: Epoch}
+	expr, err := s.SQLExpr(DialectPostgres, SQLFuncTime, "id")
+	if err != nil {
+		t.Fatalf("SQLExpr() error = %v", err)
+	}
+	want := "to_timestamp((((id >> 22) + 6816268800000) * 250) / 1000000.0)"
+	if expr != want {
+		t.Errorf("SQLExpr() = %s, want %s", expr, want)
+	}
+
+	// MySQL adds the microseconds as an interval instead of dividing
+	expr, err = s.SQLExpr(DialectMySQL, SQLFuncTime, "id")
+	if err != nil {
+		t.Fatalf("SQLExpr() error = %v", err)
+	}
+	want = "FROM_UNIXTIME((((id >> 22) + 6816268800000) * 250) DIV 1000000) + INTERVAL ((((id >> 22) + 6816268800000) * 250) MOD 1000000) MICROSECOND"
+	if expr != want {
+		t.Errorf("SQLExpr(mysql) = %s, want %s", expr, want)
+	}
+
+	s.Layout.TimeUnit = 1500 * time.Nanosecond
+	if _, err := s.SQLExpr(DialectPostgres, SQLFuncTime, "id"); !errors.Is(err, ErrInvalidBitLayout) {
+		t.Errorf("SQLExpr(1.5µs unit) error = %v, want ErrInvalidBitLayout", err)
+	}
+}
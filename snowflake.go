@@ -31,7 +31,7 @@
 //   - Single-threaded: ~450ns per ID (~2.2M IDs/sec)
 //   - Concurrent: ~380ns per ID (~2.6M IDs/sec per goroutine)
 //   - Max throughput: 4.096M IDs/sec per worker (4096 per millisecond)
-//   - Memory: ~200 bytes per Generator, zero allocations in hot path
+//   - Memory: ~1.4KB per Generator (mostly the three metrics histograms), zero allocations in hot path
 //
 // # Usage
 //
@@ -52,6 +52,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -143,7 +144,8 @@ type Config struct {
 	// Default: 5 milliseconds
 	MaxClockBackward time.Duration
 
-	// EnableMetrics determines whether to collect internal metrics.
+	// EnableMetrics determines whether to collect latency and wait-time
+	// histograms (see MetricsSnapshot). Counters in Metrics are always kept.
 	// Metrics use atomic operations and have negligible performance impact.
 	// Default: true
 	EnableMetrics bool
@@ -164,6 +166,58 @@ type Config struct {
 	// IMPORTANT: IDs generated with different layouts are incompatible.
 	// Choose once and stick with it for the lifetime of your system.
 	Layout BitLayout
+
+	// MaxBorrowAhead enables burst mode: when the sequence overflows, the
+	// generator moves on to the next time unit immediately instead of waiting
+	// for the clock, as long as it stays at most this far ahead of the clock.
+	// Bursts are absorbed without blocking and the clock catches up afterward.
+	//
+	// Borrowed IDs carry timestamps slightly in the future. A restarted
+	// process with the same worker ID must not start generating until the
+	// clock has passed the last borrowed time unit, so keep this small
+	// relative to restart time.
+	//
+	// Must be 0 (disabled) or at least one Layout.TimeUnit.
+	// Default: 0 (disabled)
+	MaxBorrowAhead time.Duration
+
+	// MaxWait bounds how long generating one ID (or one Reserve block) may
+	// wait for the clock, covering both small backward drift and sequence
+	// overflow. When a wait would exceed it, generation fails immediately with
+	// a *ClockError or *OverflowError instead, letting callers shed load.
+	//
+	// Default: 0 (no limit beyond the context)
+	MaxWait time.Duration
+
+	// Observer receives clock, overflow and lifespan incidents as they happen.
+	// Callbacks run on the generating goroutine after the generator's lock is
+	// released, so they may be slow or call back into the generator, but they
+	// add latency to the call that triggered them. Use NewChannelObserver to
+	// hand events off to another goroutine.
+	//
+	// Default: nil (no events)
+	Observer Observer
+
+	// Logger receives generator diagnostics: clock recovery and failures,
+	// lifespan warnings, timestamp overflow and clock drift (see
+	// SlogObserver for levels). Records carry a "generator" attribute when
+	// Name is set. It is used in addition to Observer.
+	//
+	// Default: nil (no logging)
+	Logger *slog.Logger
+
+	// Name identifies the generator in exported metrics (the "name" label of
+	// MetricsHandler) when one process runs several generators, for example
+	// "orders" and "users".
+	//
+	// Default: "" (unnamed)
+	Name string
+
+	// Health sets the thresholds Generator.Health and the probe handlers
+	// check against.
+	//
+	// Default: zero value (see HealthConfig for per-field defaults)
+	Health HealthConfig
 }
 
 // DefaultConfig returns a Config with production-ready defaults.
@@ -190,6 +244,8 @@ func DefaultConfig(workerID int64) Config {
 //   - WorkerID must be in range allowed by layout
 //   - Epoch must be positive
 //   - MaxClockBackward must be non-negative
+//   - MaxBorrowAhead must be 0 or at least one time unit
+//   - MaxWait must be non-negative
 //
 // Returns ConfigError with detailed context for easier debugging.
 func (c *Config) Validate() error {
@@ -230,6 +286,30 @@ func (c *Config) Validate() error {
 			"duration must be >= 0",
 		)
 	}
+	if c.Health.Window < 0 {
+		return newConfigError(
+			"Health.Window",
+			c.Health.Window.String(),
+			"must be non-negative",
+			"duration must be >= 0 (0 = default)",
+		)
+	}
+	if c.MaxWait < 0 {
+		return newConfigError(
+			"MaxWait",
+			c.MaxWait.String(),
+			"must be non-negative",
+			"duration must be >= 0 (0 = no limit)",
+		)
+	}
+	if c.MaxBorrowAhead != 0 && c.MaxBorrowAhead < c.Layout.TimeUnit {
+		return newConfigError(
+			"MaxBorrowAhead",
+			c.MaxBorrowAhead.String(),
+			"must be 0 or at least one time unit",
+			fmt.Sprintf("0 (disabled) or >= %v", c.Layout.TimeUnit),
+		)
+	}
 	return nil
 }
 
@@ -241,8 +321,12 @@ type Metrics struct {
 	Generated        int64 // Total IDs successfully generated
 	ClockBackward    int64 // Clock backward events (including recovered ones)
 	ClockBackwardErr int64 // Clock backward errors (exceeded tolerance, ID not generated)
-	SequenceOverflow int64 // Sequence exhaustion events (had to wait for next millisecond)
+	SequenceOverflow int64 // Sequence exhaustion events (waited for or borrowed the next time unit)
 	WaitTimeUs       int64 // Total time spent waiting (in microseconds)
+	Borrowed         int64 // Time units borrowed ahead of the clock (see Config.MaxBorrowAhead)
+	LeadUs           int64 // Current lead of borrowed timestamps over the clock (gauge, in microseconds)
+	ClockOffsetUs    int64 // Wall clock minus generator clock at the last DriftMonitor check (gauge, in microseconds)
+	Reanchors        int64 // Times a DriftMonitor re-anchored the generator's clock
 }
 
 // LifespanInfo provides comprehensive information about timestamp utilization and lifespan.
@@ -261,15 +345,15 @@ type Metrics struct {
 //	}
 type LifespanInfo struct {
 	// Utilization is the percentage of timestamp range used (0.0-1.0).
-	// Example: 0.25 means 25% of the 69-year lifespan has been used.
+	// Example: 0.25 means 25% of the layout's lifespan has been used.
 	Utilization float64
 
 	// Remaining is the time until timestamp overflow.
 	// This is the duration from now until the timestamp bits are exhausted.
 	Remaining time.Duration
 
-	// TotalLifespan is the total possible lifespan (~69.73 years).
-	// This is determined by the 41-bit timestamp field.
+	// TotalLifespan is the total possible lifespan (~69.73 years for LayoutDefault).
+	// This is determined by the layout's timestamp bits and time unit.
 	TotalLifespan time.Duration
 
 	// CurrentAge is the time elapsed since the epoch.
@@ -281,7 +365,8 @@ type LifespanInfo struct {
 	OverflowDate time.Time
 
 	// IsApproaching indicates if utilization exceeds the warning threshold (80%).
-	// Applications should monitor this and plan for migration to a new epoch.
+	// Applications should monitor this and plan for migration to a new epoch
+	// with PlanEpoch.
 	IsApproaching bool
 }
 
@@ -307,15 +392,33 @@ type LifespanInfo struct {
 // Memory layout is optimized for cache efficiency:
 //   - Hot path fields (mu, sequence, lastTimestamp) grouped together
 //   - Atomic metrics separated to avoid false sharing
-//   - Total size: ~200 bytes including atomics
+//   - Total size: ~1.4KB including atomics and the three ~330-byte histograms
 type Generator struct {
 	mu               sync.Mutex    // Protects mutable state (sequence, lastTimestamp)
 	epoch            time.Time     // Monotonic clock reference (set at initialization)
 	customEpoch      int64         // Custom epoch in milliseconds
 	workerID         int64         // Worker ID for this generator
+	name             string        // Name for exported metrics (Config.Name)
 	sequence         int64         // Current sequence number within this time unit
-	lastTimestamp    int64         // Last timestamp we generated an ID for
+	lastTimestamp    int64         // Last timestamp we generated an ID for (may be borrowed ahead of the clock)
+	lastClock        int64         // Highest clock reading seen, in time units
 	maxClockBackward time.Duration // Maximum tolerable clock drift
+	maxBorrowAhead   int64         // Maximum lead over the clock in time units (0 = never borrow)
+	maxWait          time.Duration // Maximum wait for the clock per ID (0 = no limit)
+	maxTimestamp     int64         // Largest timestamp the layout can hold, relative to the epoch
+	warnTimestamp    int64         // Relative timestamp at which the lifespan warning fires
+	lifespanWarned   bool          // Whether OnLifespanThreshold has been reported
+
+	// Incident reporting (see observer.go)
+	observer Observer         // Receives incident events (nil = none)
+	pending  []func(Observer) // Events recorded under mu, delivered after unlock
+
+	// Clock anchoring (see drift.go)
+	anchorShift atomic.Int64 // Nanoseconds added to the clock by DriftMonitor re-anchoring
+
+	// Health checks (see health.go)
+	healthConfig HealthConfig  // Thresholds with defaults applied
+	health       healthTracker // Recent counter readings for rates
 
 	// Pre-calculated layout constants (zero runtime cost after initialization)
 	timestampShift int           // Bits to shift timestamp left
@@ -324,6 +427,8 @@ type Generator struct {
 	maxSequence    int64         // Maximum sequence value for this layout
 	timeUnit       time.Duration // Time unit for timestamp precision
 	timeUnitShift  int8          // Bitshift for time unit conversion (or -1 for division)
+	layout         BitLayout     // Layout the constants above were derived from
+	epochMillis    int64         // Custom epoch in milliseconds (as configured)
 
 	// Metrics counters using atomic operations for lock-free reads.
 	// These are separated from hot path fields to avoid false sharing on the same cache line.
@@ -332,6 +437,16 @@ type Generator struct {
 	clockBackwardErr atomic.Int64 // Counter: clock backward errors
 	sequenceOverflow atomic.Int64 // Counter: sequence overflows
 	waitTimeUs       atomic.Int64 // Counter: total wait time in microseconds
+	borrowed         atomic.Int64 // Counter: time units borrowed ahead of the clock
+	reanchors        atomic.Int64 // Counter: clock re-anchors by a DriftMonitor
+	clockOffsetUs    atomic.Int64 // Gauge: wall clock minus generator clock, from the last DriftMonitor check
+	borrowedUntil    atomic.Int64 // Gauge source: last borrowed timestamp, for the lead metric
+
+	// Duration histograms, recorded only when metricsEnabled (see histogram.go)
+	metricsEnabled bool
+	latency        histogram // Per-call generation latency
+	overflowWait   histogram // Waits for the next time unit after sequence overflow
+	clockWait      histogram // Waits for the clock after backward drift
 }
 
 // New creates a new Snowflake ID generator with default configuration.
@@ -405,23 +520,36 @@ func NewWithConfig(cfg Config) (*Generator, error) {
 	// This enables bitshift instead of division for power-of-2 time units
 	timeUnitShift := cfg.Layout.TimeUnitShift()
 
+	// Largest relative timestamp the layout's timestamp field can hold
+	maxTimestamp := int64(1)<<cfg.Layout.TimestampBits - 1
+
 	// Convert custom epoch from milliseconds to time units
 	// This is crucial for layouts with different time units (e.g., Sonyflake uses 10ms)
-	customEpochInTimeUnits := cfg.Epoch / cfg.Layout.TimeUnit.Milliseconds()
+	customEpochInTimeUnits := unitsAt(time.UnixMilli(cfg.Epoch), cfg.Layout.TimeUnit)
 
 	return &Generator{
 		epoch:            now,
 		customEpoch:      customEpochInTimeUnits, // Now stored in time units, not milliseconds
 		workerID:         cfg.WorkerID,
+		name:             cfg.Name,
 		sequence:         0,
 		lastTimestamp:    0,
 		maxClockBackward: cfg.MaxClockBackward,
+		maxBorrowAhead:   int64(cfg.MaxBorrowAhead / cfg.Layout.TimeUnit),
+		maxWait:          cfg.MaxWait,
+		maxTimestamp:     maxTimestamp,
+		warnTimestamp:    int64(float64(maxTimestamp) * TimestampWarningThreshold),
+		observer:         generatorObserver(cfg),
+		healthConfig:     cfg.Health.withDefaults(),
+		metricsEnabled:   cfg.EnableMetrics,
 		timestampShift:   timestampShift,
 		workerShift:      workerShift,
 		maxWorker:        maxWorker,
 		maxSequence:      maxSequence,
 		timeUnit:         cfg.Layout.TimeUnit,
 		timeUnitShift:    timeUnitShift,
+		layout:           cfg.Layout,
+		epochMillis:      cfg.Epoch,
 	}, nil
 }
 
@@ -459,6 +587,46 @@ func (g *Generator) GenerateIDWithContext(ctx context.Context) (ID, error) {
 	return ID(id), err
 }
 
+// TryGenerateID creates a new Snowflake ID without waiting for the clock.
+//
+// Where GenerateID would wait, TryGenerateID fails instead:
+//   - Sequence exhausted for the current time unit: *OverflowError
+//     (errors.Is(err, ErrSequenceOverflow))
+//   - Clock moved backward, even within MaxClockBackward: *ClockError
+//     (errors.Is(err, ErrClockMovedBack))
+//
+// No ID is consumed on failure, so the caller may retry later or fall back to
+// GenerateID; the failure is still counted in Metrics and reported to the
+// Observer. Time units borrowed in burst mode (Config.MaxBorrowAhead) need no
+// wait and are used as usual.
+//
+// Performance: same as GenerateID on success
+// Thread-safe: Yes, uses mutex internally
+//
+// Example:
+//
+//	id, err := gen.TryGenerateID()
+//	if snowflake.IsOverflowError(err) {
+//	    return http.StatusTooManyRequests // Shed load instead of queueing
+//	}
+func (g *Generator) TryGenerateID() (ID, error) {
+	if g.metricsEnabled {
+		defer g.observeLatency(time.Now())
+	}
+
+	g.mu.Lock()
+	defer g.unlockAndNotify()
+
+	id, err := g.nextIDLocked(context.Background(), waitBudget{limited: true})
+	if err != nil {
+		return 0, err
+	}
+
+	g.generated.Add(1)
+
+	return ID(id), nil
+}
+
 // Generate creates a new Snowflake ID (returns int64 for backward compatibility).
 //
 // For new code, prefer GenerateID() which returns the ID type with encoding methods.
@@ -507,8 +675,13 @@ func (g *Generator) GenerateWithContext(ctx context.Context) (int64, error) {
 //
 // No allocations in hot path, all operations on stack.
 func (g *Generator) generateInt64WithContext(ctx context.Context) (int64, error) {
+	// Deferred first so it runs last, after the lock is released
+	if g.metricsEnabled {
+		defer g.observeLatency(time.Now())
+	}
+
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	defer g.unlockAndNotify()
 
 	// Fast path: check context cancellation before any work
 	select {
@@ -517,38 +690,170 @@ func (g *Generator) generateInt64WithContext(ctx context.Context) (int64, error)
 	default:
 	}
 
+	id, err := g.nextIDLocked(ctx, g.waitBudget())
+	if err != nil {
+		return 0, err
+	}
+
+	// Update metrics atomically (lock-free)
+	g.generated.Add(1)
+
+	return id, nil
+}
+
+// nextIDLocked advances the generator state by one sequence and composes the ID.
+//
+// The caller must hold g.mu. This is the single implementation of the core
+// algorithm shared by GenerateID, TryGenerateID and GenerateBatch; it does not
+// update the generated counter so batch callers can add the total once.
+//
+// When an error is returned, no ID has been handed out by this call and
+// g.sequence and g.lastTimestamp are not written. Other state may still have
+// changed: g.lastClock can advance to a newer clock reading, g.borrowedUntil,
+// the clock-backward and overflow counters and wait metrics can be updated,
+// and observer events for the incident are already queued. If the budget
+// allows waits to release g.mu, other callers may also have generated IDs
+// in the meantime.
+func (g *Generator) nextIDLocked(ctx context.Context, budget waitBudget) (int64, error) {
+	timestamp, err := g.currentTimestampLocked(ctx, &budget)
+	if err != nil {
+		return 0, err
+	}
+
+	// New time unit: sequence starts at 0
+	sequence := int64(0)
+
+	// Same time unit as last ID: increment sequence
+	if timestamp == g.lastTimestamp {
+		// Use bitwise AND with maxSequence to wrap around
+		// This is equivalent to (sequence + 1) % maxSequence but faster
+		sequence = (g.sequence + 1) & g.maxSequence
+
+		// Sequence overflow: exhausted all IDs for this time unit
+		if sequence == 0 {
+			g.sequenceOverflow.Add(1)
+			timestamp, err = g.nextTimeUnitLocked(ctx, &budget)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := g.checkTimestampLocked(timestamp); err != nil {
+		return 0, err
+	}
+
+	g.sequence = sequence
+	g.lastTimestamp = timestamp
+
+	return g.composeID(timestamp, sequence), nil
+}
+
+// checkTimestampLocked rejects timestamps beyond the layout's timestamp field
+// and reports crossing the lifespan warning threshold to the observer once.
+//
+// The caller must hold g.mu.
+func (g *Generator) checkTimestampLocked(timestamp int64) error {
+	elapsed := timestamp - g.customEpoch
+	if elapsed > g.maxTimestamp {
+		err := newTimestampOverflowError(unitTime(timestamp, g.timeUnit).UnixMilli(), g.workerID)
+		g.notifyLocked(func(o Observer) { o.OnTimestampOverflow(g.workerID, err) })
+		return err
+	}
+	if elapsed >= g.warnTimestamp && !g.lifespanWarned {
+		g.lifespanWarned = true
+		g.notifyLocked(func(o Observer) { o.OnLifespanThreshold(g.workerID, g.LifespanInfo()) })
+	}
+	return nil
+}
+
+// composeID builds an ID from a timestamp in time units and a sequence number.
+//
+// Uses pre-calculated shifts for zero runtime overhead:
+// timestamp goes in upper bits (position determined by timestampShift),
+// workerID in middle bits (position determined by workerShift),
+// sequence in lower bits (no shift needed).
+// NOTE: Both timestamp and customEpoch are in time units (not milliseconds)
+func (g *Generator) composeID(timestamp, sequence int64) int64 {
+	return ((timestamp - g.customEpoch) << g.timestampShift) | // Shift relative timestamp to upper bits
+		(g.workerID << g.workerShift) | // Shift worker ID to middle bits
+		sequence // Sequence in lower bits (no shift needed)
+}
+
+// currentTimestampLocked returns the current timestamp in time units, waiting
+// out backward clock drift within tolerance.
+//
+// The caller must hold g.mu. The returned timestamp is never earlier than
+// g.lastTimestamp; if the clock is further behind than MaxClockBackward a
+// *ClockError is returned instead.
+//
+// Drift is measured against the highest clock reading seen rather than
+// g.lastTimestamp, so time units borrowed in burst mode are not mistaken for
+// the clock moving backward. While the clock is still behind a borrowed
+// timestamp, the borrowed timestamp is returned.
+//
+// Waits are charged to budget; drift that would take longer to wait out than
+// the budget allows is reported as a *ClockError without waiting.
+func (g *Generator) currentTimestampLocked(ctx context.Context, budget *waitBudget) (int64, error) {
 	// Get current timestamp using monotonic clock
 	timestamp := g.currentTimestamp()
 
+	// Without borrowing, lastClock >= lastTimestamp and this is lastTimestamp
+	reference := min(g.lastTimestamp, g.lastClock)
+
 	// Clock drift handling: if clock moved backward, try to recover
-	if timestamp < g.lastTimestamp {
+	if timestamp < reference {
 		g.clockBackward.Add(1)
 
-		diff := g.lastTimestamp - timestamp
+		diff := reference - timestamp
 
-		// Convert tolerance to time units for comparison
-		toleranceInTimeUnits := g.maxClockBackward.Milliseconds() / g.timeUnit.Milliseconds()
+		// Convert tolerance to time units for comparison, rounding up: drift
+		// within the tolerance can move the clock back across at most this
+		// many unit boundaries
+		toleranceInTimeUnits := int64((g.maxClockBackward + g.timeUnit - 1) / g.timeUnit)
 
-		// If drift is small (within tolerance), wait it out
-		if diff <= toleranceInTimeUnits {
+		// If drift is small (within tolerance) and the budget allows, wait it out
+		if diff <= toleranceInTimeUnits && budget.take(time.Duration(diff)*g.timeUnit) {
 			waitStart := time.Now()
 			sleepDuration := time.Duration(diff) * g.timeUnit
 
+			// Unlock mutex during sleep to allow other operations
+			if budget.unlock {
+				g.mu.Unlock()
+			}
+			canceled := false
 			select {
 			case <-time.After(sleepDuration):
-				timestamp = g.currentTimestamp()
-				g.waitTimeUs.Add(time.Since(waitStart).Microseconds())
 			case <-ctx.Done():
+				canceled = true
+			}
+			if budget.unlock {
+				g.mu.Lock()
+				// Other callers may have advanced the state meanwhile
+				reference = max(reference, min(g.lastTimestamp, g.lastClock))
+			}
+			if canceled {
+				g.notifyClockBackwardLocked(diff, false)
 				return 0, ErrContextCanceled
 			}
+
+			timestamp = g.currentTimestamp()
+			waited := time.Since(waitStart)
+			g.waitTimeUs.Add(waited.Microseconds())
+			if g.metricsEnabled {
+				g.clockWait.observe(waited)
+			}
 		}
 
+		recovered := timestamp >= reference
+		g.notifyClockBackwardLocked(diff, recovered)
+
 		// Still behind after waiting? Clock issue is too severe
-		if timestamp < g.lastTimestamp {
+		if !recovered {
 			g.clockBackwardErr.Add(1)
 			return 0, newClockError(
-				timestamp,
-				g.lastTimestamp,
+				unitTime(timestamp, g.timeUnit).UnixMilli(),
+				unitTime(reference, g.timeUnit).UnixMilli(),
 				g.maxClockBackward.Milliseconds(),
 				g.workerID,
 				false, // Not recovered
@@ -556,42 +861,48 @@ func (g *Generator) generateInt64WithContext(ctx context.Context) (int64, error)
 		}
 	}
 
-	// Same time unit as last ID: increment sequence
-	if timestamp == g.lastTimestamp {
-		// Use bitwise AND with maxSequence to wrap around
-		// This is equivalent to (sequence + 1) % maxSequence but faster
-		g.sequence = (g.sequence + 1) & g.maxSequence
+	g.lastClock = max(g.lastClock, timestamp)
 
-		// Sequence overflow: exhausted all IDs for this time unit
-		if g.sequence == 0 {
-			g.sequenceOverflow.Add(1)
-			var err error
-			timestamp, err = g.waitNextMillisWithContext(ctx, timestamp)
-			if err != nil {
-				return 0, err
-			}
-		}
-	} else {
-		// New time unit: reset sequence to 0
-		g.sequence = 0
+	// Clock is behind a borrowed time unit: keep using it
+	if timestamp < g.lastTimestamp {
+		timestamp = g.lastTimestamp
 	}
 
-	g.lastTimestamp = timestamp
+	return timestamp, nil
+}
 
-	// Compose ID using dynamic bitshifting based on layout
-	// Uses pre-calculated shifts for zero runtime overhead
-	// timestamp goes in upper bits (position determined by timestampShift)
-	// workerID goes in middle bits (position determined by workerShift)
-	// sequence goes in lower bits (no shift needed)
-	// NOTE: Both timestamp and customEpoch are in time units (not milliseconds)
-	id := ((timestamp - g.customEpoch) << g.timestampShift) | // Shift relative timestamp to upper bits
-		(g.workerID << g.workerShift) | // Shift worker ID to middle bits
-		g.sequence // Sequence in lower bits (no shift needed)
+// nextTimeUnitLocked moves past g.lastTimestamp after a sequence overflow.
+//
+// In burst mode (MaxBorrowAhead > 0) it borrows the next time unit without
+// waiting if that keeps the lead over the clock within the limit. Otherwise it
+// waits for the clock to pass g.lastTimestamp, or returns an *OverflowError if
+// that wait exceeds budget. The caller must hold g.mu.
+func (g *Generator) nextTimeUnitLocked(ctx context.Context, budget *waitBudget) (int64, error) {
+	next := g.lastTimestamp + 1
+	if g.maxBorrowAhead > 0 && next-g.lastClock <= g.maxBorrowAhead {
+		g.borrowed.Add(1)
+		g.borrowedUntil.Store(next)
+		g.notifySequenceOverflowLocked(0)
+		return next, nil
+	}
 
-	// Update metrics atomically (lock-free)
-	g.generated.Add(1)
+	if !budget.take(g.untilTimeUnit(next)) {
+		g.notifySequenceOverflowLocked(0)
+		return 0, newSequenceOverflowError(unitTime(g.lastTimestamp, g.timeUnit).UnixMilli(), g.maxSequence+1, g.workerID, g.maxSequence, 0)
+	}
 
-	return id, nil
+	waitStart := time.Now()
+	timestamp, err := g.waitNextMillisWithContext(ctx, g.lastClock)
+	waited := time.Since(waitStart)
+	if g.metricsEnabled {
+		g.overflowWait.observe(waited)
+	}
+	g.notifySequenceOverflowLocked(waited)
+	if err != nil {
+		return 0, err
+	}
+	g.lastClock = max(g.lastClock, timestamp)
+	return timestamp, nil
 }
 
 // MustGenerateID generates an ID and panics on error
@@ -639,6 +950,10 @@ func (g *Generator) MustGenerate() int64 {
 // This allows callers to use successfully generated IDs even if the full batch
 // couldn't be completed.
 //
+// The mutex is released while waiting out backward clock drift, so other
+// callers are not blocked for the whole wait; their IDs may then fall between
+// IDs of the batch.
+//
 // Example:
 //
 //	// Generate 1000 IDs at once
@@ -663,7 +978,10 @@ func (g *Generator) GenerateBatch(ctx context.Context, count int) ([]ID, error)
 
 	// Acquire lock once for entire batch
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	defer g.unlockAndNotify()
+
+	// Update metrics once for entire batch, including partial batches
+	defer func() { g.generated.Add(int64(len(ids))) }()
 
 	for i := 0; i < count; i++ {
 		// Check context cancellation periodically (every 100 IDs)
@@ -675,79 +993,18 @@ func (g *Generator) GenerateBatch(ctx context.Context, count int) ([]ID, error)
 			}
 		}
 
-		// Get current timestamp using monotonic clock
-		timestamp := g.currentTimestamp()
-
-		// Clock drift handling: if clock moved backward, try to recover
-		if timestamp < g.lastTimestamp {
-			g.clockBackward.Add(1)
-
-			diff := g.lastTimestamp - timestamp
-
-			// If drift is small (within tolerance), wait it out
-			if diff <= g.maxClockBackward.Milliseconds() {
-				waitStart := time.Now()
-				sleepDuration := time.Duration(diff) * time.Millisecond
-
-				// Unlock mutex during sleep to allow other operations
-				g.mu.Unlock()
-				select {
-				case <-time.After(sleepDuration):
-					g.mu.Lock()
-					timestamp = g.currentTimestamp()
-					g.waitTimeUs.Add(time.Since(waitStart).Microseconds())
-				case <-ctx.Done():
-					g.mu.Lock() // Re-acquire before returning
-					return ids, ErrContextCanceled
-				}
-			}
-
-			// Still behind after waiting? Clock issue is too severe
-			if timestamp < g.lastTimestamp {
-				g.clockBackwardErr.Add(1)
-				return ids, fmt.Errorf("%w: current=%d last=%d diff=%dms (tolerance=%dms)",
-					ErrClockMovedBack, timestamp, g.lastTimestamp,
-					g.lastTimestamp-timestamp, g.maxClockBackward.Milliseconds())
-			}
-		}
-
-		// Same millisecond as last ID: increment sequence
-		if timestamp == g.lastTimestamp {
-			g.sequence = (g.sequence + 1) & MaxSequence
-
-			// Sequence overflow: exhausted all 4096 IDs this millisecond
-			if g.sequence == 0 {
-				g.sequenceOverflow.Add(1)
-				// Wait for next millisecond
-				waitStart := time.Now()
-				for {
-					now := g.currentTimestamp()
-					if now > g.lastTimestamp {
-						g.waitTimeUs.Add(time.Since(waitStart).Microseconds())
-						timestamp = now
-						break
-					}
-					runtime.Gosched()
-				}
-			}
-		} else {
-			// New millisecond: reset sequence to 0
-			g.sequence = 0
+		// Like the single-ID path, but other callers may run while this
+		// batch waits out clock drift
+		budget := g.waitBudget()
+		budget.unlock = true
+		id, err := g.nextIDLocked(ctx, budget)
+		if err != nil {
+			return ids, err
 		}
 
-		g.lastTimestamp = timestamp
-
-		// Compose ID using bitshifting
-		id := ((timestamp - g.customEpoch) << TimestampShift) |
-			(g.workerID << WorkerIDShift) |
-			g.sequence
-
 		ids = append(ids, ID(id))
 	}
 
-	// Update metrics once for entire batch
-	g.generated.Add(int64(len(ids)))
-
 	return ids, nil
 }
 
@@ -793,6 +1050,8 @@ func (g *Generator) GenerateBatchInt64(ctx context.Context, count int) ([]int64,
 //   - ClockBackwardErr: Clock drift errors exceeding tolerance (IDs not generated)
 //   - SequenceOverflow: Times we exhausted 4096 IDs in a millisecond
 //   - WaitTimeUs: Total microseconds spent waiting (clock drift + sequence overflow)
+//   - Borrowed: Time units borrowed ahead of the clock in burst mode
+//   - LeadUs: How far the last borrowed time unit is ahead of the clock now (0 when caught up)
 //
 // Performance: ~5ns per call (5 atomic loads)
 // Thread-safe: Yes, uses atomic operations
@@ -812,10 +1071,24 @@ func (g *Generator) GetMetrics() Metrics {
 		ClockBackwardErr: g.clockBackwardErr.Load(),
 		SequenceOverflow: g.sequenceOverflow.Load(),
 		WaitTimeUs:       g.waitTimeUs.Load(),
+		Borrowed:         g.borrowed.Load(),
+		LeadUs:           g.leadUs(),
+		ClockOffsetUs:    g.clockOffsetUs.Load(),
+		Reanchors:        g.reanchors.Load(),
 	}
 }
 
-// ResetMetrics resets all metrics counters to zero.
+// leadUs returns how far the last borrowed time unit is ahead of the clock,
+// in microseconds, or 0 if the clock has caught up.
+func (g *Generator) leadUs() int64 {
+	lead := g.borrowedUntil.Load() - g.currentTimestamp()
+	if lead <= 0 {
+		return 0
+	}
+	return (time.Duration(lead) * g.timeUnit).Microseconds()
+}
+
+// ResetMetrics resets all metrics counters and histograms to zero.
 //
 // This is primarily useful for testing. In production, metrics should typically
 // be monotonically increasing for accurate rate calculation and alerting.
@@ -831,6 +1104,17 @@ func (g *Generator) ResetMetrics() {
 	g.clockBackwardErr.Store(0)
 	g.sequenceOverflow.Store(0)
 	g.waitTimeUs.Store(0)
+	g.borrowed.Store(0)
+	g.reanchors.Store(0)
+	g.latency.reset()
+	g.overflowWait.reset()
+	g.clockWait.reset()
+	g.health.reset()
+}
+
+// observeLatency records the latency of a call that started at start.
+func (g *Generator) observeLatency(start time.Time) {
+	g.latency.observe(time.Since(start))
 }
 
 // WorkerID returns the worker ID of this generator.
@@ -845,10 +1129,16 @@ func (g *Generator) WorkerID() int64 {
 	return g.workerID
 }
 
+// Name returns the generator's Config.Name, or "" if unnamed.
+func (g *Generator) Name() string {
+	return g.name
+}
+
 // TimestampUtilization returns the percentage of timestamp range used (0.0-1.0).
 //
-// This calculates how much of the 41-bit timestamp space has been consumed since
-// the epoch. A value of 0.5 means 50% of the ~69-year lifespan has been used.
+// This calculates how much of the layout's timestamp space has been consumed since
+// the epoch. A value of 0.5 means 50% of the lifespan (~69 years for
+// LayoutDefault) has been used.
 //
 // Performance: ~50ns (time calculation + division)
 // Thread-safe: Yes, no locks required
@@ -860,32 +1150,12 @@ func (g *Generator) WorkerID() int64 {
 //	    log.Warn("High timestamp utilization", "percent", utilization*100)
 //	}
 func (g *Generator) TimestampUtilization() float64 {
-	// Get current timestamp relative to the epoch
-	currentTime := time.Now().UnixMilli()
-	elapsed := currentTime - g.customEpoch
-
-	// Prevent division by zero or negative values
-	if elapsed <= 0 {
-		return 0.0
-	}
-
-	// Calculate utilization as percentage of max timestamp
-	utilization := float64(elapsed) / float64(MaxTimestamp)
-
-	// Clamp to [0.0, 1.0] range
-	if utilization > 1.0 {
-		return 1.0
-	}
-	if utilization < 0.0 {
-		return 0.0
-	}
-
-	return utilization
+	return g.LifespanInfo().Utilization
 }
 
 // RemainingLifespan returns the duration until timestamp overflow.
 //
-// This calculates how much time remains before the 41-bit timestamp field
+// This calculates how much time remains before the layout's timestamp field
 // is exhausted. Applications should monitor this and plan for epoch migration
 // when approaching the limit.
 //
@@ -900,20 +1170,7 @@ func (g *Generator) TimestampUtilization() float64 {
 //	    log.Error("Approaching timestamp overflow!")
 //	}
 func (g *Generator) RemainingLifespan() time.Duration {
-	// Get current timestamp relative to the epoch
-	currentTime := time.Now().UnixMilli()
-	elapsed := currentTime - g.customEpoch
-
-	// Calculate remaining milliseconds until overflow
-	remaining := MaxTimestamp - elapsed
-
-	// If already past overflow (shouldn't happen), return 0
-	if remaining < 0 {
-		return 0
-	}
-
-	// Convert milliseconds to duration
-	return time.Duration(remaining) * time.Millisecond
+	return g.LifespanInfo().Remaining
 }
 
 // IsApproachingOverflow returns true if timestamp utilization exceeds 80%.
@@ -953,41 +1210,19 @@ func (g *Generator) IsApproachingOverflow() bool {
 //	    "overflow_date", info.OverflowDate,
 //	    "is_approaching", info.IsApproaching)
 func (g *Generator) LifespanInfo() LifespanInfo {
-	// Get current time
-	now := time.Now()
-	currentMillis := now.UnixMilli()
+	// Time units elapsed since the custom epoch (both in the layout's time unit)
+	elapsed := max(g.currentTimestamp()-g.customEpoch, 0)
 
-	// Calculate elapsed time since epoch
-	elapsed := currentMillis - g.customEpoch
-	if elapsed < 0 {
-		elapsed = 0
-	}
+	// Calculate utilization of the layout's timestamp field
+	utilization := min(float64(elapsed)/float64(g.maxTimestamp), 1.0)
 
-	// Calculate utilization
-	utilization := float64(elapsed) / float64(MaxTimestamp)
-	if utilization > 1.0 {
-		utilization = 1.0
-	}
-	if utilization < 0.0 {
-		utilization = 0.0
-	}
-
-	// Calculate remaining time
-	remainingMs := MaxTimestamp - elapsed
-	if remainingMs < 0 {
-		remainingMs = 0
-	}
-	remaining := time.Duration(remainingMs) * time.Millisecond
-
-	// Calculate total lifespan (constant)
-	totalLifespan := time.Duration(MaxTimestamp) * time.Millisecond
-
-	// Calculate current age
-	currentAge := time.Duration(elapsed) * time.Millisecond
+	// Convert time units to durations, which saturate for very long lifespans
+	remaining := unitsDuration(max(g.maxTimestamp-elapsed, 0), g.timeUnit)
+	totalLifespan := unitsDuration(g.maxTimestamp, g.timeUnit)
+	currentAge := unitsDuration(elapsed, g.timeUnit)
 
 	// Calculate overflow date
-	epochTime := time.UnixMilli(g.customEpoch)
-	overflowDate := epochTime.Add(totalLifespan)
+	overflowDate := unitTime(g.customEpoch+g.maxTimestamp, g.timeUnit)
 
 	// Check if approaching threshold
 	isApproaching := utilization >= TimestampWarningThreshold
@@ -1049,18 +1284,7 @@ func ParseIDComponents(id int64) (timestamp int64, workerID int64, sequence int6
 //	ts, worker, seq := snowflake.ParseIDComponentsWithLayout(id.Int64(), snowflake.LayoutSuperior)
 //	fmt.Printf("Generated by worker %d at %v (seq=%d)\n", worker, time.UnixMilli(ts), seq)
 func ParseIDComponentsWithLayout(id int64, layout BitLayout) (timestamp int64, workerID int64, sequence int64) {
-	timestampShift, workerShift, maxWorker, maxSequence := layout.CalculateShifts()
-
-	// Extract timestamp in time units and convert to milliseconds
-	timeUnits := id >> timestampShift
-	timestamp = (timeUnits * layout.TimeUnit.Milliseconds()) + Epoch
-
-	// Extract worker ID using layout-specific shift and mask
-	workerID = (id >> workerShift) & maxWorker
-
-	// Extract sequence using layout-specific mask
-	sequence = id & maxSequence
-	return
+	return Scheme{Layout: layout, Epoch: Epoch}.Components(ID(id))
 }
 
 // ExtractTimestamp extracts the timestamp from a Snowflake ID as time.Time.
@@ -1093,14 +1317,7 @@ func ExtractTimestamp(id int64) time.Time {
 //	idTime := snowflake.ExtractTimestampWithLayout(id.Int64(), snowflake.LayoutSuperior)
 //	age := time.Since(idTime)
 func ExtractTimestampWithLayout(id int64, layout BitLayout) time.Time {
-	timestampShift, _, _, _ := layout.CalculateShifts()
-
-	// Extract timestamp in time units and convert to milliseconds
-	timeUnits := id >> timestampShift
-	ms := (timeUnits * layout.TimeUnit.Milliseconds()) + Epoch
-
-	// Convert to time.Time: seconds + nanoseconds
-	return time.Unix(ms/1000, (ms%1000)*1000000)
+	return Scheme{Layout: layout, Epoch: Epoch}.Time(ID(id))
 }
 
 // currentTimestamp returns the current timestamp in time units using monotonic clock.
@@ -1113,41 +1330,38 @@ func ExtractTimestampWithLayout(id int64, layout BitLayout) time.Time {
 //
 // The calculation works as follows:
 //  1. time.Since(g.epoch) gives monotonic duration since generator creation
-//  2. Add this duration to the wall clock time at initialization
+//  2. Add this duration to the wall clock time at initialization, plus any
+//     re-anchoring by a DriftMonitor
 //  3. Convert to time units using bitshift (power-of-2) or division (fallback)
 //  4. Return timestamp in time units
 //
 // Performance:
 //   - 1ms time unit: ~20ns (no-op bitshift)
 //   - 2/4/8ms: ~22ns (fast bitshift)
-//   - 10ms: ~25ns (division fallback)
+//   - 10ms, 100µs, 250µs: ~25ns (division fallback)
 func (g *Generator) currentTimestamp() int64 {
 	// Get wall clock time at initialization + monotonic duration since then
 	// This gives us a monotonic-safe current time
-	currentTime := g.epoch.Add(time.Since(g.epoch))
-	currentMillis := currentTime.UnixMilli()
+	currentTime := g.clockTime()
 
-	// Convert milliseconds to time units
-	// Use bitshift for power-of-2 time units (fast), division otherwise
-	var currentUnits int64
+	// Convert to time units
+	// Use bitshift for power-of-2 millisecond units (fast), division otherwise
 	if g.timeUnitShift >= 0 {
-		// Fast path: bitshift for power-of-2 time units
+		// Fast path: bitshift for power-of-2 millisecond units
 		// Example: 1ms → shift 0 (no-op), 2ms → shift 1, 4ms → shift 2
-		currentUnits = currentMillis >> g.timeUnitShift
-	} else {
-		// Fallback path: division for non-power-of-2 (e.g., 10ms)
-		// Only LayoutUltimate, LayoutMegaScale, and LayoutSonyflake use this
-		currentUnits = currentMillis / g.timeUnit.Milliseconds()
+		return currentTime.UnixMilli() >> g.timeUnitShift
 	}
 
-	return currentUnits
+	// Fallback path: nanosecond division for other units (e.g., 10ms, 100µs)
+	return currentTime.UnixNano() / int64(g.timeUnit)
 }
 
 // waitNextMillisWithContext waits for the next time unit with context support.
 //
-// Returns error if context is canceled during wait.
+// Returns ErrContextCanceled if the context is done before the clock passes
+// g.lastTimestamp.
 func (g *Generator) waitNextMillisWithContext(ctx context.Context, currentTime int64) (int64, error) {
-	return g.waitNextMillisWithContextInternal(ctx, currentTime), nil
+	return g.waitNextMillisWithContextInternal(ctx, currentTime)
 }
 
 // waitNextMillisWithContextInternal implements the actual wait logic.
@@ -1172,7 +1386,7 @@ func (g *Generator) waitNextMillisWithContext(ctx context.Context, currentTime i
 // Typical wait time: <1µs if already at next time unit
 // Maximum wait time: depends on layout's time unit (1ms or 10ms)
 // CPU usage: Minimal due to smart sleeping
-func (g *Generator) waitNextMillisWithContextInternal(ctx context.Context, currentTime int64) int64 {
+func (g *Generator) waitNextMillisWithContextInternal(ctx context.Context, currentTime int64) (int64, error) {
 	waitStart := time.Now()
 	nextTimeUnit := g.lastTimestamp + 1
 	timeToWait := nextTimeUnit - currentTime
@@ -1188,7 +1402,8 @@ func (g *Generator) waitNextMillisWithContextInternal(ctx context.Context, curre
 			select {
 			case <-time.After(sleepDuration - 50*time.Microsecond):
 			case <-ctx.Done():
-				return g.currentTimestamp()
+				g.waitTimeUs.Add(time.Since(waitStart).Microseconds())
+				return 0, ErrContextCanceled
 			}
 		}
 	}
@@ -1200,7 +1415,7 @@ func (g *Generator) waitNextMillisWithContextInternal(ctx context.Context, curre
 		if now > g.lastTimestamp {
 			// Record wait time for metrics
 			g.waitTimeUs.Add(time.Since(waitStart).Microseconds())
-			return now
+			return now, nil
 		}
 		// Yield to scheduler - allows other goroutines to run
 		// This is crucial for being a good citizen in concurrent systems
@@ -1208,6 +1423,38 @@ func (g *Generator) waitNextMillisWithContextInternal(ctx context.Context, curre
 	}
 }
 
+// waitBudget tracks how much longer one ID generation may wait for the clock.
+type waitBudget struct {
+	limited   bool          // False means wait as long as needed
+	remaining time.Duration // Wait time left when limited
+	unlock    bool          // Release g.mu while waiting out clock drift
+}
+
+// waitBudget returns the per-ID budget configured by Config.MaxWait.
+func (g *Generator) waitBudget() waitBudget {
+	return waitBudget{limited: g.maxWait > 0, remaining: g.maxWait}
+}
+
+// take charges d to the budget, reporting false (and charging nothing) if
+// there is not enough left.
+func (b *waitBudget) take(d time.Duration) bool {
+	if !b.limited {
+		return true
+	}
+	if d > b.remaining {
+		return false
+	}
+	b.remaining -= d
+	return true
+}
+
+// untilTimeUnit returns how long until the clock reaches the given timestamp
+// in time units, or 0 if it already has.
+func (g *Generator) untilTimeUnit(timestamp int64) time.Duration {
+	start := unitTime(timestamp, g.timeUnit)
+	return max(0, start.Sub(g.clockTime()))
+}
+
 // Default generator instance (worker ID 0) for convenient package-level functions.
 //
 // # Lazy Initialization
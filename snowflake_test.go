@@ -2,6 +2,7 @@ package snowflake
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -1223,3 +1224,251 @@ func TestConstantValues(t *testing.T) {
 		t.Errorf("MaxTimestamp provides %d years, expected ~69", yearsInMs)
 	}
 }
+
+// TestBorrowAhead_Burst tests that overflow borrows future time units instead of waiting
+func TestBorrowAhead_Burst(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.MaxBorrowAhead = 20 * time.Millisecond
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	// Five full time units in one burst; Reserve is O(1) so this outpaces the clock
+	ctx := context.Background()
+	start := time.Now()
+	var last ID
+	for i := 0; i < 5; i++ {
+		r, err := gen.Reserve(ctx, 4096)
+		if err != nil {
+			t.Fatalf("Reserve() error = %v", err)
+		}
+		if r.First() <= last {
+			t.Fatalf("range %v not after previous ID %d", r, last)
+		}
+		last = r.Last()
+	}
+	for i := 0; i < 100; i++ {
+		id := gen.MustGenerateID()
+		if id <= last {
+			t.Fatalf("ID %d not greater than previous %d", id, last)
+		}
+		last = id
+	}
+	elapsed := time.Since(start)
+
+	m := gen.GetMetrics()
+	if m.Borrowed == 0 {
+		t.Errorf("Borrowed = 0 after a burst lasting %v, want > 0", elapsed)
+	}
+	if m.ClockBackward != 0 {
+		t.Errorf("ClockBackward = %d, borrowed time units must not count as drift", m.ClockBackward)
+	}
+	if m.LeadUs > cfg.MaxBorrowAhead.Microseconds() {
+		t.Errorf("LeadUs = %d, exceeds MaxBorrowAhead %v", m.LeadUs, cfg.MaxBorrowAhead)
+	}
+
+	// The last ID is never further ahead of the clock than the limit
+	if lead := time.Until(gen.Scheme().Time(last)); lead > cfg.MaxBorrowAhead {
+		t.Errorf("last ID is %v ahead of the clock, limit %v", lead, cfg.MaxBorrowAhead)
+	}
+
+	// Once the clock catches up the lead drops to zero
+	time.Sleep(cfg.MaxBorrowAhead + 2*time.Millisecond)
+	if m := gen.GetMetrics(); m.LeadUs != 0 {
+		t.Errorf("LeadUs = %d after catching up, want 0", m.LeadUs)
+	}
+	if id := gen.MustGenerateID(); id <= last {
+		t.Errorf("ID %d after catching up not greater than %d", id, last)
+	}
+}
+
+// TestBorrowAhead_ClockBackward tests that real drift is still detected while borrowing
+func TestBorrowAhead_ClockBackward(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.MaxBorrowAhead = 10 * time.Millisecond
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	// Simulate a clock that has been seen 100ms ahead of now
+	gen.mu.Lock()
+	gen.lastClock = gen.currentTimestamp() + 100
+	gen.lastTimestamp = gen.lastClock + 5 // Borrowed on top of that
+	gen.mu.Unlock()
+
+	_, err = gen.GenerateID()
+	if !IsClockError(err) {
+		t.Fatalf("GenerateID() error = %v, want ClockError", err)
+	}
+	if m := gen.GetMetrics(); m.ClockBackwardErr != 1 {
+		t.Errorf("ClockBackwardErr = %d, want 1", m.ClockBackwardErr)
+	}
+}
+
+// TestBorrowAhead_Config tests MaxBorrowAhead validation
+func TestBorrowAhead_Config(t *testing.T) {
+	for _, d := range []time.Duration{-time.Millisecond, 500 * time.Microsecond} {
+		cfg := DefaultConfig(1)
+		cfg.MaxBorrowAhead = d
+		if err := cfg.Validate(); !IsConfigError(err) {
+			t.Errorf("MaxBorrowAhead = %v: Validate() error = %v, want ConfigError", d, err)
+		}
+	}
+
+	// Sonyflake uses 10ms time units
+	cfg := DefaultConfig(1)
+	cfg.Layout = LayoutSonyflake
+	cfg.MaxBorrowAhead = 5 * time.Millisecond
+	if err := cfg.Validate(); !IsConfigError(err) {
+		t.Errorf("MaxBorrowAhead below time unit: Validate() error = %v, want ConfigError", err)
+	}
+	cfg.MaxBorrowAhead = 20 * time.Millisecond
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("MaxBorrowAhead = 2 time units: Validate() error = %v", err)
+	}
+}
+
+// TestTryGenerateID_Overflow tests that TryGenerateID fails instead of waiting on overflow
+func TestTryGenerateID_Overflow(t *testing.T) {
+	gen, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Exhaust the current time unit, then try before the clock ticks
+	var r IDRange
+	for attempt := 0; attempt < 100; attempt++ {
+		r, err = gen.Reserve(context.Background(), 4096)
+		if err != nil {
+			t.Fatalf("Reserve() error = %v", err)
+		}
+		if _, err = gen.TryGenerateID(); err != nil {
+			break
+		}
+	}
+	if !IsOverflowError(err) || !errors.Is(err, ErrSequenceOverflow) {
+		t.Fatalf("TryGenerateID() error = %v, want OverflowError", err)
+	}
+
+	// State is unchanged: the next ID still follows the reserved block
+	id := gen.MustGenerateID()
+	if id <= r.Last() {
+		t.Errorf("ID %d after failed TryGenerateID not greater than %d", id, r.Last())
+	}
+}
+
+func TestTryGenerateID_OverflowMillis(t *testing.T) {
+	// 10ms units: the error reports milliseconds, not time units
+	cfg := DefaultConfig(1)
+	cfg.Layout = LayoutUltimate
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	before := time.Now().UnixMilli()
+	for attempt := 0; attempt < 100; attempt++ {
+		if _, err = gen.Reserve(context.Background(), 128); err != nil {
+			t.Fatalf("Reserve() error = %v", err)
+		}
+		if _, err = gen.TryGenerateID(); err != nil {
+			break
+		}
+	}
+	overflowErr, ok := GetOverflowError(err)
+	if !ok {
+		t.Fatalf("TryGenerateID() error = %v, want OverflowError", err)
+	}
+	if overflowErr.Timestamp < before-10 || overflowErr.Timestamp > time.Now().UnixMilli() {
+		t.Errorf("Timestamp = %d, want milliseconds near %d", overflowErr.Timestamp, before)
+	}
+}
+
+// TestTryGenerateID_ClockBackward tests that even tolerable drift fails immediately
+func TestTryGenerateID_ClockBackward(t *testing.T) {
+	gen, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	gen.mu.Lock()
+	gen.lastTimestamp = gen.currentTimestamp() + 2 // Within the 5ms tolerance
+	gen.lastClock = gen.lastTimestamp
+	gen.mu.Unlock()
+
+	if _, err := gen.TryGenerateID(); !IsClockError(err) {
+		t.Fatalf("TryGenerateID() error = %v, want ClockError", err)
+	}
+
+	// GenerateID waits the drift out instead
+	if _, err := gen.GenerateID(); err != nil {
+		t.Errorf("GenerateID() error = %v, want nil", err)
+	}
+}
+
+// TestMaxWait tests that waits longer than Config.MaxWait fail fast
+func TestMaxWait(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.MaxWait = time.Millisecond
+	gen, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	// 3ms of drift is tolerable but exceeds the 1ms budget
+	gen.mu.Lock()
+	gen.lastTimestamp = gen.currentTimestamp() + 3
+	gen.lastClock = gen.lastTimestamp
+	gen.mu.Unlock()
+
+	start := time.Now()
+	if _, err := gen.GenerateID(); !IsClockError(err) {
+		t.Fatalf("GenerateID() error = %v, want ClockError", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Millisecond {
+		t.Errorf("GenerateID() took %v, want no wait", elapsed)
+	}
+
+	// An overflow wait of up to one time unit fits the budget
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if _, err := gen.Reserve(context.Background(), 4096); err != nil {
+			t.Fatalf("Reserve() error = %v, want nil within budget", err)
+		}
+	}
+
+	cfg.MaxWait = -time.Millisecond
+	if err := cfg.Validate(); !IsConfigError(err) {
+		t.Errorf("Validate() with negative MaxWait error = %v, want ConfigError", err)
+	}
+}
+
+// TestOverflowWait_ContextCanceled tests that the sequence-overflow wait reports cancellation
+func TestOverflowWait_ContextCanceled(t *testing.T) {
+	gen, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// The last time unit is exhausted and 50ms ahead of the clock
+	gen.mu.Lock()
+	gen.lastClock = gen.currentTimestamp()
+	gen.lastTimestamp = gen.lastClock + 50
+	gen.sequence = gen.maxSequence
+	gen.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	if _, err := gen.GenerateIDWithContext(ctx); !errors.Is(err, ErrContextCanceled) {
+		t.Fatalf("GenerateIDWithContext() error = %v, want ErrContextCanceled", err)
+	}
+
+	gen.mu.Lock()
+	defer gen.mu.Unlock()
+	if gen.sequence != gen.maxSequence {
+		t.Errorf("sequence = %d after canceled wait, want unchanged %d", gen.sequence, gen.maxSequence)
+	}
+}
@@ -0,0 +1,227 @@
+// Package snowflake - sqlfunc.go renders SQL that decodes IDs inside the database.
+//
+// The generated SQL is specific to a Scheme: shifts, masks, time unit and epoch
+// are baked in as constants, so the database computes exactly what
+// Scheme.Components would. PostgreSQL and MySQL get CREATE FUNCTION statements;
+// SQLite has no CREATE FUNCTION, so use the inline expressions from SQLExpr or
+// register Go functions with the snowflake/sqlite subpackage.
+
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SQL function names used by SQLFunctions, SQLExpr and the snowflake/sqlite package.
+const (
+	// SQLFuncTime returns the time an ID was generated.
+	// PostgreSQL: timestamptz. MySQL: DATETIME(3) in the session time zone.
+	// SQLite: UTC text in "YYYY-MM-DD HH:MM:SS.SSS" form.
+	SQLFuncTime = "snowflake_time"
+
+	// SQLFuncWorker returns the worker ID field of an ID.
+	SQLFuncWorker = "snowflake_worker"
+
+	// SQLFuncSequence returns the sequence field of an ID.
+	SQLFuncSequence = "snowflake_sequence"
+
+	// SQLFuncMinIDAt returns the smallest ID that could be generated at a time,
+	// like Scheme.MinIDForTime. Use it for "WHERE id >= snowflake_min_id_at(...)".
+	SQLFuncMinIDAt = "snowflake_min_id_at"
+)
+
+// ErrUnsupportedDialect is returned when a dialect cannot express a SQL feature.
+var ErrUnsupportedDialect = errors.New("unsupported SQL dialect")
+
+// SQLTimeFormat is the layout of snowflake_time values in SQLite, matching the
+// output of SQLite's strftime('%Y-%m-%d %H:%M:%f').
+const SQLTimeFormat = "2006-01-02 15:04:05.000"
+
+// SQLFunctions renders CREATE FUNCTION statements for snowflake_time,
+// snowflake_worker, snowflake_sequence and snowflake_min_id_at.
+//
+// PostgreSQL functions are declared IMMUTABLE and PARALLEL SAFE so they can be
+// used in indexes and generated columns. MySQL functions are preceded by
+// DROP FUNCTION IF EXISTS, so the script can be re-run after changing schemes.
+//
+// SQLite has no CREATE FUNCTION and returns ErrUnsupportedDialect; use SQLExpr
+// for inline expressions or snowflake/sqlite.Register for Go functions.
+//
+// Example:
+//
+//	ddl, err := gen.Scheme().SQLFunctions(snowflake.DialectPostgres)
+//	if err != nil {
+//	    return err
+//	}
+//	_, err = db.Exec(ddl)
+//	// SELECT snowflake_time(id) AS created_at FROM events
+func (s Scheme) SQLFunctions(dialect Dialect) (string, error) {
+	s = s.normalized()
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+	tick, err := s.sqlTick()
+	if err != nil {
+		return "", err
+	}
+	fsp := 3 // MySQL fractional seconds precision
+	if tick == time.Microsecond {
+		fsp = 6
+	}
+
+	var b strings.Builder
+	switch dialect {
+	case DialectPostgres:
+		fns := []struct{ name, arg, ret string }{
+			{SQLFuncTime, "id bigint", "timestamptz"},
+			{SQLFuncWorker, "id bigint", "bigint"},
+			{SQLFuncSequence, "id bigint", "bigint"},
+			{SQLFuncMinIDAt, "ts timestamptz", "bigint"},
+		}
+		for _, fn := range fns {
+			expr, _ := s.SQLExpr(dialect, fn.name, strings.Fields(fn.arg)[0])
+			fmt.Fprintf(&b, "CREATE OR REPLACE FUNCTION %s(%s) RETURNS %s\n"+
+				"    LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE\n"+
+				"    AS $$ SELECT %s $$;\n", fn.name, fn.arg, fn.ret, expr)
+		}
+	case DialectMySQL:
+		fns := []struct{ name, arg, ret string }{
+			{SQLFuncTime, "id BIGINT", fmt.Sprintf("DATETIME(%d)", fsp)},
+			{SQLFuncWorker, "id BIGINT", "BIGINT"},
+			{SQLFuncSequence, "id BIGINT", "BIGINT"},
+			{SQLFuncMinIDAt, fmt.Sprintf("ts DATETIME(%d)", fsp), "BIGINT"},
+		}
+		for _, fn := range fns {
+			expr, _ := s.SQLExpr(dialect, fn.name, strings.Fields(fn.arg)[0])
+			fmt.Fprintf(&b, "DROP FUNCTION IF EXISTS %s;\n"+
+				"CREATE FUNCTION %s(%s) RETURNS %s\n"+
+				"    DETERMINISTIC NO SQL\n"+
+				"    RETURN %s;\n", fn.name, fn.name, fn.arg, fn.ret, expr)
+		}
+	case DialectSQLite:
+		return "", fmt.Errorf("%w: SQLite has no CREATE FUNCTION; use SQLExpr or snowflake/sqlite.Register", ErrUnsupportedDialect)
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownDialect, dialect)
+	}
+	return b.String(), nil
+}
+
+// SQLExpr renders the body of one of the SQL functions as an inline expression
+// over arg, which may be a column name or any SQL expression.
+//
+// This works in every dialect, including SQLite, and needs no privileges to
+// create functions. For SQLite, snowflake_min_id_at reads integers and reals
+// as Unix milliseconds, like the function registered by the sqlite package,
+// and any other value as a time SQLite's strftime understands.
+//
+// arg may appear more than once in the result (MySQL snowflake_time, SQLite
+// snowflake_min_id_at), so pass a column rather than a "?" placeholder, for
+// example by selecting the parameter in a subquery.
+//
+// Example:
+//
+//	expr, _ := snowflake.DefaultScheme.SQLExpr(snowflake.DialectSQLite, snowflake.SQLFuncTime, "id")
+//	rows, err := db.Query("SELECT id, " + expr + " AS created_at FROM events")
+func (s Scheme) SQLExpr(dialect Dialect, fn, arg string) (string, error) {
+	s = s.normalized()
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+	if dialect != DialectPostgres && dialect != DialectMySQL && dialect != DialectSQLite {
+		return "", fmt.Errorf("%w: %q", ErrUnknownDialect, dialect)
+	}
+
+	timestampShift, workerShift, maxWorker, maxSequence := s.Layout.CalculateShifts()
+	epochUnits := s.epochUnits()
+
+	tick, err := s.sqlTick()
+	if err != nil {
+		return "", err
+	}
+	ticksPerSecond := int64(time.Second / tick)
+	unit := int64(s.Layout.TimeUnit / tick)
+
+	switch fn {
+	case SQLFuncTime:
+		ticks := fmt.Sprintf("((%s >> %d) + %d)", arg, timestampShift, epochUnits)
+		if unit != 1 {
+			ticks = fmt.Sprintf("(%s * %d)", ticks, unit)
+		}
+		switch dialect {
+		case DialectPostgres:
+			return fmt.Sprintf("to_timestamp(%s / %d.0)", ticks, ticksPerSecond), nil
+		case DialectMySQL:
+			// Whole seconds plus an exact interval: "/" keeps only
+			// div_precision_increment (default 4) decimal places
+			micros := fmt.Sprintf("(%s MOD %d)", ticks, ticksPerSecond)
+			if tick != time.Microsecond {
+				micros = fmt.Sprintf("(%s * %d)", micros, int64(tick/time.Microsecond))
+			}
+			return fmt.Sprintf("FROM_UNIXTIME(%s DIV %d) + INTERVAL %s MICROSECOND", ticks, ticksPerSecond, micros), nil
+		default:
+			// strftime rounds to milliseconds; truncate first, like SQLTimeFormat
+			if tick == time.Microsecond {
+				return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:%%M:%%f', (%s / 1000) / 1000.0, 'unixepoch')", ticks), nil
+			}
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:%%M:%%f', %s / %d.0, 'unixepoch')", ticks, ticksPerSecond), nil
+		}
+
+	case SQLFuncWorker:
+		return fmt.Sprintf("((%s >> %d) & %d)", arg, workerShift, maxWorker), nil
+
+	case SQLFuncSequence:
+		return fmt.Sprintf("(%s & %d)", arg, maxSequence), nil
+
+	case SQLFuncMinIDAt:
+		var ticks string
+		switch dialect {
+		case DialectPostgres:
+			ticks = fmt.Sprintf("floor(extract(epoch FROM %s) * %d)::bigint", arg, ticksPerSecond)
+		case DialectMySQL:
+			ticks = fmt.Sprintf("FLOOR(UNIX_TIMESTAMP(%s) * %d)", arg, ticksPerSecond)
+		default:
+			// Numbers are Unix milliseconds; strftime would read them as
+			// Julian day numbers. Text has millisecond resolution: whole
+			// seconds plus the milliseconds of strftime's "SS.SSS", in
+			// integers throughout
+			ticks = fmt.Sprintf("(CASE WHEN typeof(%s) IN ('integer', 'real') THEN CAST(%s AS INTEGER) "+
+				"ELSE CAST(strftime('%%s', %s) AS INTEGER) * 1000 + CAST(substr(strftime('%%f', %s), 4) AS INTEGER) END)",
+				arg, arg, arg, arg)
+			if tick == time.Microsecond {
+				ticks = fmt.Sprintf("(%s * 1000)", ticks)
+			}
+		}
+		units := fmt.Sprintf("%s - %d", ticks, epochUnits)
+		if unit != 1 {
+			div := "/"
+			if dialect == DialectMySQL {
+				div = "DIV"
+			}
+			units = fmt.Sprintf("%s %s %d - %d", ticks, div, unit, epochUnits)
+		}
+		if dialect == DialectSQLite {
+			return fmt.Sprintf("(min(max(%s, 0), %d) << %d)", units, s.maxTimeUnits(), timestampShift), nil
+		}
+		return fmt.Sprintf("(LEAST(GREATEST(%s, 0), %d) << %d)", units, s.maxTimeUnits(), timestampShift), nil
+
+	default:
+		return "", fmt.Errorf("unknown SQL function %q", fn)
+	}
+}
+
+// sqlTick returns the resolution SQL expressions compute times in:
+// milliseconds, or microseconds for sub-millisecond time units.
+func (s Scheme) sqlTick() (time.Duration, error) {
+	tick := time.Millisecond
+	if s.Layout.TimeUnit%time.Millisecond != 0 {
+		tick = time.Microsecond
+	}
+	if s.Layout.TimeUnit%tick != 0 {
+		return 0, fmt.Errorf("%w: time unit %v is not a whole number of microseconds",
+			ErrInvalidBitLayout, s.Layout.TimeUnit)
+	}
+	return tick, nil
+}
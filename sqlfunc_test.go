@@ -0,0 +1,94 @@
+package snowflake
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSQLFunctions_Postgres(t *testing.T) {
+	ddl, err := DefaultScheme.SQLFunctions(DialectPostgres)
+	if err != nil {
+		t.Fatalf("SQLFunctions() error = %v", err)
+	}
+
+	want := []string{
+		"CREATE OR REPLACE FUNCTION snowflake_time(id bigint) RETURNS timestamptz",
+		"SELECT to_timestamp(((id >> 22) + 1704067200000) / 1000.0)",
+		"SELECT ((id >> 12) & 1023)",
+		"SELECT (id & 4095)",
+		"CREATE OR REPLACE FUNCTION snowflake_min_id_at(ts timestamptz) RETURNS bigint",
+		"(LEAST(GREATEST(floor(extract(epoch FROM ts) * 1000)::bigint - 1704067200000, 0), 2199023255551) << 22)",
+	}
+	for _, w := range want {
+		if !strings.Contains(ddl, w) {
+			t.Errorf("SQLFunctions(postgres) missing %q\n%s", w, ddl)
+		}
+	}
+	if n := strings.Count(ddl, "IMMUTABLE STRICT PARALLEL SAFE"); n != 4 {
+		t.Errorf("expected 4 IMMUTABLE functions, got %d", n)
+	}
+}
+
+func TestSQLFunctions_MySQL(t *testing.T) {
+	ddl, err := DefaultScheme.SQLFunctions(DialectMySQL)
+	if err != nil {
+		t.Fatalf("SQLFunctions() error = %v", err)
+	}
+
+	want := []string{
+		"DROP FUNCTION IF EXISTS snowflake_time;",
+		"CREATE FUNCTION snowflake_time(id BIGINT) RETURNS DATETIME(3)",
+		"RETURN FROM_UNIXTIME(((id >> 22) + 1704067200000) DIV 1000) + INTERVAL ((((id >> 22) + 1704067200000) MOD 1000) * 1000) MICROSECOND;",
+		"RETURN ((id >> 12) & 1023);",
+		"RETURN (id & 4095);",
+		"RETURN (LEAST(GREATEST(FLOOR(UNIX_TIMESTAMP(ts) * 1000) - 1704067200000, 0), 2199023255551) << 22);",
+	}
+	for _, w := range want {
+		if !strings.Contains(ddl, w) {
+			t.Errorf("SQLFunctions(mysql) missing %q\n%s", w, ddl)
+		}
+	}
+}
+
+func TestSQLExpr_Layouts(t *testing.T) {
+	// 10ms units: epoch and timestamp are scaled like Scheme.Components
+	s := Scheme{Layout: LayoutUltimate, Epoch: Epoch}
+
+	tests := []struct {
+		dialect Dialect
+		fn      string
+		want    string
+	}{
+		{DialectPostgres, SQLFuncTime, "to_timestamp((((id >> 23) + 170406720000) * 10) / 1000.0)"},
+		{DialectPostgres, SQLFuncWorker, "((id >> 7) & 65535)"},
+		{DialectPostgres, SQLFuncSequence, "(id & 127)"},
+		{DialectMySQL, SQLFuncMinIDAt, "(LEAST(GREATEST(FLOOR(UNIX_TIMESTAMP(id) * 1000) DIV 10 - 170406720000, 0), 1099511627775) << 23)"},
+		{DialectSQLite, SQLFuncTime, "strftime('%Y-%m-%d %H:%M:%f', (((id >> 23) + 170406720000) * 10) / 1000.0, 'unixepoch')"},
+	}
+	for _, tt := range tests {
+		got, err := s.SQLExpr(tt.dialect, tt.fn, "id")
+		if err != nil {
+			t.Errorf("SQLExpr(%s, %s) error = %v", tt.dialect, tt.fn, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("SQLExpr(%s, %s) = %q, want %q", tt.dialect, tt.fn, got, tt.want)
+		}
+	}
+}
+
+func TestSQLFunctions_Errors(t *testing.T) {
+	if _, err := DefaultScheme.SQLFunctions(DialectSQLite); !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("SQLFunctions(sqlite) error = %v, want ErrUnsupportedDialect", err)
+	}
+	if _, err := DefaultScheme.SQLFunctions("oracle"); !errors.Is(err, ErrUnknownDialect) {
+		t.Errorf("SQLFunctions(oracle) error = %v, want ErrUnknownDialect", err)
+	}
+	if _, err := DefaultScheme.SQLExpr(DialectPostgres, "snowflake_nope", "id"); err == nil {
+		t.Error("SQLExpr(unknown function) should fail")
+	}
+	if _, err := (Scheme{Epoch: 0}).SQLFunctions(DialectPostgres); !IsConfigError(err) {
+		t.Errorf("SQLFunctions(invalid scheme) error = %v, want ConfigError", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package sqlite registers Snowflake ID decoding functions on SQLite connections.
+//
+// SQLite has no CREATE FUNCTION, so the functions rendered by
+// snowflake.Scheme.SQLFunctions for PostgreSQL and MySQL are provided here as
+// Go functions registered through github.com/mattn/go-sqlite3:
+//
+//	snowflake_time(id)       UTC time as "YYYY-MM-DD HH:MM:SS.SSS"
+//	snowflake_worker(id)     worker ID field
+//	snowflake_sequence(id)   sequence field
+//	snowflake_min_id_at(ts)  smallest ID generated at ts
+//
+// The package lives outside the core module package so that importing
+// snowflake does not pull in cgo.
+//
+// Example:
+//
+//	sqlite.RegisterDriver("sqlite3_snowflake", gen.Scheme())
+//	db, err := sql.Open("sqlite3_snowflake", "events.db")
+//	rows, err := db.Query("SELECT id, snowflake_time(id) FROM events")
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/sxyafiq/snowflake"
+)
+
+// timeLayouts are the text formats accepted by snowflake_min_id_at, tried in order.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Register adds the snowflake_* functions for the given scheme to a connection.
+//
+// All functions are registered as pure, so SQLite may use them in indexes and
+// generated columns.
+//
+// Example:
+//
+//	sql.Register("sqlite3_snowflake", &sqlite3.SQLiteDriver{
+//	    ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+//	        return sqlite.Register(conn, scheme)
+//	    },
+//	})
+func Register(conn *sqlite3.SQLiteConn, scheme snowflake.Scheme) error {
+	if err := scheme.Validate(); err != nil {
+		return err
+	}
+
+	fns := []struct {
+		name string
+		impl any
+	}{
+		{snowflake.SQLFuncTime, func(id int64) string {
+			return scheme.Time(snowflake.ID(id)).UTC().Format(snowflake.SQLTimeFormat)
+		}},
+		{snowflake.SQLFuncWorker, func(id int64) int64 {
+			_, worker, _ := scheme.Components(snowflake.ID(id))
+			return worker
+		}},
+		{snowflake.SQLFuncSequence, func(id int64) int64 {
+			_, _, seq := scheme.Components(snowflake.ID(id))
+			return seq
+		}},
+		{snowflake.SQLFuncMinIDAt, func(ts any) (int64, error) {
+			t, err := parseTime(ts)
+			if err != nil {
+				return 0, err
+			}
+			return scheme.MinIDForTime(t).Int64(), nil
+		}},
+	}
+
+	for _, fn := range fns {
+		if err := conn.RegisterFunc(fn.name, fn.impl, true); err != nil {
+			return fmt.Errorf("register %s: %w", fn.name, err)
+		}
+	}
+	return nil
+}
+
+// ConnectHook returns a go-sqlite3 ConnectHook that registers the functions on
+// every new connection.
+//
+// Example:
+//
+//	driver := &sqlite3.SQLiteDriver{ConnectHook: sqlite.ConnectHook(scheme)}
+func ConnectHook(scheme snowflake.Scheme) func(*sqlite3.SQLiteConn) error {
+	return func(conn *sqlite3.SQLiteConn) error {
+		return Register(conn, scheme)
+	}
+}
+
+// RegisterDriver registers a database/sql driver named name whose connections
+// have the functions for the given scheme.
+//
+// Like sql.Register, it panics if called twice with the same name.
+func RegisterDriver(name string, scheme snowflake.Scheme) {
+	sql.Register(name, &sqlite3.SQLiteDriver{ConnectHook: ConnectHook(scheme)})
+}
+
+// parseTime converts a SQLite value to a time.
+//
+// Integers and floats are Unix milliseconds. Text is parsed as RFC 3339 or
+// SQLite's "YYYY-MM-DD HH:MM:SS.SSS" form; text without a zone is UTC.
+func parseTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case int64:
+		return time.UnixMilli(v), nil
+	case float64:
+		return time.UnixMilli(int64(v)), nil
+	case string:
+		s := strings.TrimSpace(v)
+		for _, layout := range timeLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%s: unrecognized time %q", snowflake.SQLFuncMinIDAt, v)
+	default:
+		return time.Time{}, fmt.Errorf("%s: unsupported argument type %T", snowflake.SQLFuncMinIDAt, v)
+	}
+}
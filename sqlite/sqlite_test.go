@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sxyafiq/snowflake"
+)
+
+// testSchemes are registered once each as their own driver.
+var testSchemes = map[string]snowflake.Scheme{
+	"default":  snowflake.DefaultScheme,
+	"ultimate": {Layout: snowflake.LayoutUltimate, Epoch: 1600000000005},
+	"micro": {
+		Layout: snowflake.BitLayout{TimestampBits: 41, WorkerBits: 10, SequenceBits: 12, TimeUnit: 250 * time.Microsecond},
+		Epoch:  snowflake.Epoch,
+	},
+}
+
+func init() {
+	for name, scheme := range testSchemes {
+		RegisterDriver("sqlite3_snowflake_"+name, scheme)
+	}
+}
+
+func openDB(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3_snowflake_"+name, ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// generateIDs returns IDs from a generator using the scheme, plus boundary values.
+func generateIDs(t *testing.T, scheme snowflake.Scheme) []snowflake.ID {
+	t.Helper()
+	cfg := snowflake.DefaultConfig(1)
+	cfg.Layout = scheme.Layout
+	cfg.Epoch = scheme.Epoch
+	var ids []snowflake.ID
+	for _, worker := range []int64{0, 1, 777} {
+		cfg.WorkerID = worker
+		gen, err := snowflake.NewWithConfig(cfg)
+		if err != nil {
+			t.Fatalf("NewWithConfig() error = %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			ids = append(ids, gen.MustGenerateID())
+		}
+	}
+	return append(ids, 0, snowflake.ID(1<<63-1))
+}
+
+func TestRegister_MatchesComponents(t *testing.T) {
+	for name, scheme := range testSchemes {
+		t.Run(name, func(t *testing.T) {
+			db := openDB(t, name)
+			for _, id := range generateIDs(t, scheme) {
+				var gotTime string
+				var gotWorker, gotSeq int64
+				err := db.QueryRow("SELECT snowflake_time(?), snowflake_worker(?), snowflake_sequence(?)",
+					id.Int64(), id.Int64(), id.Int64()).Scan(&gotTime, &gotWorker, &gotSeq)
+				if err != nil {
+					t.Fatalf("query error = %v", err)
+				}
+
+				_, worker, seq := scheme.Components(id)
+				wantTime := scheme.Time(id).UTC().Format(snowflake.SQLTimeFormat)
+				if gotTime != wantTime || gotWorker != worker || gotSeq != seq {
+					t.Errorf("id %d: got (%s, %d, %d), want (%s, %d, %d)",
+						id, gotTime, gotWorker, gotSeq, wantTime, worker, seq)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLExpr_MatchesComponents(t *testing.T) {
+	for name, scheme := range testSchemes {
+		t.Run(name, func(t *testing.T) {
+			db := openDB(t, name)
+
+			var exprs []string
+			for _, fn := range []string{snowflake.SQLFuncTime, snowflake.SQLFuncWorker, snowflake.SQLFuncSequence} {
+				expr, err := scheme.SQLExpr(snowflake.DialectSQLite, fn, "v.id")
+				if err != nil {
+					t.Fatalf("SQLExpr(%s) error = %v", fn, err)
+				}
+				exprs = append(exprs, expr)
+			}
+			query := fmt.Sprintf("SELECT %s, %s, %s FROM (SELECT ? AS id) AS v", exprs[0], exprs[1], exprs[2])
+
+			// Boundary IDs are skipped: the maximum timestamp is outside SQLite's date range
+			ids := generateIDs(t, scheme)
+			for _, id := range ids[:len(ids)-2] {
+				var gotTime string
+				var gotWorker, gotSeq int64
+				if err := db.QueryRow(query, id.Int64()).Scan(&gotTime, &gotWorker, &gotSeq); err != nil {
+					t.Fatalf("query error = %v", err)
+				}
+
+				_, worker, seq := scheme.Components(id)
+				wantTime := scheme.Time(id).UTC().Format(snowflake.SQLTimeFormat)
+				if gotTime != wantTime || gotWorker != worker || gotSeq != seq {
+					t.Errorf("id %d: got (%s, %d, %d), want (%s, %d, %d)",
+						id, gotTime, gotWorker, gotSeq, wantTime, worker, seq)
+				}
+			}
+		})
+	}
+}
+
+func TestMinIDAt(t *testing.T) {
+	for name, scheme := range testSchemes {
+		t.Run(name, func(t *testing.T) {
+			db := openDB(t, name)
+			expr, err := scheme.SQLExpr(snowflake.DialectSQLite, snowflake.SQLFuncMinIDAt, "v.ts")
+			if err != nil {
+				t.Fatalf("SQLExpr() error = %v", err)
+			}
+
+			times := []time.Time{
+				time.Date(2024, 6, 1, 12, 30, 45, 123e6, time.UTC),
+				time.Date(2025, 1, 2, 3, 4, 5, 7e6, time.UTC),
+				time.UnixMilli(scheme.Epoch - 1000), // clamped to 0
+			}
+			for _, ts := range times {
+				want := scheme.MinIDForTime(ts).Int64()
+				text := ts.Format(snowflake.SQLTimeFormat)
+
+				var fromUDF, fromMillis, fromExpr int64
+				if err := db.QueryRow("SELECT snowflake_min_id_at(?), snowflake_min_id_at(?), "+expr+" FROM (SELECT ? AS ts) AS v",
+					text, ts.UnixMilli(), text).Scan(&fromUDF, &fromMillis, &fromExpr); err != nil {
+					t.Fatalf("query error = %v", err)
+				}
+				if fromUDF != want || fromMillis != want || fromExpr != want {
+					t.Errorf("min_id_at(%s) = (udf %d, ms %d, expr %d), want %d",
+						text, fromUDF, fromMillis, fromExpr, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMinIDAt_NumericInput(t *testing.T) {
+	// The UDF and the inline expression must agree on numbers: both read
+	// them as Unix milliseconds
+	for name, scheme := range testSchemes {
+		t.Run(name, func(t *testing.T) {
+			db := openDB(t, name)
+			expr, err := scheme.SQLExpr(snowflake.DialectSQLite, snowflake.SQLFuncMinIDAt, "v.ts")
+			if err != nil {
+				t.Fatalf("SQLExpr() error = %v", err)
+			}
+
+			ts := time.Date(2024, 6, 1, 12, 30, 45, 123e6, time.UTC)
+			want := scheme.MinIDForTime(ts).Int64()
+			for _, arg := range []any{ts.UnixMilli(), float64(ts.UnixMilli()) + 0.5} {
+				var fromUDF, fromExpr int64
+				if err := db.QueryRow("SELECT snowflake_min_id_at(v.ts), "+expr+" FROM (SELECT ? AS ts) AS v",
+					arg).Scan(&fromUDF, &fromExpr); err != nil {
+					t.Fatalf("query error = %v", err)
+				}
+				if fromUDF != want || fromExpr != want {
+					t.Errorf("min_id_at(%v) = (udf %d, expr %d), want %d", arg, fromUDF, fromExpr, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMinIDAt_InvalidInput(t *testing.T) {
+	db := openDB(t, "default")
+	var id int64
+	if err := db.QueryRow("SELECT snowflake_min_id_at('not a time')").Scan(&id); err == nil {
+		t.Error("snowflake_min_id_at('not a time') should fail")
+	}
+}
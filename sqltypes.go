@@ -0,0 +1,352 @@
+// Package snowflake - sqltypes.go provides nullable and list ID types for database/sql.
+//
+// NullID distinguishes a missing ID (SQL NULL, JSON null) from ID 0, which
+// ID.Scan cannot. IDs stores a list of IDs in a single column as a PostgreSQL
+// array, a JSON array or comma-separated text.
+
+package snowflake
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidIDList is returned when a list of IDs cannot be parsed.
+var ErrInvalidIDList = errors.New("invalid ID list")
+
+// scanID converts a database value to an ID. SQL NULL is reported by the caller.
+//
+// Integers are accepted if they fit in int64. Strings must be decimal integers,
+// since TEXT columns holding IDs are expected to store them in decimal. []byte
+// values may also use any format ParseAny accepts, for drivers that return
+// encoded IDs as raw bytes. Floats are rejected: float64 cannot represent IDs
+// above 2^53 exactly, so accepting them would silently corrupt IDs.
+func scanID(value interface{}) (ID, error) {
+	switch v := value.(type) {
+	case int64:
+		return ID(v), nil
+	case int32:
+		return ID(v), nil
+	case int:
+		return ID(v), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("cannot scan uint64 %d into ID: exceeds int64", v)
+		}
+		return ID(v), nil
+	case string:
+		return parseDecimalID(v)
+	case []byte:
+		s := strings.TrimSpace(string(v))
+		if isDecimalString(s) {
+			return parseDecimalID(s)
+		}
+		id, _, err := ParseAny(s)
+		if err != nil {
+			return 0, fmt.Errorf("cannot scan %q into ID: %w", s, err)
+		}
+		return id, nil
+	case float64, float32:
+		return 0, fmt.Errorf("cannot scan %T into ID: floating-point values lose precision above 2^53", value)
+	default:
+		return 0, fmt.Errorf("cannot scan %T into ID", value)
+	}
+}
+
+// parseDecimalID parses a decimal integer, rejecting floats and exponents.
+func parseDecimalID(s string) (ID, error) {
+	s = strings.TrimSpace(s)
+	if !isDecimalString(s) {
+		return 0, fmt.Errorf("cannot scan %q into ID: not a decimal integer", s)
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot scan %q into ID: %w", s, err)
+	}
+	return ID(i), nil
+}
+
+// isDecimalString reports whether s is an optionally signed run of ASCII digits.
+func isDecimalString(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ============================================================================
+// NullID
+// ============================================================================
+
+// NullID is an ID that may be NULL, like sql.NullInt64.
+//
+// Use it for nullable foreign keys and optional references where ID 0 and
+// "no ID" must not be confused.
+//
+// Example:
+//
+//	var parent snowflake.NullID
+//	err := db.QueryRow("SELECT parent_id FROM comments WHERE id = ?", id).Scan(&parent)
+//	if parent.Valid {
+//	    loadParent(parent.ID)
+//	}
+type NullID struct {
+	ID    ID
+	Valid bool // Valid is true if ID is not NULL
+}
+
+// NewNullID returns a valid NullID holding id.
+func NewNullID(id ID) NullID {
+	return NullID{ID: id, Valid: true}
+}
+
+// Scan implements sql.Scanner. SQL NULL sets Valid to false; other values are
+// accepted as in ID.Scan.
+func (n *NullID) Scan(value interface{}) error {
+	if value == nil {
+		n.ID, n.Valid = 0, false
+		return nil
+	}
+	id, err := scanID(value)
+	if err != nil {
+		n.ID, n.Valid = 0, false
+		return err
+	}
+	n.ID, n.Valid = id, true
+	return nil
+}
+
+// Value implements driver.Valuer, returning nil (SQL NULL) when not valid.
+func (n NullID) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return int64(n.ID), nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding invalid IDs as null and
+// valid IDs as strings like ID.MarshalJSON.
+func (n NullID) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.ID.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler. JSON null sets Valid to false.
+func (n *NullID) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "null" {
+		n.ID, n.Valid = 0, false
+		return nil
+	}
+	if err := n.ID.UnmarshalJSON(data); err != nil {
+		n.Valid = false
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// ============================================================================
+// IDs
+// ============================================================================
+
+// ListFormat selects how IDs are written to the database.
+type ListFormat int
+
+const (
+	// ListPostgres writes a PostgreSQL array literal: {1,2,3}
+	ListPostgres ListFormat = iota
+
+	// ListJSON writes a JSON array of strings: ["1","2","3"]
+	ListJSON
+
+	// ListCSV writes comma-separated decimal text: 1,2,3
+	ListCSV
+)
+
+// IDs is a list of IDs stored in a single column.
+//
+// Scan detects the format from the stored text: PostgreSQL array literals
+// ({1,2,3}), JSON arrays of strings or numbers ([1,"2"]) and comma-separated
+// text (1,2,3) are all accepted. Value writes a PostgreSQL array literal; use
+// As to write JSON or CSV instead.
+//
+// Example:
+//
+//	// PostgreSQL: tags BIGINT[]
+//	db.Exec("UPDATE posts SET tag_ids = $1 WHERE id = $2", snowflake.IDs{a, b}, postID)
+//
+//	// MySQL JSON column
+//	db.Exec("UPDATE posts SET tag_ids = ? WHERE id = ?", snowflake.IDs{a, b}.As(snowflake.ListJSON), postID)
+//
+//	var tags snowflake.IDs
+//	db.QueryRow("SELECT tag_ids FROM posts WHERE id = $1", postID).Scan(&tags)
+type IDs []ID
+
+// Encode renders the IDs in the given format.
+func (ids IDs) Encode(format ListFormat) string {
+	var b strings.Builder
+	switch format {
+	case ListJSON:
+		b.WriteByte('[')
+		for i, id := range ids {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteByte('"')
+			b.WriteString(id.String())
+			b.WriteByte('"')
+		}
+		b.WriteByte(']')
+	case ListCSV:
+		for i, id := range ids {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(id.String())
+		}
+	default:
+		b.WriteByte('{')
+		for i, id := range ids {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(id.String())
+		}
+		b.WriteByte('}')
+	}
+	return b.String()
+}
+
+// Value implements driver.Valuer, writing a PostgreSQL array literal.
+// A nil slice is written as SQL NULL; an empty slice as {}.
+func (ids IDs) Value() (driver.Value, error) {
+	return ids.As(ListPostgres).Value()
+}
+
+// As returns a driver.Valuer that writes the IDs in the given format.
+// A nil slice is written as SQL NULL.
+func (ids IDs) As(format ListFormat) driver.Valuer {
+	return idsValuer{ids: ids, format: format}
+}
+
+// idsValuer writes IDs in a chosen ListFormat.
+type idsValuer struct {
+	ids    IDs
+	format ListFormat
+}
+
+// Value implements driver.Valuer.
+func (v idsValuer) Value() (driver.Value, error) {
+	if v.ids == nil {
+		return nil, nil
+	}
+	return v.ids.Encode(v.format), nil
+}
+
+// Scan implements sql.Scanner.
+//
+// SQL NULL scans to a nil slice. Text starting with '{' is parsed as a
+// PostgreSQL array, text starting with '[' as a JSON array, and anything else
+// as comma-separated IDs. Elements are parsed like ID.Scan parses []byte, so
+// encoded IDs such as "b62:1tckI1NfUnH" are accepted.
+func (ids *IDs) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		*ids = nil
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into IDs", value)
+	}
+
+	parsed, err := ParseIDs(s)
+	if err != nil {
+		return err
+	}
+	*ids = parsed
+	return nil
+}
+
+// ParseIDs parses a PostgreSQL array literal, JSON array or comma-separated list of IDs.
+//
+// Example:
+//
+//	ids, err := snowflake.ParseIDs("{1234567890123456789,1234567890123456790}")
+//	ids, err := snowflake.ParseIDs(`["1234567890123456789"]`)
+//	ids, err := snowflake.ParseIDs("1234567890123456789, b62:1tckI1NfUnH")
+func ParseIDs(s string) (IDs, error) {
+	s = strings.TrimSpace(s)
+
+	var elems []string
+	switch {
+	case strings.HasPrefix(s, "["):
+		var raw []json.RawMessage
+		if err := json.Unmarshal([]byte(s), &raw); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidIDList, err)
+		}
+		for _, r := range raw {
+			var str string
+			if err := json.Unmarshal(r, &str); err == nil {
+				elems = append(elems, str)
+				continue
+			}
+			elems = append(elems, string(r))
+		}
+	case strings.HasPrefix(s, "{"):
+		if !strings.HasSuffix(s, "}") {
+			return nil, fmt.Errorf("%w: unterminated array %q", ErrInvalidIDList, s)
+		}
+		elems = splitList(s[1 : len(s)-1])
+		for i, e := range elems {
+			if strings.EqualFold(e, "NULL") {
+				return nil, fmt.Errorf("%w: NULL element at index %d", ErrInvalidIDList, i)
+			}
+			elems[i] = strings.Trim(e, `"`)
+		}
+	default:
+		elems = splitList(s)
+	}
+
+	ids := make(IDs, 0, len(elems))
+	for i, e := range elems {
+		id, err := scanID([]byte(e))
+		if err != nil {
+			return nil, fmt.Errorf("%w: element %d: %w", ErrInvalidIDList, i, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// splitList splits comma-separated text, trimming whitespace. Empty text
+// yields no elements.
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
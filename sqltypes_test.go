@@ -0,0 +1,224 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestID_ScanTypes(t *testing.T) {
+	const want = ID(1234567890123456789)
+
+	valid := []interface{}{
+		int64(want),
+		uint64(want),
+		int32(42),
+		"1234567890123456789",
+		" 1234567890123456789 ",
+		[]byte("1234567890123456789"),
+		[]byte("b62:1tckI1NfUnH"),
+		[]byte("0x112210f47de98115"),
+		[]byte("BNEO-O6T6-6UYE-I3"),
+	}
+	for _, v := range valid {
+		var id ID
+		if err := id.Scan(v); err != nil {
+			t.Errorf("Scan(%T %v) error = %v", v, v, err)
+			continue
+		}
+		if _, ok := v.(int32); ok {
+			if id != 42 {
+				t.Errorf("Scan(int32) = %d, want 42", id)
+			}
+			continue
+		}
+		if id != want {
+			t.Errorf("Scan(%T %v) = %d, want %d", v, v, id, want)
+		}
+	}
+
+	invalid := []interface{}{
+		float64(1234567890123456789),
+		uint64(1 << 63),
+		"1.5",
+		"1e18",
+		"b62:1tckI1NfUnH", // strings are decimal only
+		[]byte("1.5"),
+		true,
+	}
+	for _, v := range invalid {
+		var id ID
+		if err := id.Scan(v); err == nil {
+			t.Errorf("Scan(%T %v) = %d, want error", v, v, id)
+		}
+	}
+
+	var id ID = 5
+	if err := id.Scan(nil); err != nil || id != 0 {
+		t.Errorf("Scan(nil) = (%d, %v), want (0, nil)", id, err)
+	}
+}
+
+func TestNullID_Scan(t *testing.T) {
+	var n NullID
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("Scan(nil) = %+v, %v; want invalid", n, err)
+	}
+
+	if err := n.Scan(int64(0)); err != nil || !n.Valid || n.ID != 0 {
+		t.Errorf("Scan(0) = %+v, %v; want valid zero", n, err)
+	}
+
+	if err := n.Scan(int64(123)); err != nil || !n.Valid || n.ID != 123 {
+		t.Errorf("Scan(123) = %+v, %v; want valid 123", n, err)
+	}
+
+	if err := n.Scan(float64(1)); err == nil || n.Valid {
+		t.Errorf("Scan(float64) = %+v, %v; want invalid with error", n, err)
+	}
+}
+
+func TestNullID_Value(t *testing.T) {
+	if v, err := (NullID{}).Value(); v != nil || err != nil {
+		t.Errorf("invalid Value() = (%v, %v), want (nil, nil)", v, err)
+	}
+	if v, err := NewNullID(7).Value(); v != int64(7) || err != nil {
+		t.Errorf("valid Value() = (%v, %v), want (7, nil)", v, err)
+	}
+}
+
+func TestNullID_JSON(t *testing.T) {
+	type row struct {
+		Parent NullID `json:"parent"`
+	}
+
+	data, _ := json.Marshal(row{})
+	if string(data) != `{"parent":null}` {
+		t.Errorf("Marshal(invalid) = %s", data)
+	}
+	data, _ = json.Marshal(row{Parent: NewNullID(1234567890123456789)})
+	if string(data) != `{"parent":"1234567890123456789"}` {
+		t.Errorf("Marshal(valid) = %s", data)
+	}
+
+	var r row
+	if err := json.Unmarshal([]byte(`{"parent":"99"}`), &r); err != nil || !r.Parent.Valid || r.Parent.ID != 99 {
+		t.Errorf("Unmarshal(\"99\") = %+v, %v", r.Parent, err)
+	}
+	if err := json.Unmarshal([]byte(`{"parent":null}`), &r); err != nil || r.Parent.Valid {
+		t.Errorf("Unmarshal(null) = %+v, %v; want invalid", r.Parent, err)
+	}
+	if err := json.Unmarshal([]byte(`{"parent":"0"}`), &r); err != nil || !r.Parent.Valid || r.Parent.ID != 0 {
+		t.Errorf("Unmarshal(\"0\") = %+v, %v; want valid zero", r.Parent, err)
+	}
+}
+
+func TestIDs_EncodeAndValue(t *testing.T) {
+	ids := IDs{1234567890123456789, 42}
+
+	tests := []struct {
+		format ListFormat
+		want   string
+	}{
+		{ListPostgres, "{1234567890123456789,42}"},
+		{ListJSON, `["1234567890123456789","42"]`},
+		{ListCSV, "1234567890123456789,42"},
+	}
+	for _, tt := range tests {
+		if got := ids.Encode(tt.format); got != tt.want {
+			t.Errorf("Encode(%d) = %q, want %q", tt.format, got, tt.want)
+		}
+		if v, err := ids.As(tt.format).Value(); err != nil || v != tt.want {
+			t.Errorf("As(%d).Value() = (%v, %v), want %q", tt.format, v, err, tt.want)
+		}
+	}
+
+	if v, _ := ids.Value(); v != "{1234567890123456789,42}" {
+		t.Errorf("Value() = %v, want PostgreSQL array", v)
+	}
+	if v, _ := IDs(nil).Value(); v != nil {
+		t.Errorf("nil Value() = %v, want nil", v)
+	}
+	if v, _ := (IDs{}).Value(); v != "{}" {
+		t.Errorf("empty Value() = %v, want {}", v)
+	}
+}
+
+func TestIDs_Scan(t *testing.T) {
+	want := IDs{1234567890123456789, 42}
+
+	inputs := []interface{}{
+		"{1234567890123456789,42}",
+		`{"1234567890123456789", "42"}`,
+		[]byte(`["1234567890123456789","42"]`),
+		`[1234567890123456789, 42]`,
+		"1234567890123456789, 42",
+		"b62:1tckI1NfUnH,42",
+	}
+	for _, input := range inputs {
+		var got IDs
+		if err := got.Scan(input); err != nil {
+			t.Errorf("Scan(%v) error = %v", input, err)
+			continue
+		}
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("Scan(%v) = %v, want %v", input, got, want)
+		}
+	}
+
+	// Round trip through every format
+	for _, format := range []ListFormat{ListPostgres, ListJSON, ListCSV} {
+		var got IDs
+		if err := got.Scan(want.Encode(format)); err != nil || len(got) != 2 || got[0] != want[0] {
+			t.Errorf("round trip format %d = %v, %v", format, got, err)
+		}
+	}
+
+	for _, empty := range []string{"{}", "[]", ""} {
+		var got IDs
+		if err := got.Scan(empty); err != nil || len(got) != 0 {
+			t.Errorf("Scan(%q) = %v, %v; want empty", empty, got, err)
+		}
+	}
+
+	got := IDs{1}
+	if err := got.Scan(nil); err != nil || got != nil {
+		t.Errorf("Scan(nil) = %v, %v; want nil", got, err)
+	}
+}
+
+func TestIDs_ScanInvalid(t *testing.T) {
+	inputs := []interface{}{
+		"{1,NULL}",
+		"{1,2",
+		"[1.5]",
+		`["1", 2.0e18]`,
+		"1,,2",
+		"1,abc!",
+		int64(1),
+	}
+	for _, input := range inputs {
+		var got IDs
+		err := got.Scan(input)
+		if err == nil {
+			t.Errorf("Scan(%v) = %v, want error", input, got)
+			continue
+		}
+		if _, ok := input.(string); ok && !errors.Is(err, ErrInvalidIDList) {
+			t.Errorf("Scan(%v) error = %v, want ErrInvalidIDList", input, err)
+		}
+	}
+}
+
+func TestIDs_JSON(t *testing.T) {
+	ids := IDs{1234567890123456789, 42}
+	data, err := json.Marshal(ids)
+	if err != nil || string(data) != ids.Encode(ListJSON) {
+		t.Errorf("json.Marshal() = %s, %v; want %s", data, err, ids.Encode(ListJSON))
+	}
+
+	var got IDs
+	if err := json.Unmarshal(data, &got); err != nil || len(got) != 2 || got[0] != ids[0] {
+		t.Errorf("json.Unmarshal() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,274 @@
+// Package snowflake - translate.go re-derives IDs under a different scheme.
+//
+// Migrating a dataset to another layout or epoch means rewriting every ID so
+// that it decodes to the same time, worker and sequence under the new scheme.
+// Translate does this for one ID and reports any component the target cannot
+// hold. Translator does it for a sorted stream, checking that the output stays
+// in the same order and optionally renumbering sequences to fit a narrower
+// sequence field.
+
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by Translate and Translator.
+var (
+	// ErrTranslate is returned (wrapped in *TranslateError) when an ID has a
+	// component the target scheme cannot represent.
+	ErrTranslate = errors.New("ID does not fit the target scheme")
+
+	// ErrUnorderedIDs is returned by Translator when its input is not strictly
+	// ascending.
+	ErrUnorderedIDs = errors.New("IDs are not in ascending order")
+)
+
+// IDComponent names one of the fields of an ID.
+type IDComponent string
+
+// ID components reported by TranslateError.
+const (
+	ComponentTimestamp IDComponent = "timestamp"
+	ComponentWorker    IDComponent = "worker"
+	ComponentSequence  IDComponent = "sequence"
+)
+
+// TranslateError reports an ID component that does not fit the target scheme.
+//
+// Example usage:
+//
+//	newID, err := snowflake.Translate(id, from, to)
+//	var trErr *snowflake.TranslateError
+//	if errors.As(err, &trErr) && trErr.Component == snowflake.ComponentWorker {
+//	    log.Error("worker ID too large for the new layout", "worker", trErr.Value, "max", trErr.Max)
+//	}
+type TranslateError struct {
+	// ID is the ID being translated.
+	ID ID
+
+	// Component is the field that does not fit.
+	Component IDComponent
+
+	// Value is the component's value in the target scheme: the timestamp in
+	// target time units since the target epoch, or the worker ID or sequence.
+	Value int64
+
+	// Max is the largest value the target scheme allows for the component.
+	Max int64
+
+	// Reason is a human-readable explanation.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *TranslateError) Error() string {
+	return fmt.Sprintf("cannot translate ID %d: %s %d %s (max %d)",
+		e.ID, e.Component, e.Value, e.Reason, e.Max)
+}
+
+// Unwrap returns the underlying error for errors.Is() compatibility.
+func (e *TranslateError) Unwrap() error {
+	return ErrTranslate
+}
+
+// Translate re-derives id, generated under the from scheme, for the to scheme:
+// the result decodes to the same time, worker ID and sequence under to.
+//
+// Returns a *TranslateError when a component does not fit: a time before the
+// target epoch or past its lifespan, or a worker ID or sequence above the
+// target's maximum. The target time unit must divide the source unit, since a
+// coarser unit would merge IDs; otherwise the error wraps ErrTranslate. Returns
+// a validation error if either scheme is invalid.
+//
+// Translations that succeed preserve ordering: if a < b then
+// Translate(a) < Translate(b).
+//
+// Example:
+//
+//	from := snowflake.Scheme{Layout: snowflake.LayoutDefault, Epoch: snowflake.Epoch}
+//	to := snowflake.Scheme{Layout: snowflake.LayoutSuperior, Epoch: snowflake.Epoch}
+//	newID, err := snowflake.Translate(id, from, to)
+func Translate(id ID, from, to Scheme) (ID, error) {
+	if err := validateTranslate(from, to); err != nil {
+		return 0, err
+	}
+	timestamp, worker, sequence, err := translateComponents(id, from.normalized(), to.normalized())
+	if err != nil {
+		return 0, err
+	}
+	return composeTranslated(id, to.normalized(), timestamp, worker, sequence)
+}
+
+// Translator translates a stream of IDs in ascending order from one scheme to
+// another, for bulk migrations.
+//
+// Each ID must be greater than the previous one, so a stream can be read with
+// "ORDER BY id" and the output written in the same order. Output IDs are
+// guaranteed to be strictly ascending as well.
+//
+// Not thread-safe: use one Translator per stream.
+//
+// Example:
+//
+//	tr, err := snowflake.NewTranslator(from, to, snowflake.TranslatorOptions{Resequence: true})
+//	if err != nil {
+//	    return err
+//	}
+//	for rows.Next() {
+//	    rows.Scan(&id)
+//	    newID, err := tr.Translate(id)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    mapping.Write(id, newID)
+//	}
+type Translator struct {
+	from, to Scheme
+	opts     TranslatorOptions
+
+	count       int64
+	resequenced int64
+	lastIn      ID
+	lastOut     ID
+
+	// The current run of IDs sharing a target timestamp and worker
+	runTimestamp int64
+	runWorker    int64
+	runNext      int64
+}
+
+// TranslatorOptions configures a Translator.
+type TranslatorOptions struct {
+	// Resequence renumbers sequences from 0 within each time unit and worker,
+	// instead of copying them. IDs then fit a narrower sequence field as long
+	// as no worker generated more IDs in one time unit than it holds.
+	// Ordering is preserved either way.
+	Resequence bool
+}
+
+// NewTranslator returns a Translator from one scheme to another.
+//
+// Returns a validation error if either scheme is invalid, or an error wrapping
+// ErrTranslate if the target time unit does not divide the source unit.
+func NewTranslator(from, to Scheme, opts TranslatorOptions) (*Translator, error) {
+	if err := validateTranslate(from, to); err != nil {
+		return nil, err
+	}
+	return &Translator{from: from.normalized(), to: to.normalized(), opts: opts}, nil
+}
+
+// Translate translates the next ID in the stream.
+//
+// Returns an error wrapping ErrUnorderedIDs if id is not greater than the
+// previous ID, or a *TranslateError if it does not fit the target scheme. The
+// Translator's state is unchanged on error, so the stream can be resumed with
+// the next ID.
+func (t *Translator) Translate(id ID) (ID, error) {
+	if t.count > 0 && id <= t.lastIn {
+		return 0, fmt.Errorf("%w: ID %d at position %d follows %d", ErrUnorderedIDs, id, t.count, t.lastIn)
+	}
+
+	timestamp, worker, sequence, err := translateComponents(id, t.from, t.to)
+	if err != nil {
+		return 0, err
+	}
+
+	newRun := t.count == 0 || timestamp != t.runTimestamp || worker != t.runWorker
+	if t.opts.Resequence {
+		if newRun {
+			sequence = 0
+		} else {
+			sequence = t.runNext
+		}
+	}
+
+	out, err := composeTranslated(id, t.to, timestamp, worker, sequence)
+	if err != nil {
+		return 0, err
+	}
+	if t.count > 0 && out <= t.lastOut {
+		// Cannot happen for ascending input; kept as a guard for the guarantee
+		return 0, fmt.Errorf("%w: ID %d translated to %d, not above %d", ErrUnorderedIDs, id, out, t.lastOut)
+	}
+
+	if t.opts.Resequence && sequence != int64(id)&t.from.maxSequence() {
+		t.resequenced++
+	}
+	t.runTimestamp, t.runWorker, t.runNext = timestamp, worker, sequence+1
+	t.lastIn, t.lastOut = id, out
+	t.count++
+	return out, nil
+}
+
+// Count returns the number of IDs translated so far.
+func (t *Translator) Count() int64 {
+	return t.count
+}
+
+// Resequenced returns the number of IDs whose sequence was renumbered.
+func (t *Translator) Resequenced() int64 {
+	return t.resequenced
+}
+
+// validateTranslate checks both schemes and that the target's time unit can
+// represent every source time unit exactly.
+func validateTranslate(from, to Scheme) error {
+	if err := from.Validate(); err != nil {
+		return err
+	}
+	if err := to.Validate(); err != nil {
+		return err
+	}
+	fromUnit, toUnit := from.normalized().Layout.TimeUnit, to.normalized().Layout.TimeUnit
+	if fromUnit%toUnit != 0 {
+		return fmt.Errorf("%w: target time unit %v does not divide source unit %v", ErrTranslate, toUnit, fromUnit)
+	}
+	return nil
+}
+
+// translateComponents decodes id under from and re-expresses its timestamp in
+// to's time units and epoch, checking the timestamp and worker ID fit.
+func translateComponents(id ID, from, to Scheme) (timestamp, worker, sequence int64, err error) {
+	_, workerShift, maxWorker, maxSequence := from.Layout.CalculateShifts()
+	worker = (int64(id) >> workerShift) & maxWorker
+	sequence = int64(id) & maxSequence
+
+	timestamp = to.timeUnits(from.Time(id))
+	if timestamp < 0 {
+		return 0, 0, 0, &TranslateError{ID: id, Component: ComponentTimestamp, Value: timestamp,
+			Max: to.maxTimeUnits(), Reason: "is before the target epoch"}
+	}
+	if timestamp > to.maxTimeUnits() {
+		return 0, 0, 0, &TranslateError{ID: id, Component: ComponentTimestamp, Value: timestamp,
+			Max: to.maxTimeUnits(), Reason: "is past the end of the target layout's lifespan"}
+	}
+	if toMaxWorker := to.maxWorker(); worker > toMaxWorker {
+		return 0, 0, 0, &TranslateError{ID: id, Component: ComponentWorker, Value: worker,
+			Max: toMaxWorker, Reason: "does not fit the target's worker bits"}
+	}
+	return timestamp, worker, sequence, nil
+}
+
+// composeTranslated builds an ID under to, checking the sequence fits.
+func composeTranslated(id ID, to Scheme, timestamp, worker, sequence int64) (ID, error) {
+	if toMaxSequence := to.maxSequence(); sequence > toMaxSequence {
+		return 0, &TranslateError{ID: id, Component: ComponentSequence, Value: sequence,
+			Max: toMaxSequence, Reason: "does not fit the target's sequence bits"}
+	}
+	timestampShift, workerShift, _, _ := to.Layout.CalculateShifts()
+	return ID(timestamp<<timestampShift | worker<<workerShift | sequence), nil
+}
+
+// maxWorker returns the largest worker ID the scheme's layout holds.
+func (s Scheme) maxWorker() int64 {
+	_, _, maxWorker, _ := s.Layout.CalculateShifts()
+	return maxWorker
+}
+
+// maxSequence returns the largest sequence number the scheme's layout holds.
+func (s Scheme) maxSequence() int64 {
+	_, _, _, maxSequence := s.Layout.CalculateShifts()
+	return maxSequence
+}
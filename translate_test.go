@@ -0,0 +1,210 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTranslate_RoundTrip(t *testing.T) {
+	from := DefaultScheme
+	to := Scheme{Layout: LayoutSuperior, Epoch: Epoch}
+
+	gen, err := New(1023)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		id := gen.MustGenerateID()
+		_, _, seq := from.Components(id)
+		if seq > to.maxSequence() {
+			continue
+		}
+		got, err := Translate(id, from, to)
+		if err != nil {
+			t.Fatalf("Translate(%d) error = %v", id, err)
+		}
+
+		wantTs, wantWorker, wantSeq := from.Components(id)
+		gotTs, gotWorker, gotSeq := to.Components(got)
+		if gotTs != wantTs || gotWorker != wantWorker || gotSeq != wantSeq {
+			t.Fatalf("Translate(%d) = %d decodes to (%d, %d, %d), want (%d, %d, %d)",
+				id, got, gotTs, gotWorker, gotSeq, wantTs, wantWorker, wantSeq)
+		}
+
+		back, err := Translate(got, to, from)
+		if err != nil || back != id {
+			t.Fatalf("Translate(%d) back = %d, %v, want %d", got, back, err, id)
+		}
+	}
+}
+
+func TestTranslate_EpochAndUnit(t *testing.T) {
+	// A 10ms Sonyflake-style ID moved to a 1ms layout with a later epoch
+	from := Scheme{Layout: LayoutSonyflake, Epoch: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()}
+	to := DefaultScheme
+	at := time.Date(2025, 3, 4, 5, 6, 7, 890_000_000, time.UTC)
+	id := from.MinIDForTime(at) | 5<<from.Layout.SequenceBits | 7
+
+	got, err := Translate(id, from, to)
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if !to.Time(got).Equal(at) {
+		t.Errorf("Time = %v, want %v", to.Time(got), at)
+	}
+	if _, worker, seq := to.Components(got); worker != 5 || seq != 7 {
+		t.Errorf("worker, sequence = %d, %d, want 5, 7", worker, seq)
+	}
+
+	// The reverse needs a coarser unit, which would merge IDs
+	if _, err := Translate(got, to, from); !errors.Is(err, ErrTranslate) {
+		t.Errorf("Translate() to a coarser unit error = %v, want ErrTranslate", err)
+	}
+}
+
+func TestTranslate_Errors(t *testing.T) {
+	superior := Scheme{Layout: LayoutSuperior, Epoch: Epoch}
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := DefaultScheme.MinIDForTime(at)
+
+	tests := []struct {
+		name      string
+		id        ID
+		from, to  Scheme
+		component IDComponent
+		value     int64
+		max       int64
+	}{
+		{
+			name: "sequence too large", id: base | 3<<12 | 4000,
+			from: DefaultScheme, to: superior,
+			component: ComponentSequence, value: 4000, max: 511,
+		},
+		{
+			name: "before target epoch", id: base,
+			from: DefaultScheme, to: Scheme{Layout: LayoutDefault, Epoch: at.Add(time.Hour).UnixMilli()},
+			component: ComponentTimestamp, value: -3_600_000, max: 1<<41 - 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Translate(tt.id, tt.from, tt.to)
+			var trErr *TranslateError
+			if !errors.As(err, &trErr) {
+				t.Fatalf("Translate() error = %v, want *TranslateError", err)
+			}
+			if !errors.Is(err, ErrTranslate) {
+				t.Errorf("error does not wrap ErrTranslate")
+			}
+			if trErr.ID != tt.id || trErr.Component != tt.component || trErr.Value != tt.value || trErr.Max != tt.max {
+				t.Errorf("TranslateError = %+v, want component %s value %d max %d", trErr, tt.component, tt.value, tt.max)
+			}
+		})
+	}
+}
+
+func TestTranslate_WorkerTooLarge(t *testing.T) {
+	superior := Scheme{Layout: LayoutSuperior, Epoch: Epoch}
+	id := superior.MinIDForTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) | 5000<<9
+
+	_, err := Translate(id, superior, DefaultScheme)
+	trErr, ok := err.(*TranslateError)
+	if !ok || trErr.Component != ComponentWorker || trErr.Value != 5000 || trErr.Max != 1023 {
+		t.Errorf("Translate() error = %v, want worker 5000 over 1023", err)
+	}
+}
+
+func TestTranslator_PreservesOrder(t *testing.T) {
+	from := DefaultScheme
+	to := Scheme{Layout: LayoutSuperior, Epoch: Epoch}
+	start := from.MinIDForTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	// Three time units; worker 3 uses sequences past the target's 511
+	var ids []ID
+	for ms := int64(0); ms < 3; ms++ {
+		for _, worker := range []int64{1, 3} {
+			for _, seq := range []int64{0, 600, 4095} {
+				ids = append(ids, start+ID(ms<<22|worker<<12|seq))
+			}
+		}
+	}
+
+	strict, err := NewTranslator(from, to, TranslatorOptions{})
+	if err != nil {
+		t.Fatalf("NewTranslator() error = %v", err)
+	}
+	if _, err := strict.Translate(ids[0]); err != nil {
+		t.Fatalf("Translate(%d) error = %v", ids[0], err)
+	}
+	if _, err := strict.Translate(ids[1]); !errors.Is(err, ErrTranslate) {
+		t.Errorf("Translate() without Resequence error = %v, want ErrTranslate", err)
+	}
+
+	tr, err := NewTranslator(from, to, TranslatorOptions{Resequence: true})
+	if err != nil {
+		t.Fatalf("NewTranslator() error = %v", err)
+	}
+	var prev ID
+	for i, id := range ids {
+		got, err := tr.Translate(id)
+		if err != nil {
+			t.Fatalf("Translate(%d) error = %v", id, err)
+		}
+		if i > 0 && got <= prev {
+			t.Fatalf("output %d at %d is not above %d", got, i, prev)
+		}
+		prev = got
+
+		wantTs, wantWorker, _ := from.Components(id)
+		gotTs, gotWorker, gotSeq := to.Components(got)
+		if gotTs != wantTs || gotWorker != wantWorker || gotSeq != int64(i%3) {
+			t.Errorf("Translate(%d) decodes to (%d, %d, %d), want (%d, %d, %d)",
+				id, gotTs, gotWorker, gotSeq, wantTs, wantWorker, i%3)
+		}
+	}
+	if tr.Count() != int64(len(ids)) {
+		t.Errorf("Count() = %d, want %d", tr.Count(), len(ids))
+	}
+	if want := int64(len(ids) / 3 * 2); tr.Resequenced() != want {
+		t.Errorf("Resequenced() = %d, want %d", tr.Resequenced(), want)
+	}
+
+	// Unordered input is rejected without changing state
+	if _, err := tr.Translate(ids[0]); !errors.Is(err, ErrUnorderedIDs) {
+		t.Errorf("Translate() of an earlier ID error = %v, want ErrUnorderedIDs", err)
+	}
+	if tr.Count() != int64(len(ids)) {
+		t.Errorf("Count() after a rejected ID = %d, want %d", tr.Count(), len(ids))
+	}
+}
+
+func TestTranslator_SequenceRunOverflow(t *testing.T) {
+	from := DefaultScheme
+	to := Scheme{Layout: LayoutSuperior, Epoch: Epoch}
+	start := from.MinIDForTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	tr, err := NewTranslator(from, to, TranslatorOptions{Resequence: true})
+	if err != nil {
+		t.Fatalf("NewTranslator() error = %v", err)
+	}
+	for seq := int64(0); seq <= 511; seq++ {
+		if _, err := tr.Translate(start + ID(seq)); err != nil {
+			t.Fatalf("Translate(seq %d) error = %v", seq, err)
+		}
+	}
+	_, err = tr.Translate(start + 512)
+	var trErr *TranslateError
+	if !errors.As(err, &trErr) || trErr.Component != ComponentSequence || trErr.Value != 512 {
+		t.Errorf("Translate() of the 513th ID in one unit error = %v, want sequence 512 over 511", err)
+	}
+}
+
+func TestNewTranslator_Errors(t *testing.T) {
+	if _, err := NewTranslator(DefaultScheme, Scheme{Layout: LayoutSonyflake, Epoch: Epoch}, TranslatorOptions{}); !errors.Is(err, ErrTranslate) {
+		t.Errorf("NewTranslator() to a coarser unit error = %v, want ErrTranslate", err)
+	}
+	if _, err := NewTranslator(DefaultScheme, Scheme{Layout: LayoutDefault}, TranslatorOptions{}); !IsConfigError(err) {
+		t.Errorf("NewTranslator() with a zero epoch error = %v, want ConfigError", err)
+	}
+}